@@ -17,15 +17,19 @@ package artifact
 
 import (
 	"context"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/diginfra/diginfractl/cmd/artifact/audit"
 	artifactconfig "github.com/diginfra/diginfractl/cmd/artifact/config"
 	"github.com/diginfra/diginfractl/cmd/artifact/follow"
+	"github.com/diginfra/diginfractl/cmd/artifact/history"
 	"github.com/diginfra/diginfractl/cmd/artifact/info"
 	"github.com/diginfra/diginfractl/cmd/artifact/install"
 	"github.com/diginfra/diginfractl/cmd/artifact/list"
 	"github.com/diginfra/diginfractl/cmd/artifact/manifest"
+	"github.com/diginfra/diginfractl/cmd/artifact/rollback"
 	"github.com/diginfra/diginfractl/cmd/artifact/search"
 	"github.com/diginfra/diginfractl/internal/config"
 	"github.com/diginfra/diginfractl/pkg/index/cache"
@@ -61,6 +65,13 @@ func NewArtifactCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Comma
 			}
 			// Save the index cache for later use by the sub commands.
 			opt.Initialize(commonoptions.WithIndexCache(indexCache))
+			for _, err := range indexCache.RefreshErrors() {
+				opt.Printer.Logger.Warn("Using the cached copy of a stale index", opt.Printer.Logger.Args("reason", err.Error()))
+			}
+			for _, s := range indexCache.Shadowings() {
+				opt.Printer.Logger.Warn("Artifact name provided by several indexes, qualify it with the index name to use another one",
+					opt.Printer.Logger.Args("name", s.Name, "index", s.Index, "shadowed", strings.Join(s.Shadowed, ", ")))
+			}
 
 			return nil
 		},
@@ -73,6 +84,9 @@ func NewArtifactCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Comma
 	cmd.AddCommand(follow.NewArtifactFollowCmd(ctx, opt))
 	cmd.AddCommand(artifactconfig.NewArtifactConfigCmd(ctx, opt))
 	cmd.AddCommand(manifest.NewArtifactManifestCmd(ctx, opt))
+	cmd.AddCommand(history.NewArtifactHistoryCmd(ctx, opt))
+	cmd.AddCommand(audit.NewArtifactAuditCmd(ctx, opt))
+	cmd.AddCommand(rollback.NewArtifactRollbackCmd(ctx, opt))
 
 	return cmd
 }
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/diginfra/diginfractl/cmd/artifact/install"
+	auditlog "github.com/diginfra/diginfractl/internal/audit"
+	"github.com/diginfra/diginfractl/internal/config"
+	"github.com/diginfra/diginfractl/pkg/options"
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+const longAudit = `Check the installed artifacts against the lifecycle metadata published by the configured indexes.
+
+The installed artifacts, and their versions, are the last ones that went live according to the audit
+log written by the "artifact install", "artifact follow" and "artifact rollback" commands. For each of
+them, the command reports whether the artifact or its version is deprecated, past its end of life or
+affected by a security advisory, and exits with an error if anything is found.
+
+Example - Check the installed artifacts:
+	diginfractl artifact audit
+
+Example - Refresh the indexes first, to get the latest advisories:
+	diginfractl index update && diginfractl artifact audit
+`
+
+type artifactAuditOptions struct {
+	*options.Common
+	auditLog string
+}
+
+// NewArtifactAuditCmd returns the artifact audit command.
+func NewArtifactAuditCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := artifactAuditOptions{
+		Common: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "audit [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Check the installed artifacts for deprecated and vulnerable versions",
+		Long:                  longAudit,
+		Args:                  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Override "audit-log" flag with viper config if not set by user.
+			f := cmd.Flags().Lookup(install.FlagAuditLog)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", install.FlagAuditLog)
+			} else if !f.Changed && viper.IsSet(config.ArtifactAuditLogKey) {
+				val := viper.Get(config.ArtifactAuditLogKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", install.FlagAuditLog, err)
+				}
+			}
+
+			if o.auditLog == "" {
+				return fmt.Errorf("the audit log is disabled, please set the %q flag", install.FlagAuditLog)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunArtifactAudit(ctx)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.auditLog, install.FlagAuditLog, config.AuditLogFile, "path of the audit log to read")
+
+	return cmd
+}
+
+// RunArtifactAudit executes the business logic for the artifact audit command.
+func (o *artifactAuditOptions) RunArtifactAudit(_ context.Context) error {
+	logger := o.Printer.Logger
+
+	entries, err := auditlog.Read(o.auditLog)
+	if err != nil {
+		return err
+	}
+
+	installed := lastInstalled(entries)
+	now := time.Now()
+
+	var data [][]string
+	for _, e := range installed {
+		indexEntry, ok := o.IndexCache.EntryByReference(e.Ref)
+		if !ok {
+			logger.Debug("Artifact not found in the configured indexes, skipping", logger.Args("ref", e.Ref))
+			continue
+		}
+		for _, n := range indexEntry.Notices(e.Version, now) {
+			data = append(data, []string{e.Ref, e.Version, string(n.Kind), n.Message})
+		}
+	}
+
+	if len(data) == 0 {
+		logger.Info("No deprecated or vulnerable artifact found", logger.Args("installed", len(installed)))
+		return nil
+	}
+
+	if err := o.Printer.PrintTable(output.ArtifactAudit, data); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("found %d notices concerning the installed artifacts", len(data))
+}
+
+// lastInstalled returns, for each reference in the audit log, the entry of the last version that went live,
+// sorted by reference.
+func lastInstalled(entries []auditlog.Entry) []auditlog.Entry {
+	last := make(map[string]auditlog.Entry)
+	for i := range entries {
+		if entries[i].Succeeded() {
+			last[entries[i].Ref] = entries[i]
+		}
+	}
+
+	result := make([]auditlog.Entry, 0, len(last))
+	for _, e := range last {
+		result = append(result, e)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Ref < result[j].Ref
+	})
+
+	return result
+}
@@ -16,13 +16,13 @@
 package follow
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
-	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
@@ -30,16 +30,48 @@ import (
 	"github.com/spf13/viper"
 
 	"github.com/diginfra/diginfractl/cmd/artifact/install"
+	"github.com/diginfra/diginfractl/internal/audit"
 	"github.com/diginfra/diginfractl/internal/config"
 	"github.com/diginfra/diginfractl/internal/follower"
+	"github.com/diginfra/diginfractl/internal/follower/control"
+	"github.com/diginfra/diginfractl/internal/follower/webhook"
+	"github.com/diginfra/diginfractl/internal/health"
+	"github.com/diginfra/diginfractl/internal/utils"
 	"github.com/diginfra/diginfractl/pkg/index/index"
 	"github.com/diginfra/diginfractl/pkg/oci"
+	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
 	"github.com/diginfra/diginfractl/pkg/options"
 	"github.com/diginfra/diginfractl/pkg/output"
 )
 
 const (
+	// FlagControlSocket is the name of the flag to specify the path of the control API socket.
+	FlagControlSocket = "control-socket"
+	// FlagWebhookAddr is the name of the flag to specify the address of the webhook receiver.
+	FlagWebhookAddr = "webhook-addr"
+	// FlagWebhookSecret is the name of the flag to specify the shared secret of the webhook receiver.
+	FlagWebhookSecret = "webhook-secret"
+	// FlagStagingDir is the name of the flag to specify the directory where new versions wait for approval.
+	FlagStagingDir = "staging-dir"
+	// FlagPromoteAfter is the name of the flag to specify the delay after which staged versions are installed.
+	FlagPromoteAfter = "promote-after"
+	// FlagOnce is the name of the flag to run a single sync pass and exit.
+	FlagOnce = "once"
+	// FlagWorkers is the name of the flag to specify how many artifacts can be synced at the same time.
+	FlagWorkers = "workers"
+	// FlagHealthProbe is the name of the flag to specify the probe checking Diginfra after each installation.
+	FlagHealthProbe = "health-probe"
+	// FlagHealthGracePeriod is the name of the flag to specify how long Diginfra is watched after each installation.
+	FlagHealthGracePeriod = "health-grace-period"
+	// FlagHealthInterval is the name of the flag to specify the time between two health probes.
+	FlagHealthInterval = "health-interval"
+
 	timeout = time.Second * 5
+	// versionsRefreshTimeout bounds each refresh of the Diginfra versions while following, so that an
+	// unreachable endpoint never holds a worker for long.
+	versionsRefreshTimeout = time.Second * 5
+	// versionsRefreshInterval is how long the refreshed Diginfra versions are shared between the followers.
+	versionsRefreshInterval = time.Minute
 
 	longFollow = `This command allows you to keep up-to-date one or more given artifacts.
 It checks for updates on a periodic basis and then downloads and installs the latest version, 
@@ -75,6 +107,9 @@ Example - Install and follow all updates from "k8saudit-rules" 0.5.x release ser
 
 Example - Install and follow "cloudtrail" plugins using a fully qualified reference:
 	diginfractl artifact follow ghcr.io/diginfra/plugins/ruleset/k8saudit:latest
+
+Example - Check once for updates of "k8saudit-rules", e.g. from a CronJob or a systemd timer:
+	diginfractl artifact follow k8saudit-rules --once
 `
 )
 
@@ -82,15 +117,29 @@ type artifactFollowOptions struct {
 	*options.Common
 	*options.Registry
 	*options.Directory
+	*options.Kubernetes
+	*options.Layout
 	tmpDir           string
 	every            time.Duration
 	cron             string
 	diginfraVersions string
 	versions         config.DiginfraVersions
 	timeout          time.Duration
-	closeChan        chan bool
+	workers          int
 	allowedTypes     oci.ArtifactTypeSlice
 	noVerify         bool
+	controlSocket    string
+	webhookAddr      string
+	webhookSecret    string
+	stagingDir       string
+	promoteAfter     time.Duration
+	healthProbe      string
+	healthGrace      time.Duration
+	healthInterval   time.Duration
+	auditLog         string
+	policy           string // Raw string from command line
+	versionPolicy    index.VersionPolicy
+	once             bool
 }
 
 // NewArtifactFollowCmd returns the artifact follow command.
@@ -98,11 +147,12 @@ type artifactFollowOptions struct {
 //nolint:gocyclo // unknown reason for cyclomatic complexity
 func NewArtifactFollowCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 	o := artifactFollowOptions{
-		Common:    opt,
-		Registry:  &options.Registry{},
-		Directory: &options.Directory{},
-		closeChan: make(chan bool),
-		versions:  config.DiginfraVersions{},
+		Common:     opt,
+		Registry:   &options.Registry{},
+		Directory:  &options.Directory{},
+		Kubernetes: &options.Kubernetes{},
+		Layout:     &options.Layout{},
+		versions:   config.DiginfraVersions{},
 	}
 
 	cmd := &cobra.Command{
@@ -221,8 +271,215 @@ func NewArtifactFollowCmd(ctx context.Context, opt *options.Common) *cobra.Comma
 				}
 			}
 
+			// Override "control-socket" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(FlagControlSocket)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", FlagControlSocket)
+			} else if !f.Changed && viper.IsSet(config.ArtifactFollowControlSocketKey) {
+				val := viper.Get(config.ArtifactFollowControlSocketKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagControlSocket, err)
+				}
+			}
+
+			// Override "webhook-addr" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(FlagWebhookAddr)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", FlagWebhookAddr)
+			} else if !f.Changed && viper.IsSet(config.ArtifactFollowWebhookAddrKey) {
+				val := viper.Get(config.ArtifactFollowWebhookAddrKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagWebhookAddr, err)
+				}
+			}
+
+			// Override "webhook-secret" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(FlagWebhookSecret)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", FlagWebhookSecret)
+			} else if !f.Changed && viper.IsSet(config.ArtifactFollowWebhookSecretKey) {
+				val := viper.Get(config.ArtifactFollowWebhookSecretKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagWebhookSecret, err)
+				}
+			}
+
+			// Override "staging-dir" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(FlagStagingDir)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", FlagStagingDir)
+			} else if !f.Changed && viper.IsSet(config.ArtifactFollowStagingDirKey) {
+				val := viper.Get(config.ArtifactFollowStagingDirKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagStagingDir, err)
+				}
+			}
+
+			// Override "promote-after" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(FlagPromoteAfter)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", FlagPromoteAfter)
+			} else if !f.Changed && viper.IsSet(config.ArtifactFollowPromoteAfterKey) {
+				val := viper.Get(config.ArtifactFollowPromoteAfterKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagPromoteAfter, err)
+				}
+			}
+
+			// Override "health-probe" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(FlagHealthProbe)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", FlagHealthProbe)
+			} else if !f.Changed && viper.IsSet(config.ArtifactFollowHealthProbeKey) {
+				val := viper.Get(config.ArtifactFollowHealthProbeKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagHealthProbe, err)
+				}
+			}
+
+			// Override "health-grace-period" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(FlagHealthGracePeriod)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", FlagHealthGracePeriod)
+			} else if !f.Changed && viper.IsSet(config.ArtifactFollowHealthGracePeriodKey) {
+				val := viper.Get(config.ArtifactFollowHealthGracePeriodKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagHealthGracePeriod, err)
+				}
+			}
+
+			// Override "health-interval" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(FlagHealthInterval)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", FlagHealthInterval)
+			} else if !f.Changed && viper.IsSet(config.ArtifactFollowHealthIntervalKey) {
+				val := viper.Get(config.ArtifactFollowHealthIntervalKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagHealthInterval, err)
+				}
+			}
+
+			// Override "workers" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(FlagWorkers)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", FlagWorkers)
+			} else if !f.Changed && viper.IsSet(config.ArtifactFollowWorkersKey) {
+				val := viper.Get(config.ArtifactFollowWorkersKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagWorkers, err)
+				}
+			}
+			if o.workers < 1 {
+				return fmt.Errorf("%q must be at least 1", FlagWorkers)
+			}
+
+			// Override "audit-log" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(install.FlagAuditLog)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %s", install.FlagAuditLog)
+			} else if !f.Changed && viper.IsSet(config.ArtifactAuditLogKey) {
+				val := viper.Get(config.ArtifactAuditLogKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", install.FlagAuditLog, err)
+				}
+			}
+
+			// Override "k8s-destination" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(options.FlagK8sDestination)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagK8sDestination)
+			} else if !f.Changed && viper.IsSet(config.ArtifactK8sDestinationKey) {
+				val := viper.Get(config.ArtifactK8sDestinationKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagK8sDestination, err)
+				}
+			}
+
+			// Override "k8s-namespace" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(options.FlagK8sNamespace)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagK8sNamespace)
+			} else if !f.Changed && viper.IsSet(config.ArtifactK8sNamespaceKey) {
+				val := viper.Get(config.ArtifactK8sNamespaceKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagK8sNamespace, err)
+				}
+			}
+
+			// Override "kubeconfig" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(options.FlagKubeconfig)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagKubeconfig)
+			} else if !f.Changed && viper.IsSet(config.ArtifactK8sKubeconfigKey) {
+				val := viper.Get(config.ArtifactK8sKubeconfigKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagKubeconfig, err)
+				}
+			}
+
+			// Override "versioned" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(options.FlagVersioned)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagVersioned)
+			} else if !f.Changed && viper.IsSet(config.ArtifactVersionedKey) {
+				val := viper.Get(config.ArtifactVersionedKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagVersioned, err)
+				}
+			}
+
+			// Override "keep-versions" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(options.FlagKeepVersions)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagKeepVersions)
+			} else if !f.Changed && viper.IsSet(config.ArtifactKeepVersionsKey) {
+				val := viper.Get(config.ArtifactKeepVersionsKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagKeepVersions, err)
+				}
+			}
+
+			// Override "version-policy" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(install.FlagVersionPolicy)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", install.FlagVersionPolicy)
+			} else if !f.Changed && viper.IsSet(config.ArtifactVersionPolicyKey) {
+				val := viper.Get(config.ArtifactVersionPolicyKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", install.FlagVersionPolicy, err)
+				}
+			}
+
+			if o.promoteAfter != 0 && o.stagingDir == "" {
+				return fmt.Errorf("%q requires %q to be set", FlagPromoteAfter, FlagStagingDir)
+			}
+			if o.healthProbe != "" && o.Kubernetes.Destination != "" {
+				return fmt.Errorf("%q cannot be used together with %q", FlagHealthProbe, options.FlagK8sDestination)
+			}
+
+			var err error
+			if o.versionPolicy, err = install.ParseVersionPolicy(o.policy); err != nil {
+				return err
+			}
+
 			// Get Diginfra versions via HTTP endpoint
-			if err := o.retrieveDiginfraVersions(ctx); err != nil {
+			if o.versions, err = o.retrieveDiginfraVersions(ctx); err != nil {
 				return fmt.Errorf("unable to retrieve Diginfra versions, please check if it is running "+
 					"and correctly exposing the version endpoint: %w", err)
 			}
@@ -235,6 +492,8 @@ func NewArtifactFollowCmd(ctx context.Context, opt *options.Common) *cobra.Comma
 
 	o.Registry.AddFlags(cmd)
 	o.Directory.AddFlags(cmd)
+	o.Kubernetes.AddFlags(cmd)
+	o.Layout.AddFlags(cmd)
 	cmd.Flags().DurationVarP(&o.every, "every", "e", config.FollowResync, "Time interval how often it checks for a new version of the "+
 		"artifact. Cannot be used together with 'cron' option.")
 	cmd.Flags().StringVar(&o.cron, "cron", "", "Cron-like string to specify interval how often it checks for a new version of the artifact."+
@@ -243,7 +502,8 @@ func NewArtifactFollowCmd(ctx context.Context, opt *options.Common) *cobra.Comma
 	cmd.Flags().StringVar(&o.diginfraVersions, "diginfra-versions", "http://localhost:8765/versions",
 		"Where to retrieve versions, it can be either an URL or a path to a file")
 	cmd.Flags().DurationVar(&o.timeout, "timeout", defaultBackoffConfig.MaxDelay,
-		"Timeout for initial connection to the Diginfra versions endpoint")
+		"Timeout for retrieving the versions from the Diginfra versions endpoint at startup, retried with backoff until then. "+
+			"Before installing each new artifact version, they are refreshed with a short backoff of a few seconds")
 	cmd.Flags().Var(&o.allowedTypes, install.FlagAllowedTypes,
 		fmt.Sprintf(`list of artifact types that can be followed. If not specified or configured, all types are allowed.
 It accepts comma separated values or it can be repeated multiple times.
@@ -252,6 +512,43 @@ Examples:
 	--%s=rulesfile --%s=plugin`, install.FlagAllowedTypes, install.FlagAllowedTypes, install.FlagAllowedTypes))
 	cmd.Flags().BoolVar(&o.noVerify, install.FlagNoVerify, false,
 		"whether this command should skip signature verification")
+	cmd.Flags().StringVar(&o.controlSocket, FlagControlSocket, "",
+		fmt.Sprintf("path of the Unix socket where to serve the control API used by the \"follow\" commands "+
+			"(e.g. %q). Disabled if empty", config.FollowControlSocket))
+	cmd.Flags().StringVar(&o.webhookAddr, FlagWebhookAddr, "",
+		fmt.Sprintf("address where to receive registry push notifications triggering an immediate sync (e.g. \":9090\"). "+
+			"Distribution envelopes are served under %q, generic payloads under %q. Disabled if empty",
+			webhook.DistributionPath, webhook.GenericPath))
+	cmd.Flags().StringVar(&o.webhookSecret, FlagWebhookSecret, "",
+		fmt.Sprintf("shared secret used to verify the %q HMAC of generic payloads and the bearer token of distribution envelopes",
+			webhook.SignatureHeader))
+	cmd.Flags().StringVar(&o.stagingDir, FlagStagingDir, "",
+		fmt.Sprintf("directory where new versions are staged, after being pulled and verified, until approved. Approve a version with "+
+			"the \"follow approve\" command or by creating the %q file in its staging directory, reject it with \"follow reject\" "+
+			"or the %q file. If empty, new versions are installed right away", follower.ApproveMarker, follower.RejectMarker))
+	cmd.Flags().DurationVar(&o.promoteAfter, FlagPromoteAfter, 0,
+		"delay after which a staged version is installed unless rejected (e.g. \"24h\"). If zero, an explicit approval is required")
+	cmd.Flags().StringVar(&o.auditLog, install.FlagAuditLog, config.AuditLogFile,
+		"path of the JSON-lines file where the outcome of each sync is recorded. Disabled if empty")
+	cmd.Flags().StringVar(&o.policy, install.FlagVersionPolicy, string(index.VersionPolicyWarn),
+		fmt.Sprintf("what to do when the indexes mark a new version as deprecated, past its end of life or affected "+
+			"by a security advisory: %q logs a warning, %q refuses the installation", index.VersionPolicyWarn, index.VersionPolicyRefuse))
+	cmd.Flags().StringVar(&o.healthProbe, FlagHealthProbe, "",
+		fmt.Sprintf("probe checking Diginfra after each installation: an HTTP URL answering with a 2xx status, %q to query the "+
+			"versions endpoint given by \"diginfra-versions\", or %q followed by a command exiting with status 0. If Diginfra turns "+
+			"unhealthy, the previous version is restored and the new one is not installed again. Disabled if empty",
+			health.VersionProbe, health.ExecPrefix))
+	cmd.Flags().DurationVar(&o.healthGrace, FlagHealthGracePeriod, health.DefaultGracePeriod,
+		"how long Diginfra is watched after each installation")
+	cmd.Flags().DurationVar(&o.healthInterval, FlagHealthInterval, health.DefaultInterval,
+		fmt.Sprintf("time between two health probes. Diginfra is unhealthy after %d consecutive failures, "+
+			"or if the probe is still failing at the end of the grace period", health.DefaultFailureThreshold))
+	cmd.Flags().IntVar(&o.workers, FlagWorkers, config.FollowWorkers,
+		"maximum number of artifacts synced at the same time")
+	cmd.Flags().BoolVar(&o.once, FlagOnce, false,
+		fmt.Sprintf("run a single sync pass over all the artifacts, print a JSON summary and exit with code %d if nothing changed, "+
+			"%d if at least one artifact has been installed or updated, %d if at least one failed. "+
+			"The control API and the webhook receiver are not started", ExitCodeNoChange, ExitCodeUpdated, ExitCodeFailed))
 	cmd.MarkFlagsMutuallyExclusive("cron", "every")
 
 	return cmd
@@ -267,86 +564,200 @@ func (o *artifactFollowOptions) RunArtifactFollow(ctx context.Context, args []st
 	}
 
 	// Set args as configured if no arg was passed
-	if len(args) == 0 {
+	artifacts := make([]config.ArtifactSpec, 0, len(args))
+	for _, a := range args {
+		artifacts = append(artifacts, config.ArtifactSpec{Ref: a})
+	}
+	if len(artifacts) == 0 {
 		if len(configuredFollower.Artifacts) == 0 {
 			return fmt.Errorf("no artifacts to follow, please configure artifacts or pass them as arguments to this command")
 		}
-		args = configuredFollower.Artifacts
+		artifacts = configuredFollower.Artifacts
 	}
 
-	var sched cron.Schedule
+	var defaultSched cron.Schedule
 	if o.cron != "" {
-		sched, err = cron.ParseStandard(o.cron)
+		cronSched, err := cron.ParseStandard(o.cron)
 		if err != nil {
 			return fmt.Errorf("unable to parse cron '%s': %w", o.cron, err)
 		}
+		defaultSched = scheduledCron{Schedule: cronSched, spec: o.cron}
 	} else {
-		sched = scheduledDuration{o.every}
+		defaultSched = scheduledDuration{o.every}
+	}
+
+	// All the followers share the same audit log.
+	var auditLog *audit.Log
+	if o.auditLog != "" {
+		auditLog = audit.New(o.auditLog)
+	}
+
+	// All the followers share the same Kubernetes destination, if any.
+	kubeDest, err := o.NewDestination()
+	if err != nil {
+		return fmt.Errorf("unable to set up the Kubernetes destination: %w", err)
+	}
+
+	// All the followers share the same health check, if any.
+	var healthCheck *health.Check
+	if o.healthProbe != "" {
+		probe, err := health.ParseProbe(o.healthProbe, o.diginfraVersions)
+		if err != nil {
+			return err
+		}
+		healthCheck = &health.Check{
+			Probe:            probe,
+			GracePeriod:      o.healthGrace,
+			Interval:         o.healthInterval,
+			FailureThreshold: health.DefaultFailureThreshold,
+		}
+	}
+
+	// All the followers share the same registry client, and thus the same cached tokens.
+	client, err := ociutils.Client(true)
+	if err != nil {
+		return err
+	}
+
+	mirrors, err := ociutils.Mirrors()
+	if err != nil {
+		return err
 	}
 
-	var wg sync.WaitGroup
 	// For each artifact create a follower.
-	var followers = make(map[string]*follower.Follower, 0)
-	for _, a := range args {
-		if o.cron != "" {
-			logger.Info("Creating follower", logger.Args("artifact", a, "cron", o.cron))
+	// The followers share the refreshed versions, so that the endpoint is queried once for all of them.
+	versionsCache := follower.NewVersionsCache(o.refreshDiginfraVersions, versionsRefreshInterval)
+	followers := make([]*follower.Follower, 0, len(artifacts))
+	repos := make(map[string]string, len(artifacts))
+	for _, a := range artifacts {
+		// The settings of the artifact, if configured, override the ones given by the flags.
+		sched := defaultSched
+		if a.Schedule != "" {
+			if sched, err = parseSchedule(a.Schedule); err != nil {
+				return fmt.Errorf("invalid schedule for artifact %q: %w", a.Ref, err)
+			}
+		}
+		if sc, ok := sched.(scheduledCron); ok {
+			logger.Info("Creating follower", logger.Args("artifact", a.Ref, "cron", sc.spec))
 		} else {
-			logger.Info("Creating follower", logger.Args("artifact", a, "check every", o.every.String()))
+			logger.Info("Creating follower", logger.Args("artifact", a.Ref, "check every", fmt.Sprint(sched)))
+		}
+		ref, err := o.IndexCache.ResolveReference(a.Ref)
+		if err != nil {
+			return fmt.Errorf("unable to parse artifact reference for %q: %w", a.Ref, err)
+		}
+		repo, err := utils.RepositoryFromRef(ref)
+		if err != nil {
+			return fmt.Errorf("unable to parse artifact reference for %q: %w", a.Ref, err)
 		}
-		ref, err := o.IndexCache.ResolveReference(a)
+		// Followers of the same repository would install the same artifact, only the first one is kept.
+		if other, ok := repos[repo]; ok {
+			logger.Warn("Repository already followed, ignoring the artifact", logger.Args("artifact", a.Ref, "ref", ref,
+				"followed", other))
+			continue
+		}
+		repos[repo] = ref
+
+		platformOS, platformArch, err := install.ParsePlatform(a.Platform)
 		if err != nil {
-			return fmt.Errorf("unable to parse artifact reference for %q: %w", a, err)
+			return fmt.Errorf("invalid platform for artifact %q: %w", a.Ref, err)
 		}
 
+		noVerify := o.noVerify
+		if a.NoVerify != nil {
+			noVerify = *a.NoVerify
+		}
 		var sig *index.Signature
-		if !o.noVerify {
-			sig = o.IndexCache.SignatureForIndexRef(a)
+		if !noVerify {
+			if sig = index.SignatureFromConfig(a.Signature); sig == nil {
+				sig = o.IndexCache.SignatureForIndexRef(a.Ref)
+			}
 		}
 
+		indexEntry, _ := o.IndexCache.EntryByReference(ref)
+
 		cfg := &follower.Config{
-			WaitGroup:         &wg,
 			Resync:            sched,
-			RulesfilesDir:     o.RulesfilesDir,
-			PluginsDir:        o.PluginsDir,
-			AssetsDir:         o.AssetsDir,
+			RulesfilesDir:     cmp.Or(a.RulesfilesDir, o.RulesfilesDir),
+			PluginsDir:        cmp.Or(a.PluginsDir, o.PluginsDir),
+			AssetsDir:         cmp.Or(a.AssetsDir, o.AssetsDir),
 			ArtifactReference: ref,
 			PlainHTTP:         o.PlainHTTP,
-			CloseChan:         o.closeChan,
+			Client:            client,
+			Mirrors:           mirrors,
 			TmpDir:            o.tmpDir,
 			DiginfraVersions:  o.versions,
-			AllowedTypes:      o.allowedTypes,
-			Signature:         sig,
+			// Diginfra may be upgraded while we are running, check its versions again before each install.
+			RefreshDiginfraVersions: versionsCache.Get,
+			AllowedTypes:            o.allowedTypes,
+			Signature:               sig,
+			StagingDir:              o.stagingDir,
+			PromoteAfter:            o.promoteAfter,
+			Audit:                   auditLog,
+			Kube:                    kubeDest,
+			PlatformOS:              platformOS,
+			PlatformArch:            platformArch,
+			Versioned:               o.Versioned,
+			KeepVersions:            o.KeepVersions,
+			Health:                  healthCheck,
+			IndexEntry:              indexEntry,
+			VersionPolicy:           o.versionPolicy,
 		}
 		fol, err := follower.New(ref, o.Printer, cfg)
 		if err != nil {
 			return fmt.Errorf("unable to create the follower for ref %q: %w", ref, err)
 		}
-		wg.Add(1)
-		followers[ref] = fol
+		followers = append(followers, fol)
 	}
 
-	for k, f := range followers {
-		logger.Info("Starting follower", logger.Args("artifact", k))
-		go f.Follow(ctx)
+	scheduler := follower.NewScheduler(o.Printer, o.workers, followers...)
+	if o.once {
+		return o.runOnce(ctx, scheduler)
 	}
 
-	// Wait until we receive a signal to be terminated
-	<-ctx.Done()
+	logger.Info("Starting followers", logger.Args("artifacts", len(followers), "workers", o.workers))
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(ctx)
+		close(done)
+	}()
 
-	// We are done, shutdown the followers.
-	logger.Info("Closing followers...")
-	close(o.closeChan)
+	if o.controlSocket != "" {
+		targets := make([]control.Target, 0, len(followers))
+		for _, f := range followers {
+			targets = append(targets, f)
+		}
+		srv := control.NewServer(o.Printer, targets...)
+		go func() {
+			if err := srv.ListenAndServe(ctx, o.controlSocket); err != nil {
+				logger.Error("Control API stopped", logger.Args("reason", err.Error()))
+			}
+		}()
+	}
 
-	// Wait for the followers to shutdown or that the timer expires.
-	doneChan := make(chan bool)
+	if o.webhookAddr != "" {
+		if o.webhookSecret == "" {
+			logger.Warn("No webhook secret configured: distribution notifications are not authenticated and generic payloads are rejected")
+		}
+		targets := make([]webhook.Target, 0, len(followers))
+		for _, f := range followers {
+			targets = append(targets, f)
+		}
+		receiver := webhook.NewReceiver(o.Printer, o.webhookSecret, targets...)
+		go func() {
+			if err := receiver.ListenAndServe(ctx, o.webhookAddr); err != nil {
+				logger.Error("Webhook receiver stopped", logger.Args("reason", err.Error()))
+			}
+		}()
+	}
 
-	go func() {
-		wg.Wait()
-		close(doneChan)
-	}()
+	// Wait until we receive a signal to be terminated
+	<-ctx.Done()
 
+	// We are done, wait for the followers to shutdown or that the timer expires.
+	logger.Info("Closing followers...")
 	select {
-	case <-doneChan:
+	case <-done:
 		logger.Info("Followers correctly stopped.")
 	case <-time.After(timeout):
 		logger.Info("Timed out waiting for followers to exit")
@@ -355,19 +766,31 @@ func (o *artifactFollowOptions) RunArtifactFollow(ctx context.Context, args []st
 	return nil
 }
 
-func (o *artifactFollowOptions) retrieveDiginfraVersions(ctx context.Context) error {
-	_, err := url.ParseRequestURI(o.diginfraVersions)
-	if err != nil {
-		return fmt.Errorf("unable to parse URI: %w", err)
-	}
+// retrieveDiginfraVersions fetches the versions exposed by Diginfra, retrying with backoff
+// until the configured timeout expires.
+func (o *artifactFollowOptions) retrieveDiginfraVersions(ctx context.Context) (config.DiginfraVersions, error) {
+	backoffConfig := defaultBackoffConfig
+	backoffConfig.MaxDelay = o.timeout
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.diginfraVersions, http.NoBody)
-	if err != nil {
-		return fmt.Errorf("cannot fetch Diginfra version: %w", err)
+	client := &http.Client{
+		Transport: &backoffTransport{
+			Base:    http.DefaultTransport,
+			Printer: o.Printer,
+			Config:  backoffConfig,
+		},
 	}
+	return o.fetchDiginfraVersions(ctx, client)
+}
+
+// refreshDiginfraVersions fetches the versions exposed by Diginfra, retrying with backoff for
+// at most versionsRefreshTimeout, so that a restarting Diginfra does not make the refresh fail
+// while the followers still fall back to the last known versions quickly.
+func (o *artifactFollowOptions) refreshDiginfraVersions(ctx context.Context) (config.DiginfraVersions, error) {
+	ctx, cancel := context.WithTimeout(ctx, versionsRefreshTimeout)
+	defer cancel()
 
 	backoffConfig := defaultBackoffConfig
-	backoffConfig.MaxDelay = o.timeout
+	backoffConfig.MaxDelay = versionsRefreshTimeout
 
 	client := &http.Client{
 		Transport: &backoffTransport{
@@ -376,32 +799,47 @@ func (o *artifactFollowOptions) retrieveDiginfraVersions(ctx context.Context) er
 			Config:  backoffConfig,
 		},
 	}
+	return o.fetchDiginfraVersions(ctx, client)
+}
+
+func (o *artifactFollowOptions) fetchDiginfraVersions(ctx context.Context, client *http.Client) (config.DiginfraVersions, error) {
+	_, err := url.ParseRequestURI(o.diginfraVersions)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse URI: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.diginfraVersions, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch Diginfra version: %w", err)
+	}
+
 	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("unable to get versions from URL %q: %w", o.diginfraVersions, err)
+		return nil, fmt.Errorf("unable to get versions from URL %q: %w", o.diginfraVersions, err)
 	}
 	defer resp.Body.Close()
 
 	data, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("unable to read response body: %w", err)
+		return nil, fmt.Errorf("unable to read response body: %w", err)
 	}
 
 	var dataUnmarshalled map[string]interface{}
 
 	err = json.Unmarshal(data, &dataUnmarshalled)
 	if err != nil {
-		return fmt.Errorf("error unmarshalling: %w", err)
+		return nil, fmt.Errorf("error unmarshalling: %w", err)
 	}
 
+	versions := config.DiginfraVersions{}
 	for key, value := range dataUnmarshalled {
 		// todo(alacuku): how to handle types other than strings? Silently ignoring for now...
 		if strValue, ok := value.(string); ok {
-			o.versions[key] = strValue
+			versions[key] = strValue
 		}
 	}
 
-	return nil
+	return versions, nil
 }
 
 // Config defines the configuration options for backoff.
@@ -456,7 +894,11 @@ func (bt *backoffTransport) RoundTrip(req *http.Request) (*http.Response, error)
 			}
 
 			logger.Debug(fmt.Sprintf("error: %s. Trying again in %s", err.Error(), sleep.String()))
-			time.Sleep(sleep)
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(sleep):
+			}
 		} else {
 			logger.Debug("Successfully retrieved versions from Diginfra")
 			return resp, err
@@ -498,3 +940,30 @@ type scheduledDuration struct {
 func (sd scheduledDuration) Next(tm time.Time) time.Time {
 	return tm.Add(sd.Duration)
 }
+
+// parseSchedule parses the schedule of an artifact, which is either a duration or a cron expression.
+func parseSchedule(spec string) (cron.Schedule, error) {
+	if d, err := time.ParseDuration(spec); err == nil {
+		if d <= 0 {
+			return nil, fmt.Errorf("duration must be positive, got %q", spec)
+		}
+		return scheduledDuration{d}, nil
+	}
+
+	cronSched, err := cron.ParseStandard(spec)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %q as a duration or a cron expression: %w", spec, err)
+	}
+
+	return scheduledCron{Schedule: cronSched, spec: spec}, nil
+}
+
+// scheduledCron wraps a cron schedule keeping track of the spec it has been parsed from.
+type scheduledCron struct {
+	cron.Schedule
+	spec string
+}
+
+func (sc scheduledCron) String() string {
+	return sc.spec
+}
@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/diginfra/diginfractl/internal/audit"
+	"github.com/diginfra/diginfractl/internal/follower"
+	"github.com/diginfra/diginfractl/internal/utils"
+)
+
+// Exit codes of the --once mode.
+const (
+	// ExitCodeNoChange is returned when all the artifacts were already up to date.
+	ExitCodeNoChange = 0
+	// ExitCodeFailed is returned when at least one artifact failed to sync.
+	ExitCodeFailed = 1
+	// ExitCodeUpdated is returned when at least one artifact has been installed or updated, and none failed.
+	ExitCodeUpdated = 2
+)
+
+// Outcomes reported in the summary of the --once mode.
+const (
+	onceNoChange = "no-change"
+	onceUpdated  = "updated"
+	onceFailed   = "failed"
+)
+
+// onceSummary is the machine-readable summary printed by the --once mode.
+type onceSummary struct {
+	Outcome   string         `json:"outcome"`
+	Artifacts []*audit.Entry `json:"artifacts"`
+}
+
+// runOnce runs a single sync pass over all the followers, prints the summary and returns
+// a *utils.ExitError carrying the exit code unless nothing changed.
+func (o *artifactFollowOptions) runOnce(ctx context.Context, scheduler *follower.Scheduler) error {
+	results := scheduler.RunOnce(ctx)
+	refs := make([]string, 0, len(results))
+	for ref := range results {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+
+	summary := onceSummary{Outcome: onceNoChange, Artifacts: []*audit.Entry{}}
+	failed := 0
+	for _, ref := range refs {
+		for _, e := range results[ref] {
+			summary.Artifacts = append(summary.Artifacts, e)
+			switch {
+			case e.Outcome == audit.OutcomeFailed || e.Outcome == audit.OutcomeUnhealthy:
+				failed++
+			case e.Succeeded() && summary.Outcome == onceNoChange:
+				summary.Outcome = onceUpdated
+			}
+		}
+	}
+	if failed > 0 {
+		summary.Outcome = onceFailed
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("unable to encode summary: %w", err)
+	}
+	o.Printer.DefaultText.Println(string(data))
+
+	switch summary.Outcome {
+	case onceFailed:
+		return &utils.ExitError{Code: ExitCodeFailed, Err: fmt.Errorf("%d artifact(s) failed to sync", failed)}
+	case onceUpdated:
+		return &utils.ExitError{Code: ExitCodeUpdated}
+	default:
+		return nil
+	}
+}
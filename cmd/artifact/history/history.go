@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/diginfra/diginfractl/cmd/artifact/install"
+	"github.com/diginfra/diginfractl/internal/audit"
+	"github.com/diginfra/diginfractl/internal/config"
+	"github.com/diginfra/diginfractl/internal/utils"
+	"github.com/diginfra/diginfractl/pkg/options"
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+const (
+	jsonFormat = "json"
+	// shortDigestLen is the length of the digests shown in the table, algorithm included.
+	shortDigestLen = len("sha256:") + 12
+
+	longHistory = `Show the audit log of the "artifact install" and "artifact follow" commands.
+
+Every installation, update, skip and failure is recorded, along with the old and new digests,
+the version, the identity of the signer and the files written. When a name is given, only
+the entries of the matching artifact are shown. The name can be either the name of the artifact
+in the configured indexes or a reference.
+
+Example - Show when each version of "k8saudit-rules" went live:
+	diginfractl artifact history k8saudit-rules
+
+Example - Dump the last 10 entries as JSON lines:
+	diginfractl artifact history --limit 10 -o json
+`
+)
+
+var errOutputFlag = errors.New("--output must be 'json' or empty")
+
+type artifactHistoryOptions struct {
+	*options.Common
+	auditLog string
+	limit    int
+	output   string
+}
+
+// NewArtifactHistoryCmd returns the artifact history command.
+func NewArtifactHistoryCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := artifactHistoryOptions{
+		Common: opt,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "history [name] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show the history of the installed artifacts",
+		Long:                  longHistory,
+		Args:                  cobra.MaximumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Override "audit-log" flag with viper config if not set by user.
+			f := cmd.Flags().Lookup(install.FlagAuditLog)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", install.FlagAuditLog)
+			} else if !f.Changed && viper.IsSet(config.ArtifactAuditLogKey) {
+				val := viper.Get(config.ArtifactAuditLogKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", install.FlagAuditLog, err)
+				}
+			}
+
+			if o.output != "" && o.output != jsonFormat {
+				return errOutputFlag
+			}
+			if o.auditLog == "" {
+				return fmt.Errorf("the audit log is disabled, please set the %q flag", install.FlagAuditLog)
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunArtifactHistory(ctx, args)
+		},
+	}
+
+	cmd.Flags().StringVar(&o.auditLog, install.FlagAuditLog, config.AuditLogFile, "path of the audit log to read")
+	cmd.Flags().IntVar(&o.limit, "limit", 0, "Only show the last n entries. All the entries are shown if zero")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "", "Output format, 'json' to print one JSON object per line")
+
+	return cmd
+}
+
+// RunArtifactHistory executes the business logic for the artifact history command.
+func (o *artifactHistoryOptions) RunArtifactHistory(_ context.Context, args []string) error {
+	entries, err := audit.Read(o.auditLog)
+	if err != nil {
+		return err
+	}
+
+	if len(args) > 0 {
+		entries = o.filter(entries, args[0])
+	}
+
+	if o.limit > 0 && len(entries) > o.limit {
+		entries = entries[len(entries)-o.limit:]
+	}
+
+	if o.output == jsonFormat {
+		for i := range entries {
+			line, err := json.Marshal(&entries[i])
+			if err != nil {
+				return err
+			}
+			o.Printer.DefaultText.Println(string(line))
+		}
+		return nil
+	}
+
+	var data [][]string
+	for i := range entries {
+		e := &entries[i]
+		data = append(data, []string{e.Timestamp.Local().Format(time.RFC3339), string(e.Operation), e.Ref, e.Version,
+			shortDigest(e.OldDigest), shortDigest(e.NewDigest), string(e.Outcome), e.Signer})
+	}
+
+	return o.Printer.PrintTable(output.ArtifactHistory, data)
+}
+
+// filter returns the entries of the artifact identified by name. The name can be either a
+// reference or the name of an artifact, in which case it is resolved through the indexes.
+func (o *artifactHistoryOptions) filter(entries []audit.Entry, name string) []audit.Entry {
+	repos := map[string]bool{repository(name): true}
+	if o.IndexCache != nil {
+		if ref, err := o.IndexCache.ResolveReference(name); err == nil {
+			repos[repository(ref)] = true
+		}
+	}
+
+	var filtered []audit.Entry
+	for i := range entries {
+		entryName, _ := utils.NameFromRef(entries[i].Ref)
+		if entries[i].Ref == name || entryName == name || repos[repository(entries[i].Ref)] {
+			filtered = append(filtered, entries[i])
+		}
+	}
+
+	return filtered
+}
+
+// repository strips the tag or the digest from ref.
+func repository(ref string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref
+}
+
+func shortDigest(digest string) string {
+	if len(digest) > shortDigestLen {
+		return digest[:shortDigestLen]
+	}
+	return digest
+}
@@ -20,16 +20,28 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/blang/semver/v4"
 	"github.com/spf13/cobra"
 	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
 
+	"github.com/diginfra/diginfractl/pkg/index/index"
+	"github.com/diginfra/diginfractl/pkg/oci/mirror"
 	"github.com/diginfra/diginfractl/pkg/oci/repository"
 	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
 	"github.com/diginfra/diginfractl/pkg/options"
 	"github.com/diginfra/diginfractl/pkg/output"
 )
 
+const longInfo = `Retrieve all available versions of a given artifact.
+
+When the indexes publish lifecycle metadata for the artifact, a warning is logged if the artifact
+is deprecated or past its end of life, and for each listed version that is deprecated, past its
+end of life or affected by a security advisory.
+`
+
 type artifactInfoOptions struct {
 	*options.Common
 	*options.Registry
@@ -46,7 +58,7 @@ func NewArtifactInfoCmd(ctx context.Context, opt *options.Common) *cobra.Command
 		Use:                   "info [ref1 [ref2 ...]] [flags]",
 		DisableFlagsInUseLine: true,
 		Short:                 "Retrieve all available versions of a given artifact",
-		Long:                  "Retrieve all available versions of a given artifact",
+		Long:                  longInfo,
 		Args:                  cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return o.RunArtifactInfo(ctx, args)
@@ -67,30 +79,26 @@ func (o *artifactInfoOptions) RunArtifactInfo(ctx context.Context, args []string
 		return err
 	}
 
+	mirrors, err := ociutils.Mirrors()
+	if err != nil {
+		return err
+	}
+
 	// resolve references
 	for _, name := range args {
 		var ref string
-		parsedRef, err := registry.ParseReference(name)
-		if err != nil {
-			entry, ok := o.IndexCache.MergedIndexes.EntryByName(name)
-			if !ok {
-				logger.Warn("Cannot find artifact, skipping", logger.Args("name", name))
-				continue
-			}
+		// Names qualified by their index, such as "myorg/cloudtrail", would also parse as references.
+		if entry, ok := o.IndexCache.MergedIndexes.ResolveEntry(name); ok {
 			ref = fmt.Sprintf("%s/%s", entry.Registry, entry.Repository)
-		} else {
+		} else if parsedRef, err := registry.ParseReference(name); err == nil {
 			parsedRef.Reference = ""
 			ref = parsedRef.String()
+		} else {
+			logger.Warn("Cannot find artifact, skipping", logger.Args("name", name))
+			continue
 		}
 
-		repo, err := repository.NewRepository(ref,
-			repository.WithClient(client),
-			repository.WithPlainHTTP(o.PlainHTTP))
-		if err != nil {
-			return err
-		}
-
-		tags, err := repo.Tags(ctx)
+		tags, err := o.tags(ctx, client, mirrors, ref)
 		if err != nil && !errors.Is(err, context.Canceled) {
 			logger.Warn("Cannot retrieve tags from", logger.Args("ref", ref, "reason", err.Error()))
 			continue
@@ -99,7 +107,12 @@ func (o *artifactInfoOptions) RunArtifactInfo(ctx context.Context, args []string
 			return err
 		}
 
-		joinedTags := strings.Join(filterOutSigTags(tags), ", ")
+		tags = filterOutSigTags(tags)
+		if entry, ok := o.IndexCache.EntryByReference(ref); ok && entry.HasLifecycle() {
+			o.warnLifecycle(ref, entry, tags)
+		}
+
+		joinedTags := strings.Join(tags, ", ")
 		data = append(data, []string{ref, joinedTags})
 	}
 
@@ -111,6 +124,49 @@ func (o *artifactInfoOptions) RunArtifactInfo(ctx context.Context, args []string
 	return nil
 }
 
+// tags returns the tags of the repository referenced by ref, listed by the first of its mirrors, or
+// by the repository itself, that answers.
+func (o *artifactInfoOptions) tags(ctx context.Context, client remote.Client, mirrors mirror.Mirrors, ref string) ([]string, error) {
+	var errs []error
+	for _, c := range mirrors.Candidates(ref, o.PlainHTTP) {
+		repo, err := repository.NewRepository(c.Ref,
+			repository.WithClient(client),
+			repository.WithPlainHTTP(c.PlainHTTP))
+		if err != nil {
+			return nil, err
+		}
+
+		tags, err := repo.Tags(ctx)
+		if err == nil || errors.Is(err, context.Canceled) {
+			return tags, err
+		}
+		errs = append(errs, err)
+	}
+
+	return nil, errors.Join(errs...)
+}
+
+// warnLifecycle logs the lifecycle notices of the artifact and of the versions among its tags.
+func (o *artifactInfoOptions) warnLifecycle(ref string, entry *index.Entry, tags []string) {
+	logger := o.Printer.Logger
+	now := time.Now()
+
+	common := entry.Notices("", now)
+	for _, n := range common {
+		logger.Warn(n.Message, logger.Args("ref", ref, "kind", string(n.Kind)))
+	}
+
+	for _, tag := range tags {
+		// Floating tags, such as "0.5" or "latest", point to versions listed with their full tag.
+		if _, err := semver.ParseTolerant(tag); err != nil || strings.Count(tag, ".") != 2 {
+			continue
+		}
+		for _, n := range entry.Notices(tag, now)[len(common):] {
+			logger.Warn(n.Message, logger.Args("ref", ref, "kind", string(n.Kind)))
+		}
+	}
+}
+
 func filterOutSigTags(tags []string) []string {
 	// Iterate the slice in reverse to avoid index shifting when deleting
 	for i := len(tags) - 1; i >= 0; i-- {
@@ -27,4 +27,10 @@ const (
 
 	// FlagNoVerify is the name of the flag to disable signature verification.
 	FlagNoVerify = "no-verify"
+
+	// FlagAuditLog is the name of the flag to specify the path of the audit log.
+	FlagAuditLog = "audit-log"
+
+	// FlagVersionPolicy is the name of the flag to specify what to do with deprecated and vulnerable versions.
+	FlagVersionPolicy = "version-policy"
 )
@@ -16,21 +16,27 @@
 package install
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/diginfra/diginfractl/internal/audit"
 	"github.com/diginfra/diginfractl/internal/config"
+	"github.com/diginfra/diginfractl/internal/kube"
+	"github.com/diginfra/diginfractl/internal/layout"
 	"github.com/diginfra/diginfractl/internal/signature"
 	"github.com/diginfra/diginfractl/internal/utils"
 	"github.com/diginfra/diginfractl/pkg/index/index"
 	"github.com/diginfra/diginfractl/pkg/oci"
+	ocipuller "github.com/diginfra/diginfractl/pkg/oci/puller"
 	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
 	"github.com/diginfra/diginfractl/pkg/options"
 )
@@ -74,20 +80,41 @@ type artifactInstallOptions struct {
 	*options.Common
 	*options.Registry
 	*options.Directory
-	allowedTypes oci.ArtifactTypeSlice
-	platform     string // Raw string from command line
-	platformArch string // Architecture portion of parsed platform string
-	platformOS   string // OS portion of parsed platform string
-	resolveDeps  bool
-	noVerify     bool
+	*options.Kubernetes
+	*options.Layout
+	allowedTypes  oci.ArtifactTypeSlice
+	platform      string // Raw string from command line
+	platformArch  string // Architecture portion of parsed platform string
+	platformOS    string // OS portion of parsed platform string
+	resolveDeps   bool
+	noVerify      bool
+	auditLog      string
+	policy        string // Raw string from command line
+	versionPolicy index.VersionPolicy
+	kubeDest      *kube.Destination
+	// overrides are the settings of the configured artifacts, by resolved reference.
+	overrides map[string]artifactSettings
+}
+
+// artifactSettings are the settings used to install an artifact: the ones given by the flags,
+// possibly overridden by the configuration of the artifact.
+type artifactSettings struct {
+	rulesfilesDir string
+	pluginsDir    string
+	assetsDir     string
+	platformOS    string
+	platformArch  string
+	noVerify      bool
 }
 
 // NewArtifactInstallCmd returns the artifact install command.
 func NewArtifactInstallCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 	o := artifactInstallOptions{
-		Common:    opt,
-		Registry:  &options.Registry{},
-		Directory: &options.Directory{},
+		Common:     opt,
+		Registry:   &options.Registry{},
+		Directory:  &options.Directory{},
+		Kubernetes: &options.Kubernetes{},
+		Layout:     &options.Layout{},
 	}
 
 	cmd := &cobra.Command{
@@ -169,16 +196,98 @@ func NewArtifactInstallCmd(ctx context.Context, opt *options.Common) *cobra.Comm
 				}
 			}
 
-			// Parse "platform" into OS and Arch
-			if len(o.platform) > 0 {
-				parts := strings.Split(o.platform, "/")
-				if len(parts) != 2 {
-					return fmt.Errorf("invalid %q: must be in the format OS/Arch", FlagPlatform)
+			// Override "audit-log" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(FlagAuditLog)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", FlagAuditLog)
+			} else if !f.Changed && viper.IsSet(config.ArtifactAuditLogKey) {
+				val := viper.Get(config.ArtifactAuditLogKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagAuditLog, err)
+				}
+			}
+
+			// Override "version-policy" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(FlagVersionPolicy)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", FlagVersionPolicy)
+			} else if !f.Changed && viper.IsSet(config.ArtifactVersionPolicyKey) {
+				val := viper.Get(config.ArtifactVersionPolicyKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", FlagVersionPolicy, err)
+				}
+			}
+
+			// Override "k8s-destination" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(options.FlagK8sDestination)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagK8sDestination)
+			} else if !f.Changed && viper.IsSet(config.ArtifactK8sDestinationKey) {
+				val := viper.Get(config.ArtifactK8sDestinationKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagK8sDestination, err)
+				}
+			}
+
+			// Override "k8s-namespace" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(options.FlagK8sNamespace)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagK8sNamespace)
+			} else if !f.Changed && viper.IsSet(config.ArtifactK8sNamespaceKey) {
+				val := viper.Get(config.ArtifactK8sNamespaceKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagK8sNamespace, err)
+				}
+			}
+
+			// Override "kubeconfig" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(options.FlagKubeconfig)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagKubeconfig)
+			} else if !f.Changed && viper.IsSet(config.ArtifactK8sKubeconfigKey) {
+				val := viper.Get(config.ArtifactK8sKubeconfigKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagKubeconfig, err)
+				}
+			}
+
+			// Override "versioned" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(options.FlagVersioned)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagVersioned)
+			} else if !f.Changed && viper.IsSet(config.ArtifactVersionedKey) {
+				val := viper.Get(config.ArtifactVersionedKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagVersioned, err)
 				}
-				o.platformOS, o.platformArch = parts[0], parts[1]
 			}
 
-			return nil
+			// Override "keep-versions" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(options.FlagKeepVersions)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagKeepVersions)
+			} else if !f.Changed && viper.IsSet(config.ArtifactKeepVersionsKey) {
+				val := viper.Get(config.ArtifactKeepVersionsKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagKeepVersions, err)
+				}
+			}
+
+			// Parse "platform" into OS and Arch
+			var err error
+			if o.platformOS, o.platformArch, err = ParsePlatform(o.platform); err != nil {
+				return fmt.Errorf("invalid %q: %w", FlagPlatform, err)
+			}
+
+			o.versionPolicy, err = ParseVersionPolicy(o.policy)
+			return err
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return o.RunArtifactInstall(ctx, args)
@@ -187,6 +296,8 @@ func NewArtifactInstallCmd(ctx context.Context, opt *options.Common) *cobra.Comm
 
 	o.Registry.AddFlags(cmd)
 	o.Directory.AddFlags(cmd)
+	o.Kubernetes.AddFlags(cmd)
+	o.Layout.AddFlags(cmd)
 	cmd.Flags().Var(&o.allowedTypes, FlagAllowedTypes,
 		fmt.Sprintf(`list of artifact types that can be installed. If not specified or configured, all types are allowed.
 It accepts comma separated values or it can be repeated multiple times.
@@ -199,6 +310,11 @@ Examples:
 		"whether this command should resolve dependencies or not")
 	cmd.Flags().BoolVar(&o.noVerify, FlagNoVerify, false,
 		"whether this command should skip signature verification")
+	cmd.Flags().StringVar(&o.auditLog, FlagAuditLog, config.AuditLogFile,
+		"path of the JSON-lines file where the outcome of each installation is recorded. Disabled if empty")
+	cmd.Flags().StringVar(&o.policy, FlagVersionPolicy, string(index.VersionPolicyWarn),
+		fmt.Sprintf("what to do when the indexes mark the selected version as deprecated, past its end of life or affected "+
+			"by a security advisory: %q logs a warning, %q refuses the installation", index.VersionPolicyWarn, index.VersionPolicyRefuse))
 
 	return cmd
 }
@@ -212,12 +328,17 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 		return fmt.Errorf("unable to retrieve the configured installer: %w", err)
 	}
 
+	signatures := make(map[string]*index.Signature)
+
 	// Set args as configured if no arg was passed
 	if len(args) == 0 {
 		if len(configuredInstaller.Artifacts) == 0 {
 			return fmt.Errorf("no artifacts to install, please configure artifacts or pass them as arguments to this command")
 		}
-		args = configuredInstaller.Artifacts
+		args = config.ArtifactRefs(configuredInstaller.Artifacts)
+		if err := o.loadOverrides(configuredInstaller.Artifacts, signatures); err != nil {
+			return err
+		}
 	}
 
 	// Create temp dir where to put pulled artifacts
@@ -233,6 +354,9 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 		return err
 	}
 
+	// Config layers fetched while resolving the dependencies, used to record the installed versions.
+	configs := make(map[string]*oci.ArtifactConfig)
+
 	// Specify how to pull config layer for each artifact requested by user.
 	resolver := artifactConfigResolver(func(ref string) (*oci.RegistryResult, error) {
 		ref, err := o.IndexCache.ResolveReference(ref)
@@ -240,25 +364,26 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 			return nil, err
 		}
 
-		artifactConfig, err := puller.ArtifactConfig(ctx, ref, o.platformOS, o.platformArch)
+		s := o.settings(ref)
+		artifactConfig, err := puller.ArtifactConfig(ctx, ref, s.platformOS, s.platformArch)
 		if err != nil {
 			return nil, err
 		}
+		configs[ref] = artifactConfig
 
 		return &oci.RegistryResult{
 			Config: *artifactConfig,
 		}, nil
 	})
 
-	signatures := make(map[string]*index.Signature)
-
 	// Compute input to install dependencies
 	for i, arg := range args {
 		ref, err := o.IndexCache.ResolveReference(arg)
 		if err != nil {
 			return err
 		}
-		if sig := o.IndexCache.SignatureForIndexRef(arg); sig != nil {
+		// Signatures configured for the artifact take precedence over the ones of the indexes.
+		if sig := o.IndexCache.SignatureForIndexRef(arg); sig != nil && signatures[ref] == nil {
 			signatures[ref] = sig
 		}
 		args[i] = ref
@@ -276,99 +401,318 @@ func (o *artifactInstallOptions) RunArtifactInstall(ctx context.Context, args []
 		refs = args
 	}
 
+	if o.kubeDest, err = o.NewDestination(); err != nil {
+		return fmt.Errorf("unable to set up the Kubernetes destination: %w", err)
+	}
+
 	logger.Info("Installing artifacts", logger.Args("refs", refs))
 
+	var auditLog *audit.Log
+	if o.auditLog != "" {
+		auditLog = audit.New(o.auditLog)
+	}
+
 	for _, ref := range refs {
-		resolvedRef, err := o.IndexCache.ResolveReference(ref)
+		entry := &audit.Entry{Operation: audit.OperationInstall, Ref: ref}
+		err := o.installArtifact(ctx, puller, tmpDir, ref, signatures, configs, auditLog, entry)
+		if err != nil {
+			entry.Outcome = audit.OutcomeFailed
+			entry.Error = err.Error()
+		}
+		if auditErr := auditLog.Record(entry); auditErr != nil {
+			logger.Warn("Unable to write audit log", logger.Args("reason", auditErr.Error()))
+		}
 		if err != nil {
 			return err
 		}
+	}
 
-		if signatures[resolvedRef] == nil {
-			if sig := o.IndexCache.SignatureForIndexRef(ref); sig != nil {
-				signatures[resolvedRef] = sig
-			}
+	return nil
+}
+
+// installArtifact pulls, verifies and installs a single artifact, filling the audit entry along the way.
+func (o *artifactInstallOptions) installArtifact(ctx context.Context, puller *ocipuller.Puller, tmpDir, ref string,
+	signatures map[string]*index.Signature, configs map[string]*oci.ArtifactConfig, auditLog *audit.Log, entry *audit.Entry) error {
+	logger := o.Printer.Logger
+
+	resolvedRef, err := o.IndexCache.ResolveReference(ref)
+	if err != nil {
+		return err
+	}
+
+	if signatures[resolvedRef] == nil {
+		if sig := o.IndexCache.SignatureForIndexRef(ref); sig != nil {
+			signatures[resolvedRef] = sig
 		}
+	}
+
+	entry.Ref = resolvedRef
+	if entry.OldDigest, err = auditLog.LastDigest(resolvedRef); err != nil {
+		logger.Warn("Unable to read audit log", logger.Args("reason", err.Error()))
+	}
+	if cfg, ok := configs[resolvedRef]; ok {
+		entry.Version = cfg.Version
+	}
+
+	logger.Info("Preparing to pull artifact", logger.Args("ref", resolvedRef))
+
+	s := o.settings(resolvedRef)
+	if err := o.checkVersion(ctx, puller, resolvedRef, s, entry); err != nil {
+		return err
+	}
+	if err := puller.CheckAllowedType(ctx, resolvedRef, s.platformOS, s.platformArch, o.allowedTypes.Types); err != nil {
+		return err
+	}
 
-		logger.Info("Preparing to pull artifact", logger.Args("ref", resolvedRef))
+	// Install will always install artifact for the current OS and architecture
+	result, err := puller.Pull(ctx, resolvedRef, tmpDir, s.platformOS, s.platformArch)
+	if err != nil {
+		return err
+	}
+	entry.NewDigest = result.RootDigest
+
+	sig := signatures[resolvedRef]
 
-		if err := puller.CheckAllowedType(ctx, resolvedRef, o.platformOS, o.platformArch, o.allowedTypes.Types); err != nil {
+	if sig != nil && !s.noVerify {
+		// The signature is checked where the artifact has been pulled from, which may be a mirror.
+		repo, err := utils.RepositoryFromRef(result.Source)
+		if err != nil {
 			return err
 		}
 
-		// Install will always install artifact for the current OS and architecture
-		result, err := puller.Pull(ctx, resolvedRef, tmpDir, o.platformOS, o.platformArch)
+		// In order to prevent TOCTOU issues we'll perform signature verification after we complete a pull
+		// and obtained a digest but before files are written to disk. This way we ensure that we're verifying
+		// the exact digest that we just pulled, even if the tag gets overwritten in the meantime.
+		digestRef := fmt.Sprintf("%s@%s", repo, result.RootDigest)
+
+		logger.Info("Verifying signature for artifact", logger.Args("digest", digestRef))
+		entry.Signer, err = signature.VerifySigner(ctx, digestRef, result.PlainHTTP, sig)
 		if err != nil {
-			return err
+			return fmt.Errorf("error while verifying signature for %s: %w", digestRef, err)
 		}
+		logger.Info("Signature successfully verified!")
+	}
 
-		sig := signatures[resolvedRef]
+	var destDir string
+	switch result.Type {
+	case oci.Plugin:
+		destDir = s.pluginsDir
+	case oci.Rulesfile:
+		destDir = s.rulesfilesDir
+	case oci.Asset:
+		destDir = s.assetsDir
+	default:
+		return fmt.Errorf("unrecognized result type %q while pulling artifact", result.Type)
+	}
 
-		if sig != nil && !o.noVerify {
-			repo, err := utils.RepositoryFromRef(resolvedRef)
-			if err != nil {
-				return err
-			}
+	if o.kubeDest != nil {
+		return o.installInCluster(ctx, tmpDir, resolvedRef, result, entry)
+	}
 
-			// In order to prevent TOCTOU issues we'll perform signature verification after we complete a pull
-			// and obtained a digest but before files are written to disk. This way we ensure that we're verifying
-			// the exact digest that we just pulled, even if the tag gets overwritten in the meantime.
-			digestRef := fmt.Sprintf("%s@%s", repo, result.RootDigest)
+	// Check if directory exists and is writable.
+	err = utils.ExistsAndIsWritable(destDir)
+	if err != nil {
+		return fmt.Errorf("cannot use directory %q as install destination: %w", destDir, err)
+	}
 
-			logger.Info("Verifying signature for artifact", logger.Args("digest", digestRef))
-			err = signature.Verify(ctx, digestRef, sig)
-			if err != nil {
-				return fmt.Errorf("error while verifying signature for %s: %w", digestRef, err)
-			}
-			logger.Info("Signature successfully verified!")
-		}
+	logger.Info("Extracting and installing artifact", logger.Args("type", result.Type, "file", result.Filename))
 
-		var destDir string
-		switch result.Type {
-		case oci.Plugin:
-			destDir = o.PluginsDir
-		case oci.Rulesfile:
-			destDir = o.RulesfilesDir
-		case oci.Asset:
-			destDir = o.AssetsDir
-		default:
-			return fmt.Errorf("unrecognized result type %q while pulling artifact", result.Type)
-		}
+	if !o.Printer.DisableStyling {
+		o.Printer.Spinner, _ = o.Printer.Spinner.Start("Extracting and installing")
+	}
+
+	result.Filename = filepath.Join(tmpDir, result.Filename)
+
+	f, err := os.Open(result.Filename)
+	if err != nil {
+		return err
+	}
+	if o.Versioned {
+		// Extract artifact in a new version directory and make it the active one.
+		ver := layout.Version{Ref: resolvedRef, Digest: result.RootDigest, Version: entry.Version}
+		entry.Files, err = layout.New(destDir, o.KeepVersions).Install(layout.Name(resolvedRef), ver, func(dir string) error {
+			_, err := utils.ExtractTarGz(ctx, f, dir, 0)
+			return err
+		})
+	} else {
+		// Extract artifact and move it to its destination directory
+		entry.Files, err = utils.ExtractTarGz(ctx, f, destDir, 0)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot extract %q to %q: %w", result.Filename, destDir, err)
+	}
+	entry.SetInstalled()
+
+	err = os.Remove(result.Filename)
+	if err != nil {
+		return err
+	}
+
+	if o.Printer.Spinner != nil {
+		_ = o.Printer.Spinner.Stop()
+	}
+	logger.Info("Artifact successfully installed", logger.Args("name", resolvedRef, "type", result.Type, "digest", result.Digest, "directory", destDir))
+
+	return nil
+}
+
+// checkVersion logs the lifecycle notices of the version being installed, as published by the indexes,
+// and refuses the version if required by the version policy.
+func (o *artifactInstallOptions) checkVersion(ctx context.Context, puller *ocipuller.Puller, ref string,
+	s artifactSettings, entry *audit.Entry) error {
+	logger := o.Printer.Logger
+
+	indexEntry, ok := o.IndexCache.EntryByReference(ref)
+	if !ok || !indexEntry.HasLifecycle() {
+		return nil
+	}
 
-		// Check if directory exists and is writable.
-		err = utils.ExistsAndIsWritable(destDir)
+	// The version is only known in advance when the dependencies have been resolved.
+	if entry.Version == "" {
+		artifactConfig, err := puller.ArtifactConfig(ctx, ref, s.platformOS, s.platformArch)
 		if err != nil {
-			return fmt.Errorf("cannot use directory %q as install destination: %w", destDir, err)
+			return err
 		}
+		entry.Version = artifactConfig.Version
+	}
 
-		logger.Info("Extracting and installing artifact", logger.Args("type", result.Type, "file", result.Filename))
+	notices, err := indexEntry.CheckVersion(entry.Version, o.versionPolicy, time.Now())
+	for _, n := range notices {
+		logger.Warn(n.Message, logger.Args("ref", ref, "kind", string(n.Kind)))
+	}
 
-		if !o.Printer.DisableStyling {
-			o.Printer.Spinner, _ = o.Printer.Spinner.Start("Extracting and installing")
-		}
+	return err
+}
 
-		result.Filename = filepath.Join(tmpDir, result.Filename)
+// installInCluster extracts the artifact and stores its files in the Kubernetes destination.
+func (o *artifactInstallOptions) installInCluster(ctx context.Context, tmpDir, ref string, result *oci.RegistryResult, entry *audit.Entry) error {
+	logger := o.Printer.Logger
+
+	if result.Type == oci.Plugin {
+		return fmt.Errorf("plugins cannot be installed in %s", o.kubeDest)
+	}
 
-		f, err := os.Open(result.Filename)
+	extractDir, err := os.MkdirTemp(tmpDir, "extract")
+	if err != nil {
+		return fmt.Errorf("cannot create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(extractDir)
+
+	logger.Info("Extracting and installing artifact", logger.Args("type", result.Type, "file", result.Filename, "destination", o.kubeDest))
+
+	archive := filepath.Join(tmpDir, result.Filename)
+	f, err := os.Open(archive)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	paths, err := utils.ExtractTarGz(ctx, f, extractDir, 0)
+	if err != nil {
+		return fmt.Errorf("cannot extract %q: %w", archive, err)
+	}
+
+	// Only regular files can be stored as keys, directories are flattened.
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
 		if err != nil {
 			return err
 		}
-		// Extract artifact and move it to its destination directory
-		_, err = utils.ExtractTarGz(ctx, f, destDir, 0)
-		if err != nil {
-			return fmt.Errorf("cannot extract %q to %q: %w", result.Filename, destDir, err)
+		if info.Mode().IsRegular() {
+			files = append(files, path)
 		}
+	}
+
+	if entry.Files, err = o.kubeDest.Write(ctx, ref, result.RootDigest, entry.Version, files); err != nil {
+		return err
+	}
+	entry.SetInstalled()
+
+	logger.Info("Artifact successfully installed", logger.Args("name", ref, "type", result.Type, "digest", result.Digest,
+		"destination", o.kubeDest))
 
-		err = os.Remove(result.Filename)
+	return nil
+}
+
+// loadOverrides records the settings and the signatures configured for the artifacts.
+func (o *artifactInstallOptions) loadOverrides(artifacts []config.ArtifactSpec, signatures map[string]*index.Signature) error {
+	o.overrides = make(map[string]artifactSettings, len(artifacts))
+	for _, a := range artifacts {
+		ref, err := o.IndexCache.ResolveReference(a.Ref)
 		if err != nil {
 			return err
 		}
 
-		if o.Printer.Spinner != nil {
-			_ = o.Printer.Spinner.Stop()
+		s := o.settings(ref)
+		s.rulesfilesDir = cmp.Or(a.RulesfilesDir, s.rulesfilesDir)
+		s.pluginsDir = cmp.Or(a.PluginsDir, s.pluginsDir)
+		s.assetsDir = cmp.Or(a.AssetsDir, s.assetsDir)
+		if a.Platform != "" {
+			if s.platformOS, s.platformArch, err = ParsePlatform(a.Platform); err != nil {
+				return fmt.Errorf("invalid platform for artifact %q: %w", a.Ref, err)
+			}
+		}
+		if a.NoVerify != nil {
+			s.noVerify = *a.NoVerify
+		}
+		o.overrides[ref] = s
+
+		if sig := index.SignatureFromConfig(a.Signature); sig != nil {
+			signatures[ref] = sig
 		}
-		logger.Info("Artifact successfully installed", logger.Args("name", resolvedRef, "type", result.Type, "digest", result.Digest, "directory", destDir))
 	}
 
 	return nil
 }
+
+// settings returns the settings to be used to install the artifact identified by ref.
+func (o *artifactInstallOptions) settings(ref string) artifactSettings {
+	if s, ok := o.overrides[ref]; ok {
+		return s
+	}
+
+	// The dependency resolution may have picked another tag of a configured artifact.
+	if repo, err := utils.RepositoryFromRef(ref); err == nil {
+		for r, s := range o.overrides {
+			if other, err := utils.RepositoryFromRef(r); err == nil && other == repo {
+				return s
+			}
+		}
+	}
+
+	return artifactSettings{
+		rulesfilesDir: o.RulesfilesDir,
+		pluginsDir:    o.PluginsDir,
+		assetsDir:     o.AssetsDir,
+		platformOS:    o.platformOS,
+		platformArch:  o.platformArch,
+		noVerify:      o.noVerify,
+	}
+}
+
+// ParseVersionPolicy validates the value of the version policy flag. An empty value means the default policy.
+func ParseVersionPolicy(policy string) (index.VersionPolicy, error) {
+	switch index.VersionPolicy(policy) {
+	case "", index.VersionPolicyWarn:
+		return index.VersionPolicyWarn, nil
+	case index.VersionPolicyRefuse:
+		return index.VersionPolicyRefuse, nil
+	default:
+		return "", fmt.Errorf("invalid %q: must be one of %s", FlagVersionPolicy, strings.Join(index.VersionPolicies, ", "))
+	}
+}
+
+// ParsePlatform splits a platform in the OS/Arch format. Both are empty if the platform is empty.
+func ParsePlatform(platform string) (platformOS, platformArch string, err error) {
+	if platform == "" {
+		return "", "", nil
+	}
+
+	parts := strings.Split(platform, "/")
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("%q must be in the format OS/Arch", platform)
+	}
+
+	return parts[0], parts[1], nil
+}
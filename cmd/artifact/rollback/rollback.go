@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rollback
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/diginfra/diginfractl/cmd/artifact/install"
+	"github.com/diginfra/diginfractl/internal/audit"
+	"github.com/diginfra/diginfractl/internal/config"
+	"github.com/diginfra/diginfractl/internal/layout"
+	"github.com/diginfra/diginfractl/pkg/options"
+)
+
+const (
+	longRollback = `Roll back an artifact installed with the versioned layout to its previous version.
+
+Artifacts installed by "artifact install" or "artifact follow" with the --versioned flag keep their
+last versions under the .versions directory of the install directory. This command switches the
+active version back to the one installed before the current one, atomically. The rolled back
+version is not installed again by "artifact follow" until a newer version is published.
+
+The name can be either the name of the artifact in the configured indexes, a reference or
+the last component of its repository, as long as a single installed artifact matches it.
+
+Example - Roll back "k8saudit-rules" to the previous version:
+	diginfractl artifact rollback k8saudit-rules
+`
+)
+
+type artifactRollbackOptions struct {
+	*options.Common
+	*options.Directory
+	auditLog string
+}
+
+// NewArtifactRollbackCmd returns the artifact rollback command.
+func NewArtifactRollbackCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := artifactRollbackOptions{
+		Common:    opt,
+		Directory: &options.Directory{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "rollback name [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Roll back an artifact to its previous version",
+		Long:                  longRollback,
+		Args:                  cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// Override "rulesfiles-dir" flag with viper config if not set by user.
+			f := cmd.Flags().Lookup(options.FlagRulesFilesDir)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagRulesFilesDir)
+			} else if !f.Changed && viper.IsSet(config.ArtifactInstallRulesfilesDirKey) {
+				val := viper.Get(config.ArtifactInstallRulesfilesDirKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagRulesFilesDir, err)
+				}
+			}
+
+			// Override "plugins-dir" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(options.FlagPluginsFilesDir)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagPluginsFilesDir)
+			} else if !f.Changed && viper.IsSet(config.ArtifactInstallPluginsDirKey) {
+				val := viper.Get(config.ArtifactInstallPluginsDirKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagPluginsFilesDir, err)
+				}
+			}
+
+			// Override "assets-dir" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(options.FlagAssetsFilesDir)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagAssetsFilesDir)
+			} else if !f.Changed && viper.IsSet(config.ArtifactInstallAssetsDirKey) {
+				val := viper.Get(config.ArtifactInstallAssetsDirKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagAssetsFilesDir, err)
+				}
+			}
+
+			// Override "audit-log" flag with viper config if not set by user.
+			f = cmd.Flags().Lookup(install.FlagAuditLog)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", install.FlagAuditLog)
+			} else if !f.Changed && viper.IsSet(config.ArtifactAuditLogKey) {
+				val := viper.Get(config.ArtifactAuditLogKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", install.FlagAuditLog, err)
+				}
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunArtifactRollback(ctx, args)
+		},
+	}
+
+	o.Directory.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.auditLog, install.FlagAuditLog, config.AuditLogFile,
+		"path of the JSON-lines file where the rollback is recorded. Disabled if empty")
+
+	return cmd
+}
+
+// RunArtifactRollback executes the business logic for the artifact rollback command.
+func (o *artifactRollbackOptions) RunArtifactRollback(_ context.Context, args []string) error {
+	logger := o.Printer.Logger
+
+	var auditLog *audit.Log
+	if o.auditLog != "" {
+		auditLog = audit.New(o.auditLog)
+	}
+
+	rolledBack := false
+	for _, dir := range o.dirs() {
+		v := layout.New(dir, 0)
+		names, err := o.names(v, args[0])
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			ok, err := v.Installed(name)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+
+			from, to, err := v.Rollback(name)
+			if err != nil {
+				return err
+			}
+			rolledBack = true
+
+			entry := &audit.Entry{
+				Operation: audit.OperationRollback,
+				Ref:       to.Ref,
+				OldDigest: from.Digest,
+				NewDigest: to.Digest,
+				Version:   to.Version,
+				Outcome:   audit.OutcomeUpdated,
+			}
+			for _, file := range to.Files {
+				entry.Files = append(entry.Files, filepath.Join(dir, file))
+			}
+			if err := auditLog.Record(entry); err != nil {
+				logger.Warn("Unable to record audit entry", logger.Args("reason", err.Error()))
+			}
+
+			logger.Info("Artifact rolled back", logger.Args("name", name, "directory", dir,
+				"from", from.Dir, "to", to.Dir))
+			// The other names designate the same artifact.
+			break
+		}
+	}
+
+	if !rolledBack {
+		return fmt.Errorf("%w for %q in %v", layout.ErrNotInstalled, args[0], o.dirs())
+	}
+
+	return nil
+}
+
+// names returns the names under which the artifact may be stored in v, by order of preference. The name
+// given by the user can be either a reference or the name of an artifact, in which case it is resolved
+// through the indexes, or else matched against the last component of the repositories installed in v.
+func (o *artifactRollbackOptions) names(v *layout.Versioned, name string) ([]string, error) {
+	names := []string{layout.Name(name)}
+	if o.IndexCache != nil {
+		if ref, err := o.IndexCache.ResolveReference(name); err == nil && !slices.Contains(names, layout.Name(ref)) {
+			names = append(names, layout.Name(ref))
+		}
+	}
+
+	if strings.Contains(name, "/") {
+		return names, nil
+	}
+	for _, n := range names {
+		if ok, err := v.Installed(n); err != nil || ok {
+			return names, err
+		}
+	}
+
+	// Otherwise the name is matched against the last component of the installed repositories.
+	installed, err := v.Names()
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, n := range installed {
+		if strings.HasSuffix(n, "+"+layout.Name(name)) && !slices.Contains(names, n) {
+			matches = append(matches, n)
+		}
+	}
+	if len(matches) > 1 {
+		return nil, fmt.Errorf("%q matches several artifacts in %q: %s, please use a reference instead",
+			name, v.Dir(), strings.Join(matches, ", "))
+	}
+	names = append(names, matches...)
+
+	return names, nil
+}
+
+// dirs returns the distinct install directories.
+func (o *artifactRollbackOptions) dirs() []string {
+	var dirs []string
+	for _, dir := range []string{o.RulesfilesDir, o.PluginsDir, o.AssetsDir} {
+		if dir != "" && !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
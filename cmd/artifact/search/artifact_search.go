@@ -21,6 +21,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/diginfra/diginfractl/pkg/index/index"
 	"github.com/diginfra/diginfractl/pkg/oci"
 	"github.com/diginfra/diginfractl/pkg/options"
 	"github.com/diginfra/diginfractl/pkg/output"
@@ -30,22 +31,52 @@ const (
 	defaultMinScore = 0.65
 	// CommandName name of the command. It has to be the first word in the use line.
 	CommandName = "search"
+
+	longSearch = `Search an artifact by keywords
+
+The keywords are matched against the names, the keywords, the descriptions and the maintainers of the
+artifacts provided by the configured indexes, and the results are sorted by relevance. Names are also
+matched approximately, see --min-score. The results can be restricted with filters, in which case the
+keywords are optional.
+
+Example - Search the artifacts related to Kubernetes audit logs:
+	diginfractl artifact search kubernetes audit
+
+Example - List the plugins of the "myorg" index released under the Apache-2.0 license:
+	diginfractl artifact search --type plugin --index myorg --license Apache-2.0
+`
 )
 
 type artifactSearchOptions struct {
 	*options.Common
 	minScore     float64
 	artifactType oci.ArtifactType
+	license      string
+	maintainer   string
+	index        string
 }
 
-func (o *artifactSearchOptions) Validate() error {
+func (o *artifactSearchOptions) Validate(args []string) error {
 	if o.minScore <= 0 || o.minScore > 1 {
 		return fmt.Errorf("minScore must be a number within (0,1]")
 	}
 
+	if len(args) == 0 && o.filter() == (index.SearchFilter{}) {
+		return fmt.Errorf("at least one keyword or filter is required")
+	}
+
 	return nil
 }
 
+func (o *artifactSearchOptions) filter() index.SearchFilter {
+	return index.SearchFilter{
+		Type:       o.artifactType.String(),
+		License:    o.license,
+		Maintainer: o.maintainer,
+		Index:      o.index,
+	}
+}
+
 // NewArtifactSearchCmd returns the artifact search command.
 func NewArtifactSearchCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 	o := artifactSearchOptions{
@@ -56,10 +87,9 @@ func NewArtifactSearchCmd(ctx context.Context, opt *options.Common) *cobra.Comma
 		Use:                   fmt.Sprintf("%s [keyword1 [keyword2 ...]] [flags]", CommandName),
 		DisableFlagsInUseLine: true,
 		Short:                 "Search an artifact by keywords",
-		Long:                  "Search an artifact by keywords",
-		Args:                  cobra.MinimumNArgs(1),
+		Long:                  longSearch,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
-			return o.Validate()
+			return o.Validate(args)
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return o.RunArtifactSearch(ctx, args)
@@ -70,20 +100,20 @@ func NewArtifactSearchCmd(ctx context.Context, opt *options.Common) *cobra.Comma
 		"the minimum score used to match artifact names with search keywords")
 
 	cmd.Flags().Var(&o.artifactType, "type", `Only search artifacts with a specific type. Allowed values: "rulesfile", "plugin", "asset"`)
+	cmd.Flags().StringVar(&o.license, "license", "", "Only search artifacts with a specific license, e.g. Apache-2.0")
+	cmd.Flags().StringVar(&o.maintainer, "maintainer", "", "Only search artifacts whose maintainers' name or email contain the given value")
+	cmd.Flags().StringVar(&o.index, "index", "", "Only search artifacts provided by a specific index, including the ones shadowed by other indexes")
 
 	return cmd
 }
 
 func (o *artifactSearchOptions) RunArtifactSearch(_ context.Context, args []string) error {
-	resultEntries := o.IndexCache.MergedIndexes.SearchByKeywords(o.minScore, args...)
+	results := o.IndexCache.MergedIndexes.Search(o.minScore, o.filter(), args...)
 
 	var data [][]string
-	for _, entry := range resultEntries {
-		if o.artifactType != "" && o.artifactType != oci.ArtifactType(entry.Type) {
-			continue
-		}
-		indexName := o.IndexCache.MergedIndexes.IndexByEntry(entry).Name
-		row := []string{indexName, entry.Name, entry.Type, entry.Registry, entry.Repository}
+	for _, result := range results {
+		entry := result.Entry
+		row := []string{result.Index.Name, entry.Name, entry.Type, entry.Registry, entry.Repository}
 		data = append(data, row)
 	}
 
@@ -27,12 +27,10 @@ import (
 	"golang.org/x/net/context"
 	"gopkg.in/yaml.v3"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/util/retry"
 
 	"github.com/diginfra/diginfractl/internal/config"
+	"github.com/diginfra/diginfractl/internal/kube"
 	"github.com/diginfra/diginfractl/internal/utils"
 	drivertype "github.com/diginfra/diginfractl/pkg/driver/type"
 	"github.com/diginfra/diginfractl/pkg/options"
@@ -159,21 +157,7 @@ func (o *driverConfigOptions) replaceDriverTypeInDiginfraConfig(driverType drive
 }
 
 func (o *driverConfigOptions) replaceDriverTypeInK8SConfigMap(ctx context.Context, driverType drivertype.DriverType) error {
-	var (
-		err error
-		cfg *rest.Config
-	)
-
-	if o.KubeConfig != "" {
-		cfg, err = clientcmd.BuildConfigFromFlags("", o.KubeConfig)
-	} else {
-		cfg, err = rest.InClusterConfig()
-	}
-	if err != nil {
-		return err
-	}
-
-	cl, err := kubernetes.NewForConfig(cfg)
+	cl, err := kube.NewClientset(o.KubeConfig)
 	if err != nil {
 		return err
 	}
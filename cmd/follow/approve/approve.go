@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package followapprove
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/diginfra/diginfractl/internal/follower/control"
+	"github.com/diginfra/diginfractl/pkg/options"
+)
+
+type followApproveOptions struct {
+	*options.Common
+	*options.Control
+}
+
+// NewFollowApproveCmd returns the follow approve command.
+func NewFollowApproveCmd(ctx context.Context, opt *options.Common, ctl *options.Control) *cobra.Command {
+	o := followApproveOptions{
+		Common:  opt,
+		Control: ctl,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "approve [ref] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Install the versions staged by the running followers",
+		Long:                  "Install the versions staged by the running followers. If no reference is given, all the followers with a staged version are affected",
+		Args:                  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunFollowApprove(ctx, args)
+		},
+	}
+
+	return cmd
+}
+
+// RunFollowApprove implements the follow approve command.
+func (o *followApproveOptions) RunFollowApprove(ctx context.Context, args []string) error {
+	logger := o.Printer.Logger
+	var ref string
+	if len(args) > 0 {
+		ref = args[0]
+	}
+
+	statuses, err := control.NewClient(o.Socket).Approve(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range statuses {
+		if st.StagedDigest == "" {
+			continue
+		}
+		logger.Info("Staged version approved", logger.Args("ref", st.Ref, "digest", st.StagedDigest))
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follow
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	followapprove "github.com/diginfra/diginfractl/cmd/follow/approve"
+	followpause "github.com/diginfra/diginfractl/cmd/follow/pause"
+	followreject "github.com/diginfra/diginfractl/cmd/follow/reject"
+	followresume "github.com/diginfra/diginfractl/cmd/follow/resume"
+	followstatus "github.com/diginfra/diginfractl/cmd/follow/status"
+	followsync "github.com/diginfra/diginfractl/cmd/follow/sync"
+	"github.com/diginfra/diginfractl/internal/config"
+	"github.com/diginfra/diginfractl/pkg/options"
+)
+
+const longFollow = `Interact with a running "artifact follow" process through its control API.
+
+The follower must be started with the --control-socket option, and the same
+socket path must be passed to these commands through the --socket option or
+the "artifact.follow.controlSocket" configuration key.
+`
+
+// NewFollowCmd returns the follow command.
+func NewFollowCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	ctl := &options.Control{}
+
+	cmd := &cobra.Command{
+		Use:                   "follow",
+		DisableFlagsInUseLine: true,
+		Short:                 "Interact with a running follower",
+		Long:                  longFollow,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			opt.Initialize()
+			if err := config.Load(opt.ConfigFile); err != nil {
+				return err
+			}
+
+			// Override "socket" flag with viper config if not set by user.
+			f := cmd.Flags().Lookup(options.FlagControlSocket)
+			if f == nil {
+				// should never happen
+				return fmt.Errorf("unable to retrieve flag %q", options.FlagControlSocket)
+			} else if !f.Changed && viper.IsSet(config.ArtifactFollowControlSocketKey) {
+				val := viper.Get(config.ArtifactFollowControlSocketKey)
+				if err := cmd.Flags().Set(f.Name, fmt.Sprintf("%v", val)); err != nil {
+					return fmt.Errorf("unable to overwrite %q flag: %w", options.FlagControlSocket, err)
+				}
+			}
+
+			return nil
+		},
+	}
+
+	ctl.AddFlags(cmd)
+
+	cmd.AddCommand(followstatus.NewFollowStatusCmd(ctx, opt, ctl))
+	cmd.AddCommand(followsync.NewFollowSyncCmd(ctx, opt, ctl))
+	cmd.AddCommand(followpause.NewFollowPauseCmd(ctx, opt, ctl))
+	cmd.AddCommand(followresume.NewFollowResumeCmd(ctx, opt, ctl))
+	cmd.AddCommand(followapprove.NewFollowApproveCmd(ctx, opt, ctl))
+	cmd.AddCommand(followreject.NewFollowRejectCmd(ctx, opt, ctl))
+
+	return cmd
+}
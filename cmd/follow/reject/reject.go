@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package followreject
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/diginfra/diginfractl/internal/follower/control"
+	"github.com/diginfra/diginfractl/pkg/options"
+)
+
+type followRejectOptions struct {
+	*options.Common
+	*options.Control
+}
+
+// NewFollowRejectCmd returns the follow reject command.
+func NewFollowRejectCmd(ctx context.Context, opt *options.Common, ctl *options.Control) *cobra.Command {
+	o := followRejectOptions{
+		Common:  opt,
+		Control: ctl,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "reject [ref] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Discard the versions staged by the running followers",
+		Long:                  "Discard the versions staged by the running followers. If no reference is given, all the followers with a staged version are affected",
+		Args:                  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunFollowReject(ctx, args)
+		},
+	}
+
+	return cmd
+}
+
+// RunFollowReject implements the follow reject command.
+func (o *followRejectOptions) RunFollowReject(ctx context.Context, args []string) error {
+	logger := o.Printer.Logger
+	var ref string
+	if len(args) > 0 {
+		ref = args[0]
+	}
+
+	statuses, err := control.NewClient(o.Socket).Reject(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range statuses {
+		if st.StagedDigest == "" {
+			continue
+		}
+		logger.Info("Staged version rejected", logger.Args("ref", st.Ref, "digest", st.StagedDigest))
+	}
+
+	return nil
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package followresume
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/diginfra/diginfractl/internal/follower/control"
+	"github.com/diginfra/diginfractl/pkg/options"
+)
+
+type followResumeOptions struct {
+	*options.Common
+	*options.Control
+}
+
+// NewFollowResumeCmd returns the follow resume command.
+func NewFollowResumeCmd(ctx context.Context, opt *options.Common, ctl *options.Control) *cobra.Command {
+	o := followResumeOptions{
+		Common:  opt,
+		Control: ctl,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "resume [ref] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Resume the updates of the running followers",
+		Long:                  "Resume the updates of the running followers. If no reference is given, all the followers are affected",
+		Args:                  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunFollowResume(ctx, args)
+		},
+	}
+
+	return cmd
+}
+
+// RunFollowResume implements the follow resume command.
+func (o *followResumeOptions) RunFollowResume(ctx context.Context, args []string) error {
+	logger := o.Printer.Logger
+	var ref string
+	if len(args) > 0 {
+		ref = args[0]
+	}
+
+	statuses, err := control.NewClient(o.Socket).Resume(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range statuses {
+		logger.Info("Follower resumed", logger.Args("ref", st.Ref))
+	}
+
+	return nil
+}
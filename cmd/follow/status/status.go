@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package followstatus
+
+import (
+	"context"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/diginfra/diginfractl/internal/follower/control"
+	"github.com/diginfra/diginfractl/pkg/options"
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+type followStatusOptions struct {
+	*options.Common
+	*options.Control
+}
+
+// NewFollowStatusCmd returns the follow status command.
+func NewFollowStatusCmd(ctx context.Context, opt *options.Common, ctl *options.Control) *cobra.Command {
+	o := followStatusOptions{
+		Common:  opt,
+		Control: ctl,
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "status [ref] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show the status of the running followers",
+		Long:                  "Show the reference, schedule, current and staged digests, last error and next run of the running followers",
+		Args:                  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunFollowStatus(ctx, args)
+		},
+	}
+
+	return cmd
+}
+
+// RunFollowStatus implements the follow status command.
+func (o *followStatusOptions) RunFollowStatus(ctx context.Context, args []string) error {
+	var ref string
+	if len(args) > 0 {
+		ref = args[0]
+	}
+
+	statuses, err := control.NewClient(o.Socket).Status(ctx, ref)
+	if err != nil {
+		return err
+	}
+
+	var data [][]string
+	for _, st := range statuses {
+		paused := "false"
+		if st.Paused {
+			paused = "true"
+		}
+		data = append(data, []string{st.Ref, st.Schedule, st.CurrentDigest, st.StagedDigest, paused,
+			formatTime(st.LastSync), formatTime(st.NextRun), st.LastError})
+	}
+
+	return o.Printer.PrintTable(output.FollowerStatus, data)
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
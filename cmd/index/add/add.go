@@ -18,6 +18,8 @@ package add
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -29,6 +31,24 @@ import (
 // IndexAddOptions contains the options for the index add command.
 type IndexAddOptions struct {
 	*options.Common
+	bearerTokenFile string
+	bearerTokenEnv  string
+	username        string
+	passwordFile    string
+	passwordEnv     string
+	headers         map[string]string
+	caFile          string
+	certFile        string
+	keyFile         string
+	s3Endpoint      string
+	s3Region        string
+	s3PathStyle     bool
+	refreshInterval time.Duration
+	priority        int
+	sigKey          string
+	sigIdentity     string
+	sigOidcIssuer   string
+	sigURL          string
 }
 
 // NewIndexAddCmd returns the index add command.
@@ -48,9 +68,87 @@ func NewIndexAddCmd(ctx context.Context, opt *options.Common) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().StringVar(&o.bearerTokenFile, "bearer-token-file", "", "file containing the bearer token sent to fetch the index")
+	cmd.Flags().StringVar(&o.bearerTokenEnv, "bearer-token-env", "", "environment variable containing the bearer token sent to fetch the index")
+	cmd.Flags().StringVar(&o.username, "username", "", "username of the basic credentials sent to fetch the index")
+	cmd.Flags().StringVar(&o.passwordFile, "password-file", "", "file containing the password of the basic credentials")
+	cmd.Flags().StringVar(&o.passwordEnv, "password-env", "", "environment variable containing the password of the basic credentials")
+	cmd.Flags().StringToStringVar(&o.headers, "header", nil, "additional header sent to fetch the index, in the name=value format. It can be repeated")
+	cmd.Flags().StringVar(&o.caFile, "ca-file", "", "PEM bundle of certificate authorities trusted to fetch the index, besides the system ones")
+	cmd.Flags().StringVar(&o.certFile, "cert-file", "", "PEM client certificate used for mutual TLS")
+	cmd.Flags().StringVar(&o.keyFile, "key-file", "", "PEM client key used for mutual TLS")
+	cmd.Flags().StringVar(&o.s3Endpoint, "s3-endpoint", "", "URL of the S3-compatible store serving the index, such as MinIO or Ceph. Defaults to AWS S3")
+	cmd.Flags().StringVar(&o.s3Region, "s3-region", "", "region of the S3 bucket serving the index. Defaults to the one of the AWS configuration")
+	cmd.Flags().BoolVar(&o.s3PathStyle, "s3-path-style", false, "address the S3 objects as ENDPOINT/BUCKET/KEY, as required by most S3-compatible stores")
+	cmd.Flags().DurationVar(&o.refreshInterval, "refresh-interval", 0,
+		"age after which the index is refreshed by the commands using it, e.g. 24h. It is never refreshed automatically if 0")
+	cmd.Flags().IntVar(&o.priority, "priority", 0,
+		"priority of the index when several indexes provide artifacts with the same name. The highest one wins")
+	cmd.Flags().StringVar(&o.sigKey, "signature-key", "", "public key trusted to sign the index, verified against its detached signature")
+	cmd.Flags().StringVar(&o.sigIdentity, "signature-identity", "", "certificate identity trusted to sign the index, for keyless signatures")
+	cmd.Flags().StringVar(&o.sigOidcIssuer, "signature-oidc-issuer", "", "certificate OIDC issuer trusted to sign the index, for keyless signatures")
+	cmd.Flags().StringVar(&o.sigURL, "signature-url", "", "URL of the detached signature of the index. Defaults to the index URL followed by \".sig\"")
+	cmd.MarkFlagsMutuallyExclusive("bearer-token-file", "bearer-token-env")
+	cmd.MarkFlagsMutuallyExclusive("password-file", "password-env")
+	cmd.MarkFlagsRequiredTogether("cert-file", "key-file")
+	cmd.MarkFlagsMutuallyExclusive("signature-key", "signature-identity")
+	cmd.MarkFlagsMutuallyExclusive("signature-key", "signature-oidc-issuer")
+	cmd.MarkFlagsRequiredTogether("signature-identity", "signature-oidc-issuer")
+
 	return cmd
 }
 
+// auth returns the credentials and the TLS settings given by the flags, or nil if none is given.
+// Secrets can only be read from files or environment variables, so that they do not end up in the shell history.
+func (o *IndexAddOptions) auth() *config.IndexAuth {
+	auth := &config.IndexAuth{
+		BearerToken: config.Secret{File: o.bearerTokenFile, Env: o.bearerTokenEnv},
+		Username:    o.username,
+		Password:    config.Secret{File: o.passwordFile, Env: o.passwordEnv},
+		CAFile:      o.caFile,
+		CertFile:    o.certFile,
+		KeyFile:     o.keyFile,
+	}
+	for name, val := range o.headers {
+		if auth.Headers == nil {
+			auth.Headers = make(map[string]config.Secret, len(o.headers))
+		}
+		auth.Headers[name] = config.Secret{Value: val}
+	}
+
+	if reflect.ValueOf(*auth).IsZero() {
+		return nil
+	}
+	return auth
+}
+
+// s3 returns the S3 settings given by the flags, or nil if none is given. Credentials are taken from
+// the standard AWS credential chain, or can be set in the configuration file.
+func (o *IndexAddOptions) s3() *config.IndexS3 {
+	if o.s3Endpoint == "" && o.s3Region == "" && !o.s3PathStyle {
+		return nil
+	}
+	return &config.IndexS3{
+		Endpoint:  o.s3Endpoint,
+		Region:    o.s3Region,
+		PathStyle: o.s3PathStyle,
+	}
+}
+
+// signature returns the trusted key or identity given by the flags, or nil if none is given.
+func (o *IndexAddOptions) signature() *config.Signature {
+	if o.sigKey == "" && o.sigIdentity == "" {
+		return nil
+	}
+	return &config.Signature{
+		Cosign: &config.CosignSignature{
+			KeyRef:                o.sigKey,
+			CertificateIdentity:   o.sigIdentity,
+			CertificateOidcIssuer: o.sigOidcIssuer,
+		},
+	}
+}
+
 // RunIndexAdd implements the index add command.
 func (o *IndexAddOptions) RunIndexAdd(ctx context.Context, args []string) error {
 	var err error
@@ -71,7 +169,18 @@ func (o *IndexAddOptions) RunIndexAdd(ctx context.Context, args []string) error
 
 	logger.Info("Adding index", logger.Args("name", name, "path", url))
 
-	if err = indexCache.Add(ctx, name, backend, url); err != nil {
+	idx := config.Index{
+		Name:            name,
+		URL:             url,
+		Backend:         backend,
+		Auth:            o.auth(),
+		S3:              o.s3(),
+		RefreshInterval: o.refreshInterval,
+		Priority:        o.priority,
+		Signature:       o.signature(),
+		SignatureURL:    o.sigURL,
+	}
+	if err = indexCache.Add(ctx, &idx); err != nil {
 		return fmt.Errorf("unable to add index: %w", err)
 	}
 
@@ -81,11 +190,7 @@ func (o *IndexAddOptions) RunIndexAdd(ctx context.Context, args []string) error
 	}
 
 	logger.Debug("Adding new index entry to configuration", logger.Args("file", o.ConfigFile))
-	if err = config.AddIndexes([]config.Index{{
-		Name:    name,
-		URL:     url,
-		Backend: backend,
-	}}, o.ConfigFile); err != nil {
+	if err = config.AddIndexes([]config.Index{idx}, o.ConfigFile); err != nil {
 		return fmt.Errorf("index entry %q: %w", name, err)
 	}
 
@@ -30,7 +30,25 @@ var indexAddUsage = `Usage:
 diginfractl index add [NAME] [URL] [BACKEND] [flags]
 
 Flags:
--h, --help   help for add
+    --bearer-token-env string        environment variable containing the bearer token sent to fetch the index
+      --bearer-token-file string       file containing the bearer token sent to fetch the index
+      --ca-file string                 PEM bundle of certificate authorities trusted to fetch the index, besides the system ones
+      --cert-file string               PEM client certificate used for mutual TLS
+      --header stringToString          additional header sent to fetch the index, in the name=value format. It can be repeated (default [])
+  -h, --help                           help for add
+      --key-file string                PEM client key used for mutual TLS
+      --password-env string            environment variable containing the password of the basic credentials
+      --password-file string           file containing the password of the basic credentials
+      --priority int                   priority of the index when several indexes provide artifacts with the same name. The highest one wins
+      --refresh-interval duration      age after which the index is refreshed by the commands using it, e.g. 24h. It is never refreshed automatically if 0
+      --s3-endpoint string             URL of the S3-compatible store serving the index, such as MinIO or Ceph. Defaults to AWS S3
+      --s3-path-style                  address the S3 objects as ENDPOINT/BUCKET/KEY, as required by most S3-compatible stores
+      --s3-region string               region of the S3 bucket serving the index. Defaults to the one of the AWS configuration
+      --signature-identity string      certificate identity trusted to sign the index, for keyless signatures
+      --signature-key string           public key trusted to sign the index, verified against its detached signature
+      --signature-oidc-issuer string   certificate OIDC issuer trusted to sign the index, for keyless signatures
+      --signature-url string           URL of the detached signature of the index. Defaults to the index URL followed by ".sig"
+      --username string                username of the basic credentials sent to fetch the index
 
 Global Flags:
       --config string       config file to be used for diginfractl (default "/etc/diginfractl/diginfractl.yaml")
@@ -45,7 +63,25 @@ Usage:
   diginfractl index add [NAME] [URL] [BACKEND] [flags]
 
 Flags:
-  -h, --help   help for add
+      --bearer-token-env string        environment variable containing the bearer token sent to fetch the index
+      --bearer-token-file string       file containing the bearer token sent to fetch the index
+      --ca-file string                 PEM bundle of certificate authorities trusted to fetch the index, besides the system ones
+      --cert-file string               PEM client certificate used for mutual TLS
+      --header stringToString          additional header sent to fetch the index, in the name=value format. It can be repeated (default [])
+  -h, --help                           help for add
+      --key-file string                PEM client key used for mutual TLS
+      --password-env string            environment variable containing the password of the basic credentials
+      --password-file string           file containing the password of the basic credentials
+      --priority int                   priority of the index when several indexes provide artifacts with the same name. The highest one wins
+      --refresh-interval duration      age after which the index is refreshed by the commands using it, e.g. 24h. It is never refreshed automatically if 0
+      --s3-endpoint string             URL of the S3-compatible store serving the index, such as MinIO or Ceph. Defaults to AWS S3
+      --s3-path-style                  address the S3 objects as ENDPOINT/BUCKET/KEY, as required by most S3-compatible stores
+      --s3-region string               region of the S3 bucket serving the index. Defaults to the one of the AWS configuration
+      --signature-identity string      certificate identity trusted to sign the index, for keyless signatures
+      --signature-key string           public key trusted to sign the index, verified against its detached signature
+      --signature-oidc-issuer string   certificate OIDC issuer trusted to sign the index, for keyless signatures
+      --signature-url string           URL of the detached signature of the index. Defaults to the index URL followed by ".sig"
+      --username string                username of the basic credentials sent to fetch the index
 
 Global Flags:
       --config string       config file to be used for diginfractl (default "/etc/diginfractl/diginfractl.yaml")
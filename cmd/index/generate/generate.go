@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/diginfra/diginfractl/pkg/index/generate"
+	"github.com/diginfra/diginfractl/pkg/index/index"
+	"github.com/diginfra/diginfractl/pkg/oci"
+	ociregistry "github.com/diginfra/diginfractl/pkg/oci/registry"
+	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
+	"github.com/diginfra/diginfractl/pkg/options"
+)
+
+const (
+	longGenerate = `Generate an index file from the artifacts stored in a registry
+
+The repositories are listed through the catalog API of the registry and filtered by prefix,
+or given explicitly with --repository. Each entry is built from the config layer and the
+OCI annotations of the artifact tagged with --tag:
+	org.opencontainers.image.description  ->  description
+	org.opencontainers.image.url          ->  home
+	org.opencontainers.image.licenses     ->  license
+	org.opencontainers.image.source       ->  sources
+	org.diginfra.artifact.keywords        ->  keywords (comma separated)
+
+If the output file already exists, the generated entries are merged into the entries with the
+same registry and repository: their type is updated, while the other fields are only filled when
+empty, so that hand-edited values and signatures are preserved. Generated entries whose name is
+already used by an entry of another repository are skipped with a warning.
+
+Example - Generate "index.yaml" from the repositories under "myorg/diginfra/":
+	diginfractl index generate --registry ghcr.io --prefix myorg/diginfra/
+
+Example - Generate an index from a list of repositories:
+	diginfractl index generate --registry ghcr.io --repository myorg/diginfra/rules --repository myorg/diginfra/plugin -o myorg.yaml
+`
+)
+
+type indexGenerateOptions struct {
+	*options.Common
+	*options.Registry
+	registry     string
+	prefix       string
+	repositories []string
+	tag          string
+	output       string
+}
+
+// NewIndexGenerateCmd returns the index generate command.
+func NewIndexGenerateCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := indexGenerateOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "generate [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Generate an index file from the artifacts stored in a registry",
+		Long:                  longGenerate,
+		Args:                  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunIndexGenerate(ctx)
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().StringVar(&o.registry, "registry", "", "registry storing the artifacts, e.g. ghcr.io")
+	cmd.Flags().StringVar(&o.prefix, "prefix", "", "prefix of the repositories listed through the catalog API, e.g. myorg/diginfra/")
+	cmd.Flags().StringArrayVar(&o.repositories, "repository", nil,
+		"repository to include instead of listing the catalog. Can be repeated multiple times")
+	cmd.Flags().StringVar(&o.tag, "tag", oci.DefaultTag, "tag of the artifacts read to build the entries")
+	cmd.Flags().StringVarP(&o.output, "output", "o", "index.yaml", "index file to write, merging the entries into it if it exists")
+	_ = cmd.MarkFlagRequired("registry")
+	cmd.MarkFlagsMutuallyExclusive("prefix", "repository")
+
+	return cmd
+}
+
+// RunIndexGenerate executes the business logic for the index generate command.
+func (o *indexGenerateOptions) RunIndexGenerate(ctx context.Context) error {
+	logger := o.Printer.Logger
+
+	puller, err := ociutils.Puller(o.PlainHTTP, o.Printer)
+	if err != nil {
+		return fmt.Errorf("an error occurred while creating the puller for registry %s: %w", o.registry, err)
+	}
+
+	if err = ociutils.CheckConnectionForRegistry(ctx, puller.Client, o.PlainHTTP, o.registry); err != nil {
+		return err
+	}
+
+	repositories := o.repositories
+	listed := len(repositories) == 0
+	if listed {
+		reg, err := ociregistry.NewRegistry(o.registry,
+			ociregistry.WithClient(puller.Client),
+			ociregistry.WithPlainHTTP(o.PlainHTTP))
+		if err != nil {
+			return err
+		}
+
+		logger.Info("Listing repositories", logger.Args("registry", o.registry, "prefix", o.prefix))
+		if repositories, err = reg.Repositories(ctx, o.prefix); err != nil {
+			return fmt.Errorf("unable to list the repositories of %s: %w", o.registry, err)
+		}
+	}
+
+	entries := make([]*index.Entry, 0, len(repositories))
+	for _, repo := range repositories {
+		repo = strings.Trim(repo, "/")
+		entry, err := generate.Entry(ctx, puller, o.registry, repo, o.tag)
+		if err != nil {
+			// The catalog may list repositories that are not diginfra artifacts, such as container images.
+			if listed {
+				logger.Warn("Skipping repository", logger.Args("repository", repo, "reason", err.Error()))
+				continue
+			}
+			return err
+		}
+		logger.Debug("Generated entry", logger.Args("name", entry.Name, "repository", repo))
+		entries = append(entries, entry)
+	}
+
+	idx := index.New("")
+	if err = idx.Read(o.output); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("unable to read index %q: %w", o.output, err)
+	} else if err == nil {
+		logger.Info("Merging into existing index", logger.Args("file", o.output))
+	}
+
+	clashes := generate.Merge(idx, entries)
+	for _, entry := range clashes {
+		existing, _ := idx.EntryByName(entry.Name)
+		logger.Warn("Skipping entry, its name is already used by another repository", logger.Args(
+			"name", entry.Name, "repository", entry.Repository,
+			"existing", existing.Registry+"/"+existing.Repository))
+	}
+
+	if err = idx.Write(o.output); err != nil {
+		return fmt.Errorf("unable to write index %q: %w", o.output, err)
+	}
+
+	logger.Info("Index generated", logger.Args("file", o.output, "entries", len(entries)-len(clashes)))
+
+	return nil
+}
@@ -21,7 +21,10 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/diginfra/diginfractl/cmd/index/add"
+	"github.com/diginfra/diginfractl/cmd/index/generate"
+	"github.com/diginfra/diginfractl/cmd/index/lint"
 	"github.com/diginfra/diginfractl/cmd/index/list"
+	"github.com/diginfra/diginfractl/cmd/index/push"
 	"github.com/diginfra/diginfractl/cmd/index/remove"
 	"github.com/diginfra/diginfractl/cmd/index/update"
 	"github.com/diginfra/diginfractl/internal/config"
@@ -45,6 +48,9 @@ func NewIndexCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Command
 	cmd.AddCommand(remove.NewIndexRemoveCmd(ctx, opt))
 	cmd.AddCommand(update.NewIndexUpdateCmd(ctx, opt))
 	cmd.AddCommand(list.NewIndexListCmd(ctx, opt))
+	cmd.AddCommand(push.NewIndexPushCmd(ctx, opt))
+	cmd.AddCommand(generate.NewIndexGenerateCmd(ctx, opt))
+	cmd.AddCommand(lint.NewIndexLintCmd(ctx, opt))
 
 	return cmd
 }
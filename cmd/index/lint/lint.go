@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/diginfra/diginfractl/pkg/index/config"
+	"github.com/diginfra/diginfractl/pkg/index/fetch"
+	"github.com/diginfra/diginfractl/pkg/index/lint"
+	"github.com/diginfra/diginfractl/pkg/oci/repository"
+	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
+	"github.com/diginfra/diginfractl/pkg/options"
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+const (
+	longLint = `Validate an index file
+
+The index is validated against the JSON schema of index files, then its entries are checked:
+names must be unique, registries and repositories must form valid references and cosign
+signatures must be consistent, e.g. they cannot set both certificate-identity and
+certificate-identity-regexp.
+
+The index can be a local file or the URL of a remote index, using any of the backends supported
+by "index add".
+
+Example - Lint a local index file:
+	diginfractl index lint index.yaml
+
+Example - Lint a remote index and check that the referenced repositories exist:
+	diginfractl index lint https://example.com/index.yaml --remote
+`
+)
+
+type indexLintOptions struct {
+	*options.Common
+	*options.Registry
+	remote bool
+}
+
+// NewIndexLintCmd returns the index lint command.
+func NewIndexLintCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := indexLintOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "lint file|url [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Validate an index file",
+		Long:                  longLint,
+		Args:                  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunIndexLint(ctx, args[0])
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.remote, "remote", false, "also check that every referenced repository exists in its registry")
+
+	return cmd
+}
+
+// RunIndexLint executes the business logic for the index lint command.
+func (o *indexLintOptions) RunIndexLint(ctx context.Context, source string) error {
+	logger := o.Printer.Logger
+
+	content, err := o.read(ctx, source)
+	if err != nil {
+		return err
+	}
+
+	issues, err := lint.Lint(content)
+	if err != nil {
+		return err
+	}
+
+	if o.remote {
+		client, err := ociutils.Client(true)
+		if err != nil {
+			return err
+		}
+		logger.Info("Checking the referenced repositories")
+		issues = append(issues, lint.CheckRepositories(ctx, content,
+			repository.WithClient(client), repository.WithPlainHTTP(o.PlainHTTP))...)
+	}
+
+	if len(issues) == 0 {
+		logger.Info("Index is valid", logger.Args("index", source))
+		return nil
+	}
+
+	data := make([][]string, 0, len(issues))
+	for _, issue := range issues {
+		data = append(data, []string{issue.Entry, issue.Message})
+	}
+	if err = o.Printer.PrintTable(output.IndexLint, data); err != nil {
+		return err
+	}
+
+	return fmt.Errorf("index %q has %d issue(s)", source, len(issues))
+}
+
+// read returns the content of a local index file, or of a remote index if source is a URL.
+func (o *indexLintOptions) read(ctx context.Context, source string) ([]byte, error) {
+	if !strings.Contains(source, "://") {
+		content, err := os.ReadFile(filepath.Clean(source))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read index file: %w", err)
+		}
+		return content, nil
+	}
+
+	return fetch.NewFetcher().FetchBytes(ctx, &config.Entry{Name: source, URL: source})
+}
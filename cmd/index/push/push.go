@@ -0,0 +1,117 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package push
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/diginfra/diginfractl/internal/utils"
+	"github.com/diginfra/diginfractl/pkg/index/index"
+	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
+	"github.com/diginfra/diginfractl/pkg/options"
+)
+
+const (
+	longPush = `Push an index file to a remote registry as an OCI artifact
+
+The pushed index can then be added by consumers with the oci backend, using the same
+registry credentials configured for the artifacts:
+	diginfractl index add myorg oci://registry.example.com/diginfra/index:latest
+
+Example - Push "index.yaml" with the "latest" tag:
+	diginfractl index push registry.example.com/diginfra/index:latest index.yaml
+
+Example - Push "index.yaml" with an additional tag:
+	diginfractl index push registry.example.com/diginfra/index:latest index.yaml --tag 2024-06-01
+
+Example - Push "index.yaml" to an insecure registry:
+	diginfractl index push --plain-http localhost:5000/index:latest index.yaml
+`
+)
+
+type indexPushOptions struct {
+	*options.Common
+	*options.Registry
+	tags []string
+}
+
+// NewIndexPushCmd returns the index push command.
+func NewIndexPushCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := indexPushOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "push hostname/repo[:tag|@digest] file [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Push an index file to a remote registry",
+		Long:                  longPush,
+		Args:                  cobra.ExactArgs(2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			_, err := utils.GetRegistryFromRef(args[0])
+			return err
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunIndexPush(ctx, args)
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().StringArrayVarP(&o.tags, "tag", "t", nil,
+		"additional tag for the pushed index. Can be repeated multiple times")
+
+	return cmd
+}
+
+// RunIndexPush executes the business logic for the index push command.
+func (o *indexPushOptions) RunIndexPush(ctx context.Context, args []string) error {
+	ref, path := args[0], args[1]
+	logger := o.Printer.Logger
+
+	// Refuse to publish something consumers would not be able to read.
+	if err := index.New("").Read(path); err != nil {
+		return fmt.Errorf("invalid index file %q: %w", path, err)
+	}
+
+	registry, err := utils.GetRegistryFromRef(ref)
+	if err != nil {
+		return err
+	}
+
+	pusher, err := ociutils.Pusher(o.PlainHTTP, o.Printer)
+	if err != nil {
+		return fmt.Errorf("an error occurred while creating the pusher for registry %s: %w", registry, err)
+	}
+
+	if err = ociutils.CheckConnectionForRegistry(ctx, pusher.Client, o.PlainHTTP, registry); err != nil {
+		return err
+	}
+
+	logger.Info("Preparing to push index", logger.Args("name", ref, "file", path))
+
+	res, err := pusher.PushIndex(ctx, ref, path, o.tags...)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Index pushed", logger.Args("name", ref, "digest", res.RootDigest))
+
+	return nil
+}
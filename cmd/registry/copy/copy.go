@@ -0,0 +1,173 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blang/semver/v4"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/diginfra/diginfractl/internal/utils"
+	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
+	"github.com/diginfra/diginfractl/pkg/options"
+)
+
+const (
+	longCopy = `Copy Diginfra OCI artifacts from a remote registry to another one.
+
+The artifacts are copied as they are: the image indexes, the manifests of all the platforms, the config
+and layer blobs keep their digests. Blobs already present in the destination are skipped, and mounted
+from the source repository when both are on the same registry.
+
+The source and destination references are passed as arguments. When the destination has no tag or digest,
+the one of the source is used (":latest" is assumed by default when neither has one).
+
+Example - Copy artifact "myplugin" to another registry:
+	diginfractl registry copy ghcr.io/myorg/myplugin:1.2.3 registry.example.com/mirror/myplugin
+
+Example - Copy artifact "myplugin" along with its cosign signatures and OCI referrers:
+	diginfractl registry copy ghcr.io/myorg/myplugin:1.2.3 registry.example.com/mirror/myplugin --with-signatures
+
+Example - Copy all the tags of artifact "myrulesfile":
+	diginfractl registry copy ghcr.io/myorg/myrulesfile registry.example.com/mirror/myrulesfile --all-tags
+
+Example - Copy the 1.x versions of artifact "myrulesfile":
+	diginfractl registry copy ghcr.io/myorg/myrulesfile registry.example.com/mirror/myrulesfile --semver-range ">=1.0.0 <2.0.0"
+`
+)
+
+type copyOptions struct {
+	*options.Common
+	*options.Registry
+	allTags        bool
+	semverRange    string
+	withSignatures bool
+	versions       semver.Range
+}
+
+func (o *copyOptions) validate(args []string) error {
+	for _, ref := range args {
+		if _, err := utils.GetRegistryFromRef(ref); err != nil {
+			return err
+		}
+	}
+
+	if o.semverRange != "" {
+		versions, err := semver.ParseRange(o.semverRange)
+		if err != nil {
+			return fmt.Errorf("invalid semver range %q: %w", o.semverRange, err)
+		}
+		o.versions = versions
+	}
+
+	if o.allTags || o.semverRange != "" {
+		for _, ref := range args {
+			parsed, err := registry.ParseReference(ref)
+			if err != nil {
+				return err
+			}
+			if parsed.Reference != "" {
+				return fmt.Errorf("reference %q must not have a tag or digest when copying with --all-tags or --semver-range", ref)
+			}
+		}
+	}
+
+	return nil
+}
+
+// NewCopyCmd returns the copy command.
+func NewCopyCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := copyOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "copy src-hostname/repo[:tag|@digest] dst-hostname/repo[:tag] [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Copy a Diginfra OCI artifact between remote registries",
+		Long:                  longCopy,
+		Args:                  cobra.ExactArgs(2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunCopy(ctx, args)
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.allTags, "all-tags", false, "copy all the tags of the source repository")
+	cmd.Flags().StringVar(&o.semverRange, "semver-range", "",
+		"copy the tags of the source repository whose versions are in the given range, e.g. \">=1.0.0 <2.0.0\"")
+	cmd.Flags().BoolVar(&o.withSignatures, "with-signatures", false,
+		"copy the cosign signatures and the OCI referrers of the artifacts as well")
+
+	return cmd
+}
+
+// RunCopy executes the business logic for the copy command.
+func (o *copyOptions) RunCopy(ctx context.Context, args []string) error {
+	logger := o.Printer.Logger
+	srcRef, dstRef := args[0], args[1]
+
+	copier, err := ociutils.Copier(o.PlainHTTP, o.Printer)
+	if err != nil {
+		return fmt.Errorf("an error occurred while creating the copier: %w", err)
+	}
+
+	for _, ref := range args {
+		reg, err := utils.GetRegistryFromRef(ref)
+		if err != nil {
+			return err
+		}
+		if err := ociutils.CheckConnectionForRegistry(ctx, copier.Client, o.PlainHTTP, reg); err != nil {
+			return err
+		}
+	}
+
+	refs := [][2]string{{srcRef, dstRef}}
+	if o.allTags || o.semverRange != "" {
+		tags, err := copier.Tags(ctx, srcRef, o.versions)
+		if err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			logger.Warn("No tags to copy", logger.Args("ref", srcRef))
+			return nil
+		}
+
+		refs = refs[:0]
+		for _, tag := range tags {
+			refs = append(refs, [2]string{srcRef + ":" + tag, dstRef + ":" + tag})
+		}
+	}
+
+	for _, r := range refs {
+		logger.Info("Copying artifact", logger.Args("source", r[0], "destination", r[1]))
+		res, err := copier.Copy(ctx, r[0], r[1], o.withSignatures)
+		if err != nil {
+			return err
+		}
+		logger.Info("Artifact copied", logger.Args("destination", res.Destination, "digest", res.Digest,
+			"signatures", len(res.Signatures)))
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copy_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/diginfra/diginfractl/cmd"
+	commonoptions "github.com/diginfra/diginfractl/pkg/options"
+	testutils "github.com/diginfra/diginfractl/pkg/test"
+)
+
+//nolint:unused // false positive
+const rulesfiletgz = "../../../pkg/test/data/rules.tar.gz"
+
+//nolint:unused // false positive
+var (
+	registry     string
+	ctx          = context.Background()
+	output       = gbytes.NewBuffer()
+	rootCmd      *cobra.Command
+	opt          *commonoptions.Common
+	orasRegistry *remote.Registry
+	configFile   string
+	err          error
+	args         []string
+)
+
+func TestCopy(t *testing.T) {
+	RegisterFailHandler(Fail)
+	registry = testutils.StartTestRegistry(t, nil)
+	RunSpecs(t, "Copy Suite")
+}
+
+var _ = BeforeSuite(func() {
+	// Create and configure the common options.
+	opt = commonoptions.NewOptions()
+	opt.Initialize(commonoptions.WithWriter(output))
+
+	// Create the oras registry.
+	orasRegistry, err = testutils.NewOrasRegistry(registry, true)
+	Expect(err).ToNot(HaveOccurred())
+
+	// Create temporary directory used to save the configuration file.
+	configFile, err = testutils.CreateEmptyFile("diginfractl.yaml")
+	Expect(err).Should(Succeed())
+})
+
+var _ = AfterSuite(func() {
+	configDir := filepath.Dir(configFile)
+	Expect(os.RemoveAll(configDir)).Should(Succeed())
+})
+
+//nolint:unused // false positive
+func executeRoot(args []string) error {
+	rootCmd.SetArgs(args)
+	rootCmd.SetOut(output)
+	return cmd.Execute(rootCmd, opt)
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copy_test
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/diginfra/diginfractl/cmd"
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/authn"
+	ocipusher "github.com/diginfra/diginfractl/pkg/oci/pusher"
+)
+
+//nolint:unused // false positive
+var copyAssertFailedBehavior = func(specificError string) {
+	It("check that fails and the usage is not printed", func() {
+		Expect(err).To(HaveOccurred())
+		Expect(output).ShouldNot(gbytes.Say(regexp.QuoteMeta("Usage:")))
+		Expect(output).Should(gbytes.Say(regexp.QuoteMeta(specificError)))
+	})
+}
+
+//nolint:unused // false positive
+var registryCopyTests = Describe("copy", func() {
+	const (
+		// Used as flags for all the test cases.
+		registryCmd = "registry"
+		copyCmd     = "copy"
+	)
+
+	// Each test gets its own root command and runs it.
+	// The err variable is asserted by each test.
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	Context("failure", func() {
+		When("without destination", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, copyCmd, registry + "/src/rules:1.0.0", "--config", configFile}
+			})
+			copyAssertFailedBehavior("ERROR accepts 2 arg(s), received 1")
+		})
+
+		When("invalid semver range", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, copyCmd, registry + "/src/rules", registry + "/dst/rules",
+					"--semver-range", "latest", "--config", configFile}
+			})
+			copyAssertFailedBehavior(`ERROR invalid semver range "latest"`)
+		})
+
+		When("tag with --all-tags", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, copyCmd, registry + "/src/rules:1.0.0", registry + "/dst/rules",
+					"--all-tags", "--config", configFile}
+			})
+			copyAssertFailedBehavior("must not have a tag or digest when copying with --all-tags or --semver-range")
+		})
+
+		When("missing artifact", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, copyCmd, registry + "/missing/rules:1.0.0", registry + "/dst/rules",
+					"--plain-http", "--config", configFile}
+			})
+			copyAssertFailedBehavior("ERROR unable to copy " + registry + "/missing/rules:1.0.0")
+		})
+	})
+
+	Context("success", func() {
+		var digest string
+
+		BeforeEach(func() {
+			pusher := ocipusher.NewPusher(authn.NewClient(authn.WithCredentials(&auth.EmptyCredential)), true, nil)
+			result, err := pusher.Push(ctx, oci.Rulesfile, registry+"/src/rules:1.0.0",
+				ocipusher.WithFilepaths([]string{rulesfiletgz}),
+				ocipusher.WithTags("1.1.0", "2.0.0"),
+				ocipusher.WithArtifactConfig(oci.ArtifactConfig{Name: "rules", Version: "1.0.0"}))
+			Expect(err).ToNot(HaveOccurred())
+			digest = result.RootDigest
+		})
+
+		When("a single artifact", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, copyCmd, registry + "/src/rules:1.0.0", registry + "/single/rules",
+					"--plain-http", "--config", configFile}
+			})
+
+			It("keeps the tag and the digest", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(output).Should(gbytes.Say("Artifact copied"))
+				repo, err := orasRegistry.Repository(ctx, "single/rules")
+				Expect(err).ToNot(HaveOccurred())
+				desc, err := repo.Resolve(ctx, "1.0.0")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(desc.Digest.String()).To(Equal(digest))
+			})
+		})
+
+		When("a semver range", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, copyCmd, registry + "/src/rules", registry + "/ranged/rules",
+					"--semver-range", ">=1.0.0 <2.0.0", "--plain-http", "--config", configFile}
+			})
+
+			It("copies the tags in the range only", func() {
+				Expect(err).ToNot(HaveOccurred())
+				repo, err := orasRegistry.Repository(ctx, "ranged/rules")
+				Expect(err).ToNot(HaveOccurred())
+				var tags []string
+				Expect(repo.Tags(ctx, "", func(t []string) error {
+					tags = append(tags, t...)
+					return nil
+				})).To(Succeed())
+				Expect(tags).To(ConsistOf("1.0.0", "1.1.0"))
+			})
+		})
+	})
+})
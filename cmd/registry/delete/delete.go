@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package delete
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/diginfra/diginfractl/internal/utils"
+	"github.com/diginfra/diginfractl/pkg/oci/repository"
+	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
+	"github.com/diginfra/diginfractl/pkg/options"
+)
+
+const (
+	longDelete = `Delete Diginfra OCI artifacts from remote registry.
+
+The artifact is referenced by digest, and is deleted along with all the tags pointing to it. Artifacts
+still referenced by a tagged image index of the repository, such as the manifest of one of the platforms
+of a plugin, are not deleted. Registries that do not support deletions, or where they are disabled,
+refuse the operation.
+
+Example - Delete an artifact:
+	diginfractl registry delete localhost:5000/myplugin@sha256:d7b83de5dbe7b5d284de0980cea3268fe74cb20d3faf14b5c5e34819ac6c60ed
+
+Example - Show what would be deleted, without deleting anything:
+	diginfractl registry delete localhost:5000/myplugin@sha256:d7b83de5dbe7b5d284de0980cea3268fe74cb20d3faf14b5c5e34819ac6c60ed --dry-run
+`
+)
+
+type deleteOptions struct {
+	*options.Common
+	*options.Registry
+	dryRun bool
+}
+
+func (o *deleteOptions) validate(args []string) error {
+	if _, err := utils.GetRegistryFromRef(args[0]); err != nil {
+		return err
+	}
+
+	ref, err := registry.ParseReference(args[0])
+	if err != nil {
+		return err
+	}
+	if err := ref.ValidateReferenceAsDigest(); err != nil {
+		return fmt.Errorf("reference %q must be a digest: %w", args[0], err)
+	}
+
+	return nil
+}
+
+// NewDeleteCmd returns the delete command.
+func NewDeleteCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := deleteOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "delete hostname/repo@digest [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Delete a Diginfra OCI artifact from remote registry",
+		Long:                  longDelete,
+		Args:                  cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunDelete(ctx, args)
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "show the artifact that would be deleted without deleting it")
+
+	return cmd
+}
+
+// RunDelete executes the business logic for the delete command.
+func (o *deleteOptions) RunDelete(ctx context.Context, args []string) error {
+	logger := o.Printer.Logger
+	ref := args[0]
+
+	repo, err := ociutils.Repository(ref, o.PlainHTTP)
+	if err != nil {
+		return err
+	}
+
+	if err := ociutils.CheckConnectionForRegistry(ctx, repo.Client, o.PlainHTTP, repo.Reference.Registry); err != nil {
+		return err
+	}
+
+	dgst, err := repo.Reference.Digest()
+	if err != nil {
+		return err
+	}
+
+	if o.dryRun {
+		if _, err := repo.Resolve(ctx, dgst.String()); err != nil {
+			return fmt.Errorf("unable to resolve %s: %w", ref, err)
+		}
+		indexes, err := repo.IndexesReferencing(ctx, dgst)
+		if err != nil {
+			return err
+		}
+		if len(indexes) > 0 {
+			return fmt.Errorf("unable to delete %s: %w %s", ref, repository.ErrReferenced, strings.Join(indexes, ", "))
+		}
+		logger.Info("Dry run: artifact not deleted", logger.Args("ref", ref))
+		return nil
+	}
+
+	if err := repo.DeleteManifest(ctx, dgst); errors.Is(err, repository.ErrDeleteUnsupported) {
+		return fmt.Errorf("unable to delete %s: %w, deletions may have to be enabled in its configuration", ref, repository.ErrDeleteUnsupported)
+	} else if err != nil {
+		return fmt.Errorf("unable to delete %s: %w", ref, err)
+	}
+	logger.Info("Artifact deleted", logger.Args("ref", ref))
+
+	return nil
+}
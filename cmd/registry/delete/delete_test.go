@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package delete_test
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/diginfra/diginfractl/cmd"
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/authn"
+	ocipusher "github.com/diginfra/diginfractl/pkg/oci/pusher"
+)
+
+//nolint:unused // false positive
+var deleteAssertFailedBehavior = func(specificError string) {
+	It("check that fails and the usage is not printed", func() {
+		Expect(err).To(HaveOccurred())
+		Expect(output).ShouldNot(gbytes.Say(regexp.QuoteMeta("Usage:")))
+		Expect(output).Should(gbytes.Say(regexp.QuoteMeta(specificError)))
+	})
+}
+
+//nolint:unused // false positive
+var registryDeleteTests = Describe("delete", func() {
+	const (
+		// Used as flags for all the test cases.
+		registryCmd = "registry"
+		deleteCmd   = "delete"
+	)
+
+	var ref string
+
+	BeforeEach(func() {
+		pusher := ocipusher.NewPusher(authn.NewClient(authn.WithCredentials(&auth.EmptyCredential)), true, nil)
+		result, err := pusher.Push(ctx, oci.Rulesfile, registry+"/delete/rules:1.0.0",
+			ocipusher.WithFilepaths([]string{rulesfiletgz}),
+			ocipusher.WithArtifactConfig(oci.ArtifactConfig{Name: "rules", Version: "1.0.0"}))
+		Expect(err).ToNot(HaveOccurred())
+		ref = registry + "/delete/rules@" + result.RootDigest
+	})
+
+	// Each test gets its own root command and runs it.
+	// The err variable is asserted by each test.
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	Context("failure", func() {
+		When("reference by tag", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, deleteCmd, registry + "/delete/rules:1.0.0", "--config", configFile}
+			})
+			deleteAssertFailedBehavior(`ERROR reference "` + registry + `/delete/rules:1.0.0" must be a digest`)
+		})
+
+		When("deletions are disabled", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, deleteCmd, ref, "--plain-http", "--config", configFile}
+			})
+			deleteAssertFailedBehavior("the registry does not support deletions, deletions may have to be enabled in its configuration")
+
+			It("leaves the artifact in place", func() {
+				repo, err := orasRegistry.Repository(ctx, "delete/rules")
+				Expect(err).ToNot(HaveOccurred())
+				_, err = repo.Resolve(ctx, "1.0.0")
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+	})
+
+	Context("success", func() {
+		When("dry run", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, deleteCmd, ref, "--dry-run", "--plain-http", "--config", configFile}
+			})
+
+			It("reports the artifact without deleting it", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(output).Should(gbytes.Say("Dry run: artifact not deleted"))
+				repo, err := orasRegistry.Repository(ctx, "delete/rules")
+				Expect(err).ToNot(HaveOccurred())
+				_, err = repo.Resolve(ctx, "1.0.0")
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+	})
+})
@@ -115,9 +115,12 @@ func (o *pullOptions) RunPull(ctx context.Context, args []string) error {
 		return fmt.Errorf("an error occurred while creating the puller for registry %s: %w", registry, err)
 	}
 
-	err = ociutils.CheckConnectionForRegistry(ctx, puller.Client, o.PlainHTTP, registry)
-	if err != nil {
-		return err
+	// When the artifact is mirrored, its registry may not be reachable: the puller reports the errors
+	// of each of its locations instead.
+	if candidates := puller.Mirrors.Candidates(ref, o.PlainHTTP); !candidates[0].Mirror {
+		if err = ociutils.CheckConnectionForRegistry(ctx, puller.Client, o.PlainHTTP, registry); err != nil {
+			return err
+		}
 	}
 
 	logger.Info("Preparing to pull artifact", logger.Args("name", args[0]))
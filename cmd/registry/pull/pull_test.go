@@ -324,4 +324,36 @@ var registryPullTests = Describe("pull", func() {
 
 	})
 
+	Context("mirror", func() {
+		const upstream = "localhost:1"
+
+		var destDir string
+
+		When("upstream is unreachable", func() {
+			BeforeEach(func() {
+				pusher := ocipusher.NewPusher(authn.NewClient(authn.WithCredentials(&auth.EmptyCredential)), true, nil)
+				_, err := pusher.Push(ctx, oci.Rulesfile, registry+"/mirror/rules:1.0.0",
+					ocipusher.WithFilepaths([]string{rulesfiletgz}),
+					ocipusher.WithArtifactConfig(oci.ArtifactConfig{Name: "rules", Version: "1.0.0"}))
+				Expect(err).ToNot(HaveOccurred())
+
+				configFile := filepath.Join(GinkgoT().TempDir(), "diginfractl.yaml")
+				Expect(os.WriteFile(configFile, []byte(fmt.Sprintf(`registry:
+  mirrors:
+    - prefix: %s
+      endpoints:
+        - location: %s
+          plainHTTP: true
+`, upstream, registry)), 0o600)).To(Succeed())
+				destDir = GinkgoT().TempDir()
+				args = []string{registryCmd, pullCmd, upstream + "/mirror/rules:1.0.0", "--dest-dir", destDir, "--config", configFile}
+			})
+
+			It("pulls the artifact from the mirror", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(output).Should(gbytes.Say("Artifact pulled"))
+				Expect(filepath.Join(destDir, filepath.Base(rulesfiletgz))).To(BeAnExistingFile())
+			})
+		})
+	})
 })
@@ -21,8 +21,12 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/diginfra/diginfractl/cmd/registry/auth"
+	"github.com/diginfra/diginfractl/cmd/registry/copy"
+	"github.com/diginfra/diginfractl/cmd/registry/delete"
 	"github.com/diginfra/diginfractl/cmd/registry/pull"
 	"github.com/diginfra/diginfractl/cmd/registry/push"
+	"github.com/diginfra/diginfractl/cmd/registry/tag"
+	"github.com/diginfra/diginfractl/cmd/registry/untag"
 	"github.com/diginfra/diginfractl/internal/config"
 	commonoptions "github.com/diginfra/diginfractl/pkg/options"
 )
@@ -45,6 +49,10 @@ func NewRegistryCmd(ctx context.Context, opt *commonoptions.Common) *cobra.Comma
 	cmd.AddCommand(auth.NewAuthCmd(ctx, opt))
 	cmd.AddCommand(push.NewPushCmd(ctx, opt))
 	cmd.AddCommand(pull.NewPullCmd(ctx, opt))
+	cmd.AddCommand(copy.NewCopyCmd(ctx, opt))
+	cmd.AddCommand(tag.NewTagCmd(ctx, opt))
+	cmd.AddCommand(untag.NewUntagCmd(ctx, opt))
+	cmd.AddCommand(delete.NewDeleteCmd(ctx, opt))
 
 	return cmd
 }
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tag
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/diginfra/diginfractl/internal/utils"
+	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
+	"github.com/diginfra/diginfractl/pkg/options"
+)
+
+const (
+	longTag = `Tag Diginfra OCI artifacts in remote registry.
+
+The artifact is referenced by the first argument, with a tag or a digest (":latest" is assumed by default
+when neither is given). The following arguments are the new tags, which are created or moved to point to
+the artifact.
+
+Example - Move the floating tag "0.7" back to version "0.7.0" of artifact "myplugin":
+	diginfractl registry tag localhost:5000/myplugin:0.7.0 0.7
+
+Example - Tag an artifact by digest:
+	diginfractl registry tag localhost:5000/myplugin@sha256:d7b83de5dbe7b5d284de0980cea3268fe74cb20d3faf14b5c5e34819ac6c60ed 0.7 latest
+
+Example - Show what would be tagged, without changing anything:
+	diginfractl registry tag localhost:5000/myplugin:0.7.0 0.7 --dry-run
+`
+)
+
+type tagOptions struct {
+	*options.Common
+	*options.Registry
+	dryRun bool
+}
+
+func (o *tagOptions) validate(args []string) error {
+	if _, err := utils.GetRegistryFromRef(args[0]); err != nil {
+		return err
+	}
+
+	ref, err := registry.ParseReference(args[0])
+	if err != nil {
+		return err
+	}
+	for _, tag := range args[1:] {
+		ref.Reference = tag
+		if err := ref.ValidateReferenceAsTag(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewTagCmd returns the tag command.
+func NewTagCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := tagOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "tag hostname/repo[:tag|@digest] newtag... [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Tag a Diginfra OCI artifact in remote registry",
+		Long:                  longTag,
+		Args:                  cobra.MinimumNArgs(2),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunTag(ctx, args)
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "show the tags that would be changed without changing them")
+
+	return cmd
+}
+
+// RunTag executes the business logic for the tag command.
+func (o *tagOptions) RunTag(ctx context.Context, args []string) error {
+	logger := o.Printer.Logger
+	ref, tags := args[0], args[1:]
+
+	repo, err := ociutils.Repository(ref, o.PlainHTTP)
+	if err != nil {
+		return err
+	}
+
+	if err := ociutils.CheckConnectionForRegistry(ctx, repo.Client, o.PlainHTTP, repo.Reference.Registry); err != nil {
+		return err
+	}
+
+	reference := repo.Reference.ReferenceOrDefault()
+	if o.dryRun {
+		desc, err := repo.Resolve(ctx, reference)
+		if err != nil {
+			return fmt.Errorf("unable to resolve %s: %w", ref, err)
+		}
+		logger.Info("Dry run: artifact not tagged", logger.Args("ref", ref, "digest", desc.Digest.String(), "tags", tags))
+		return nil
+	}
+
+	desc, err := repo.Retag(ctx, reference, tags...)
+	if err != nil {
+		return fmt.Errorf("unable to tag %s: %w", ref, err)
+	}
+	logger.Info("Artifact tagged", logger.Args("ref", ref, "digest", desc.Digest.String(), "tags", tags))
+
+	return nil
+}
@@ -0,0 +1,121 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tag_test
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/diginfra/diginfractl/cmd"
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/authn"
+	ocipusher "github.com/diginfra/diginfractl/pkg/oci/pusher"
+)
+
+//nolint:unused // false positive
+var tagAssertFailedBehavior = func(specificError string) {
+	It("check that fails and the usage is not printed", func() {
+		Expect(err).To(HaveOccurred())
+		Expect(output).ShouldNot(gbytes.Say(regexp.QuoteMeta("Usage:")))
+		Expect(output).Should(gbytes.Say(regexp.QuoteMeta(specificError)))
+	})
+}
+
+//nolint:unused // false positive
+var registryTagTests = Describe("tag", func() {
+	const (
+		// Used as flags for all the test cases.
+		registryCmd = "registry"
+		tagCmd      = "tag"
+	)
+
+	var digest string
+
+	BeforeEach(func() {
+		pusher := ocipusher.NewPusher(authn.NewClient(authn.WithCredentials(&auth.EmptyCredential)), true, nil)
+		result, err := pusher.Push(ctx, oci.Rulesfile, registry+"/tag/rules:1.0.0",
+			ocipusher.WithFilepaths([]string{rulesfiletgz}),
+			ocipusher.WithArtifactConfig(oci.ArtifactConfig{Name: "rules", Version: "1.0.0"}))
+		Expect(err).ToNot(HaveOccurred())
+		digest = result.RootDigest
+	})
+
+	// Each test gets its own root command and runs it.
+	// The err variable is asserted by each test.
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	Context("failure", func() {
+		When("invalid new tag", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, tagCmd, registry + "/tag/rules:1.0.0", "1.0", "@invalid", "--config", configFile}
+			})
+			tagAssertFailedBehavior("ERROR invalid reference: invalid tag")
+		})
+
+		When("unknown reference", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, tagCmd, registry + "/tag/rules:2.0.0", "2.0", "--plain-http", "--config", configFile}
+			})
+			tagAssertFailedBehavior("ERROR unable to tag " + registry + "/tag/rules:2.0.0")
+		})
+	})
+
+	Context("success", func() {
+		When("dry run", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, tagCmd, registry + "/tag/rules:1.0.0", "dry", "--dry-run", "--plain-http", "--config", configFile}
+			})
+
+			It("reports the artifact without tagging it", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(output).Should(gbytes.Say("Dry run: artifact not tagged"))
+				repo, err := orasRegistry.Repository(ctx, "tag/rules")
+				Expect(err).ToNot(HaveOccurred())
+				_, err = repo.Resolve(ctx, "dry")
+				Expect(err).To(HaveOccurred())
+			})
+		})
+
+		When("new tags", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, tagCmd, registry + "/tag/rules:1.0.0", "1.0", "latest", "--plain-http", "--config", configFile}
+			})
+
+			It("points the new tags to the artifact", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(output).Should(gbytes.Say("Artifact tagged"))
+				repo, err := orasRegistry.Repository(ctx, "tag/rules")
+				Expect(err).ToNot(HaveOccurred())
+				for _, tag := range []string{"1.0", "latest"} {
+					desc, err := repo.Resolve(ctx, tag)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(desc.Digest.String()).To(Equal(digest))
+				}
+			})
+		})
+	})
+})
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package untag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/diginfra/diginfractl/internal/utils"
+	"github.com/diginfra/diginfractl/pkg/oci/repository"
+	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
+	"github.com/diginfra/diginfractl/pkg/options"
+)
+
+const (
+	longUntag = `Remove a tag of Diginfra OCI artifacts from remote registry.
+
+The tag is removed from the repository, while the artifact it points to is left in place and is still
+reachable through its digest and its other tags. Registries that do not support deleting tags refuse the
+operation.
+
+Example - Remove tag "0.7.1" of artifact "myplugin":
+	diginfractl registry untag localhost:5000/myplugin:0.7.1
+
+Example - Show what would be untagged, without changing anything:
+	diginfractl registry untag localhost:5000/myplugin:0.7.1 --dry-run
+`
+)
+
+type untagOptions struct {
+	*options.Common
+	*options.Registry
+	dryRun bool
+}
+
+func (o *untagOptions) validate(args []string) error {
+	if _, err := utils.GetRegistryFromRef(args[0]); err != nil {
+		return err
+	}
+
+	ref, err := registry.ParseReference(args[0])
+	if err != nil {
+		return err
+	}
+	if ref.Reference == "" {
+		return fmt.Errorf("reference %q has no tag", args[0])
+	}
+
+	return ref.ValidateReferenceAsTag()
+}
+
+// NewUntagCmd returns the untag command.
+func NewUntagCmd(ctx context.Context, opt *options.Common) *cobra.Command {
+	o := untagOptions{
+		Common:   opt,
+		Registry: &options.Registry{},
+	}
+
+	cmd := &cobra.Command{
+		Use:                   "untag hostname/repo:tag [flags]",
+		DisableFlagsInUseLine: true,
+		Short:                 "Remove a tag of a Diginfra OCI artifact from remote registry",
+		Long:                  longUntag,
+		Args:                  cobra.ExactArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			return o.validate(args)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return o.RunUntag(ctx, args)
+		},
+	}
+
+	o.Registry.AddFlags(cmd)
+	cmd.Flags().BoolVar(&o.dryRun, "dry-run", false, "show the tag that would be removed without removing it")
+
+	return cmd
+}
+
+// RunUntag executes the business logic for the untag command.
+func (o *untagOptions) RunUntag(ctx context.Context, args []string) error {
+	logger := o.Printer.Logger
+	ref := args[0]
+
+	repo, err := ociutils.Repository(ref, o.PlainHTTP)
+	if err != nil {
+		return err
+	}
+
+	if err := ociutils.CheckConnectionForRegistry(ctx, repo.Client, o.PlainHTTP, repo.Reference.Registry); err != nil {
+		return err
+	}
+
+	tag := repo.Reference.Reference
+	desc, err := repo.Resolve(ctx, tag)
+	if err != nil {
+		return fmt.Errorf("unable to resolve %s: %w", ref, err)
+	}
+
+	if o.dryRun {
+		logger.Info("Dry run: tag not removed", logger.Args("ref", ref, "digest", desc.Digest.String()))
+		return nil
+	}
+
+	if err := repo.Untag(ctx, tag); errors.Is(err, repository.ErrDeleteUnsupported) {
+		return fmt.Errorf("unable to untag %s: %w, deleting tags may have to be enabled in its configuration", ref, repository.ErrDeleteUnsupported)
+	} else if err != nil {
+		return fmt.Errorf("unable to untag %s: %w", ref, err)
+	}
+	logger.Info("Tag removed", logger.Args("ref", ref, "digest", desc.Digest.String()))
+
+	return nil
+}
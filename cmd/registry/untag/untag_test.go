@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package untag_test
+
+import (
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/diginfra/diginfractl/cmd"
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/authn"
+	ocipusher "github.com/diginfra/diginfractl/pkg/oci/pusher"
+)
+
+//nolint:unused // false positive
+var untagAssertFailedBehavior = func(specificError string) {
+	It("check that fails and the usage is not printed", func() {
+		Expect(err).To(HaveOccurred())
+		Expect(output).ShouldNot(gbytes.Say(regexp.QuoteMeta("Usage:")))
+		Expect(output).Should(gbytes.Say(regexp.QuoteMeta(specificError)))
+	})
+}
+
+//nolint:unused // false positive
+var registryUntagTests = Describe("untag", func() {
+	const (
+		// Used as flags for all the test cases.
+		registryCmd = "registry"
+		untagCmd    = "untag"
+	)
+
+	var digest string
+
+	BeforeEach(func() {
+		pusher := ocipusher.NewPusher(authn.NewClient(authn.WithCredentials(&auth.EmptyCredential)), true, nil)
+		result, err := pusher.Push(ctx, oci.Rulesfile, registry+"/untag/rules:1.0.0",
+			ocipusher.WithFilepaths([]string{rulesfiletgz}),
+			ocipusher.WithArtifactConfig(oci.ArtifactConfig{Name: "rules", Version: "1.0.0"}))
+		Expect(err).ToNot(HaveOccurred())
+		digest = result.RootDigest
+	})
+
+	// Each test gets its own root command and runs it.
+	// The err variable is asserted by each test.
+	JustBeforeEach(func() {
+		rootCmd = cmd.New(ctx, opt)
+		err = executeRoot(args)
+	})
+
+	JustAfterEach(func() {
+		Expect(output.Clear()).ShouldNot(HaveOccurred())
+	})
+
+	Context("failure", func() {
+		When("reference without tag", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, untagCmd, registry + "/untag/rules", "--config", configFile}
+			})
+			untagAssertFailedBehavior(`ERROR reference "` + registry + `/untag/rules" has no tag`)
+		})
+
+		When("reference by digest", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, untagCmd, registry + "/untag/rules@" + digest, "--config", configFile}
+			})
+			untagAssertFailedBehavior("ERROR invalid reference: invalid tag")
+		})
+	})
+
+	Context("success", func() {
+		When("dry run", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, untagCmd, registry + "/untag/rules:1.0.0", "--dry-run", "--plain-http", "--config", configFile}
+			})
+
+			It("reports the tag without removing it", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(output).Should(gbytes.Say("Dry run: tag not removed"))
+				repo, err := orasRegistry.Repository(ctx, "untag/rules")
+				Expect(err).ToNot(HaveOccurred())
+				_, err = repo.Resolve(ctx, "1.0.0")
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		When("tag", func() {
+			BeforeEach(func() {
+				args = []string{registryCmd, untagCmd, registry + "/untag/rules:1.0.0", "--plain-http", "--config", configFile}
+			})
+
+			It("removes the tag and leaves the artifact in place", func() {
+				Expect(err).ToNot(HaveOccurred())
+				Expect(output).Should(gbytes.Say("Tag removed"))
+				repo, err := orasRegistry.Repository(ctx, "untag/rules")
+				Expect(err).ToNot(HaveOccurred())
+				_, err = repo.Resolve(ctx, "1.0.0")
+				Expect(err).To(HaveOccurred())
+				_, err = repo.Resolve(ctx, digest)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+	})
+})
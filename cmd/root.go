@@ -17,15 +17,18 @@ package cmd
 
 import (
 	"context"
+	"errors"
 
 	"github.com/spf13/cobra"
 
 	"github.com/diginfra/diginfractl/cmd/artifact"
 	"github.com/diginfra/diginfractl/cmd/driver"
+	"github.com/diginfra/diginfractl/cmd/follow"
 	"github.com/diginfra/diginfractl/cmd/index"
 	"github.com/diginfra/diginfractl/cmd/registry"
 	"github.com/diginfra/diginfractl/cmd/tls"
 	"github.com/diginfra/diginfractl/cmd/version"
+	"github.com/diginfra/diginfractl/internal/utils"
 	"github.com/diginfra/diginfractl/pkg/options"
 )
 
@@ -70,6 +73,7 @@ func New(ctx context.Context, opt *options.Common) *cobra.Command {
 	rootCmd.AddCommand(index.NewIndexCmd(ctx, opt))
 	rootCmd.AddCommand(artifact.NewArtifactCmd(ctx, opt))
 	rootCmd.AddCommand(driver.NewDriverCmd(ctx, opt))
+	rootCmd.AddCommand(follow.NewFollowCmd(ctx, opt))
 
 	return rootCmd
 }
@@ -79,6 +83,11 @@ func Execute(cmd *cobra.Command, opt *options.Common) error {
 	// we do not log the error here since we expect that each subcommand
 	// handles the errors by itself.
 	err := cmd.Execute()
+	// Exit codes that do not signal a failure have nothing to report.
+	var exitErr *utils.ExitError
+	if errors.As(err, &exitErr) && exitErr.Err == nil {
+		return err
+	}
 	opt.Printer.CheckErr(err)
 	return err
 }
@@ -45,6 +45,7 @@ Available Commands:
   artifact    Interact with Diginfra artifacts
   completion  Generate the autocompletion script for the specified shell
   driver      Interact with diginfra driver
+  follow      Interact with a running follower
   help        Help about any command
   index       Interact with index
   registry    Interact with OCI registries
@@ -76,6 +77,7 @@ Usage:
 Available Commands:
   artifact    Interact with Diginfra artifacts
   completion  Generate the autocompletion script for the specified shell
+  follow      Interact with a running follower
   help        Help about any command
   index       Interact with index
   registry    Interact with OCI registries
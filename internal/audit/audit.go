@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/diginfra/diginfractl/internal/lockedfile"
+)
+
+// Operation is the operation that produced an entry.
+type Operation string
+
+const (
+	// OperationInstall identifies entries produced by the "artifact install" command.
+	OperationInstall Operation = "install"
+	// OperationFollow identifies entries produced by the "artifact follow" command.
+	OperationFollow Operation = "follow"
+	// OperationRollback identifies entries produced by the "artifact rollback" command.
+	OperationRollback Operation = "rollback"
+)
+
+// Outcome is the outcome of an operation.
+type Outcome string
+
+const (
+	// OutcomeInstalled means that the artifact has been installed for the first time.
+	OutcomeInstalled Outcome = "installed"
+	// OutcomeUpdated means that a new version of the artifact replaced the previous one.
+	OutcomeUpdated Outcome = "updated"
+	// OutcomeSkipped means that nothing had to be done.
+	OutcomeSkipped Outcome = "skipped"
+	// OutcomeFailed means that the operation failed, see the error of the entry.
+	OutcomeFailed Outcome = "failed"
+	// OutcomeStaged means that the new version is waiting for approval in the staging directory.
+	OutcomeStaged Outcome = "staged"
+	// OutcomeRejected means that the staged version has been rejected.
+	OutcomeRejected Outcome = "rejected"
+	// OutcomeUnhealthy means that Diginfra turned unhealthy after the installation of the new version,
+	// and that the previous version has been restored.
+	OutcomeUnhealthy Outcome = "unhealthy"
+)
+
+// maxLineSize is the maximum size of a single entry when reading the log.
+const maxLineSize = 1 << 20
+
+// Entry is a single record of the audit log.
+type Entry struct {
+	// Timestamp is the time when the operation completed.
+	Timestamp time.Time `json:"timestamp"`
+	// Operation is the operation that produced the entry.
+	Operation Operation `json:"operation"`
+	// Ref is the reference of the artifact.
+	Ref string `json:"ref"`
+	// OldDigest is the digest of the version installed before the operation, if known.
+	OldDigest string `json:"oldDigest,omitempty"`
+	// NewDigest is the digest of the version processed by the operation.
+	NewDigest string `json:"newDigest,omitempty"`
+	// Version is the version of the artifact, as declared in its config layer.
+	Version string `json:"version,omitempty"`
+	// Signer is the identity of the signer, as returned by the signature verification.
+	Signer string `json:"signer,omitempty"`
+	// Files are the files written by the operation.
+	Files []string `json:"files,omitempty"`
+	// Outcome is the outcome of the operation.
+	Outcome Outcome `json:"outcome"`
+	// Error is the error returned by the operation, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Succeeded returns true if the entry records a new version going live.
+func (e *Entry) Succeeded() bool {
+	return e.Outcome == OutcomeInstalled || e.Outcome == OutcomeUpdated
+}
+
+// SetInstalled sets the outcome to OutcomeInstalled or OutcomeUpdated depending on the old digest.
+func (e *Entry) SetInstalled() {
+	if e.OldDigest != "" && e.OldDigest != e.NewDigest {
+		e.Outcome = OutcomeUpdated
+	} else {
+		e.Outcome = OutcomeInstalled
+	}
+}
+
+// Log is an append-only audit log stored in a file. A nil *Log discards all the entries.
+type Log struct {
+	path string
+	mu   sync.Mutex
+}
+
+// New returns a Log that appends the entries to the file at path.
+func New(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the path of the file where the entries are stored.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Record appends the entry to the log. The timestamp is set to the current time if not already set.
+func (l *Log) Record(e *Entry) error {
+	if l == nil {
+		return nil
+	}
+
+	if e.Timestamp.IsZero() {
+		e.Timestamp = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("unable to encode audit entry: %w", err)
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o750); err != nil {
+		return fmt.Errorf("unable to create directory for audit log %q: %w", l.path, err)
+	}
+
+	// The lock keeps the lines whole when more processes append to the same file.
+	release, err := lockedfile.Lock(l.path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = release() }()
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to open audit log %q: %w", l.path, err)
+	}
+
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to write audit log %q: %w", l.path, err)
+	}
+
+	return f.Close()
+}
+
+// LastDigest returns the digest of the last version of ref that went live according to the log.
+func (l *Log) LastDigest(ref string) (string, error) {
+	e, err := l.Last(ref)
+	if err != nil || e == nil {
+		return "", err
+	}
+	return e.NewDigest, nil
+}
+
+// Last returns the entry of the last version of ref that went live according to the log,
+// or nil if there is none.
+func (l *Log) Last(ref string) (*Entry, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	return l.last(ref, (*Entry).Succeeded)
+}
+
+// LastOutcome returns the last entry of ref with the given outcome, or nil if there is none.
+func (l *Log) LastOutcome(ref string, outcome Outcome) (*Entry, error) {
+	if l == nil {
+		return nil, nil
+	}
+
+	return l.last(ref, func(e *Entry) bool { return e.Outcome == outcome })
+}
+
+func (l *Log) last(ref string, match func(e *Entry) bool) (*Entry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	entries, err := Read(l.path)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Ref == ref && match(&entries[i]) {
+			return &entries[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Read returns all the entries stored in the file at path, oldest first.
+// It returns no entries if the file does not exist.
+func Read(path string) ([]Entry, error) {
+	f, err := os.Open(filepath.Clean(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to open audit log %q: %w", path, err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("unable to decode audit log %q at line %d: %w", path, line, err)
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read audit log %q: %w", path, err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "audit.log")
+	l := New(path)
+
+	entries, err := Read(path)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	first := &Entry{Operation: OperationInstall, Ref: "ghcr.io/diginfra/rules/a:1", NewDigest: "sha256:1"}
+	first.SetInstalled()
+	require.NoError(t, l.Record(first))
+	assert.Equal(t, OutcomeInstalled, first.Outcome)
+	assert.False(t, first.Timestamp.IsZero())
+
+	second := &Entry{Operation: OperationFollow, Ref: "ghcr.io/diginfra/rules/a:1", OldDigest: "sha256:1", NewDigest: "sha256:2"}
+	second.SetInstalled()
+	require.NoError(t, l.Record(second))
+	assert.Equal(t, OutcomeUpdated, second.Outcome)
+
+	require.NoError(t, l.Record(&Entry{Operation: OperationFollow, Ref: "ghcr.io/diginfra/rules/a:1",
+		NewDigest: "sha256:3", Outcome: OutcomeFailed, Error: "boom"}))
+
+	entries, err = Read(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, OperationInstall, entries[0].Operation)
+	assert.Equal(t, "sha256:2", entries[1].NewDigest)
+	assert.Equal(t, "boom", entries[2].Error)
+
+	// Failed entries do not count as live versions.
+	digest, err := l.LastDigest("ghcr.io/diginfra/rules/a:1")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:2", digest)
+
+	digest, err = l.LastDigest("ghcr.io/diginfra/rules/b:1")
+	require.NoError(t, err)
+	assert.Empty(t, digest)
+
+	failed, err := l.LastOutcome("ghcr.io/diginfra/rules/a:1", OutcomeFailed)
+	require.NoError(t, err)
+	require.NotNil(t, failed)
+	assert.Equal(t, "sha256:3", failed.NewDigest)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestNilLog(t *testing.T) {
+	var l *Log
+	assert.NoError(t, l.Record(&Entry{}))
+	digest, err := l.LastDigest("ref")
+	assert.NoError(t, err)
+	assert.Empty(t, digest)
+}
+
+func TestReadMalformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, os.WriteFile(path, []byte("{\"ref\":\"a\"}\nnot json\n"), 0o600))
+
+	_, err := Read(path)
+	assert.ErrorContains(t, err, "line 2")
+}
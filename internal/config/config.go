@@ -16,6 +16,7 @@
 package config
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"os"
@@ -29,7 +30,9 @@ import (
 	"github.com/docker/docker/pkg/homedir"
 	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
 
+	"github.com/diginfra/diginfractl/internal/lockedfile"
 	drivertype "github.com/diginfra/diginfractl/pkg/driver/type"
 	"github.com/diginfra/diginfractl/pkg/oci"
 )
@@ -45,6 +48,8 @@ var (
 	IndexesDir string
 	// ClientCredentialsFile name of the file where oauth client credentials are stored. It lives under DiginfractlPath.
 	ClientCredentialsFile string
+	// AuditLogFile name of the file where the audit log of the installed artifacts is stored. It lives under DiginfractlPath.
+	AuditLogFile string
 	// DefaultIndex is the default index for the diginfra organization.
 	DefaultIndex Index
 	// DefaultRegistryCredentialConfPath is the default path for the credential store configuration file.
@@ -71,14 +76,20 @@ const (
 	RulesfilesDir = "/etc/diginfra"
 	// AssetsDir default path where assets are installed.
 	AssetsDir = "/etc/diginfra/assets"
+	// FollowControlSocket default path of the Unix socket used by the follow commands to reach the follower.
+	FollowControlSocket = "/var/run/diginfractl/follow.sock"
 	// FollowResync time interval how often it checks for newer version of the artifact.
 	// Default values is set every 24 hours.
 	FollowResync = time.Hour * 24
+	// FollowWorkers default number of artifacts synced at the same time by the follower.
+	FollowWorkers = 4
 
 	//
 	// Viper configuration keys.
 	//
 
+	// LockTimeoutKey is the Viper key for how long the files shared with other diginfractl processes are waited for.
+	LockTimeoutKey = "lockTimeout"
 	// RegistryCredentialConfigKey is the Viper key for the credentials store path configuration.
 	//#nosec G101 -- false positive
 	RegistryCredentialConfigKey = "registry.creds.config"
@@ -88,6 +99,8 @@ const (
 	RegistryAuthBasicKey = "registry.auth.basic"
 	// RegistryAuthGcpKey is the Viper key for gcp authentication configuration.
 	RegistryAuthGcpKey = "registry.auth.gcp"
+	// RegistryMirrorsKey is the Viper key for the registry mirrors configuration.
+	RegistryMirrorsKey = "registry.mirrors"
 
 	// IndexesKey is the Viper key for indexes configuration.
 	IndexesKey = "indexes"
@@ -108,6 +121,24 @@ const (
 	ArtifactFollowAssetsDirKey = "artifact.follow.assetsdir"
 	// ArtifactFollowTmpDirKey is the Viper key for follower "pluginsDir" configuration.
 	ArtifactFollowTmpDirKey = "artifact.follow.tmpdir"
+	// ArtifactFollowControlSocketKey is the Viper key for follower "controlSocket" configuration.
+	ArtifactFollowControlSocketKey = "artifact.follow.controlsocket"
+	// ArtifactFollowWebhookAddrKey is the Viper key for follower "webhookAddr" configuration.
+	ArtifactFollowWebhookAddrKey = "artifact.follow.webhookaddr"
+	// ArtifactFollowWebhookSecretKey is the Viper key for follower "webhookSecret" configuration.
+	ArtifactFollowWebhookSecretKey = "artifact.follow.webhooksecret"
+	// ArtifactFollowStagingDirKey is the Viper key for follower "stagingDir" configuration.
+	ArtifactFollowStagingDirKey = "artifact.follow.stagingdir"
+	// ArtifactFollowPromoteAfterKey is the Viper key for follower "promoteAfter" configuration.
+	ArtifactFollowPromoteAfterKey = "artifact.follow.promoteafter"
+	// ArtifactFollowWorkersKey is the Viper key for follower "workers" configuration.
+	ArtifactFollowWorkersKey = "artifact.follow.workers"
+	// ArtifactFollowHealthProbeKey is the Viper key for follower "healthProbe" configuration.
+	ArtifactFollowHealthProbeKey = "artifact.follow.healthprobe"
+	// ArtifactFollowHealthGracePeriodKey is the Viper key for follower "healthGracePeriod" configuration.
+	ArtifactFollowHealthGracePeriodKey = "artifact.follow.healthgraceperiod"
+	// ArtifactFollowHealthIntervalKey is the Viper key for follower "healthInterval" configuration.
+	ArtifactFollowHealthIntervalKey = "artifact.follow.healthinterval"
 
 	// ArtifactInstallArtifactsKey is the Viper key for installer "artifacts" configuration.
 	ArtifactInstallArtifactsKey = "artifact.install.refs"
@@ -124,6 +155,20 @@ const (
 	ArtifactAllowedTypesKey = "artifact.allowedTypes"
 	// ArtifactNoVerifyKey is the Viper key for skipping signature verification.
 	ArtifactNoVerifyKey = "artifact.noVerify"
+	// ArtifactAuditLogKey is the Viper key for the path of the audit log. An empty value disables the audit log.
+	ArtifactAuditLogKey = "artifact.auditLog"
+	// ArtifactVersionPolicyKey is the Viper key for the policy applied to deprecated and vulnerable versions.
+	ArtifactVersionPolicyKey = "artifact.versionPolicy"
+	// ArtifactK8sDestinationKey is the Viper key for the ConfigMap or Secret where the artifacts are installed.
+	ArtifactK8sDestinationKey = "artifact.k8s.destination"
+	// ArtifactK8sNamespaceKey is the Viper key for the namespace of the Kubernetes destination.
+	ArtifactK8sNamespaceKey = "artifact.k8s.namespace"
+	// ArtifactK8sKubeconfigKey is the Viper key for the kubeconfig used to reach the Kubernetes destination.
+	ArtifactK8sKubeconfigKey = "artifact.k8s.kubeconfig"
+	// ArtifactVersionedKey is the Viper key for enabling the versioned install layout.
+	ArtifactVersionedKey = "artifact.versioned"
+	// ArtifactKeepVersionsKey is the Viper key for the number of versions kept with the versioned layout.
+	ArtifactKeepVersionsKey = "artifact.keepVersions"
 
 	// DriverKey is the Viper key for driver structure.
 	DriverKey = "driver"
@@ -149,6 +194,21 @@ type Index struct {
 	Name    string `mapstructure:"name"`
 	URL     string `mapstructure:"url"`
 	Backend string `mapstructure:"backend"`
+	// Auth is used to fetch the index from HTTP/S backends, if set.
+	Auth *IndexAuth `mapstructure:"auth" yaml:"auth,omitempty"`
+	// S3 is used to fetch the index from S3 backends, if set.
+	S3 *IndexS3 `mapstructure:"s3" yaml:"s3,omitempty"`
+	// RefreshInterval is the age after which the index is refreshed by the commands using it, if set.
+	RefreshInterval time.Duration `mapstructure:"refreshInterval" yaml:"refreshInterval,omitempty"`
+	// Priority decides which index provides an artifact when several indexes have artifacts with the same name:
+	// the highest one wins. Indexes with the same priority are ordered as configured, the last one wins.
+	Priority int `mapstructure:"priority" yaml:"priority,omitempty"`
+	// Signature is the trusted key or identity of the index, if set. It is verified against the index artifact
+	// pulled from OCI backends, and against the detached signature published next to the index otherwise.
+	Signature *Signature `mapstructure:"signature" yaml:"signature,omitempty"`
+	// SignatureURL is the location of the detached signature of the index, defaulting to the index URL
+	// followed by ".sig".
+	SignatureURL string `mapstructure:"signatureURL" yaml:"signatureURL,omitempty"`
 }
 
 // OauthAuth represents an OAuth credential.
@@ -171,24 +231,91 @@ type GcpAuth struct {
 	Registry string `mapstructure:"registry"`
 }
 
+// RegistryMirror maps an upstream registry, or a repository prefix, to the mirrors serving its artifacts.
+type RegistryMirror struct {
+	// Prefix is the upstream registry or repository prefix, such as "ghcr.io" or "ghcr.io/diginfra".
+	Prefix string `mapstructure:"prefix"`
+	// Endpoints are the mirrors, tried in order.
+	Endpoints []MirrorEndpoint `mapstructure:"endpoints"`
+	// Fallback allows pulling from upstream when none of the mirrors can serve an artifact.
+	Fallback bool `mapstructure:"fallback"`
+}
+
+// MirrorEndpoint represents a mirror of a registry.
+type MirrorEndpoint struct {
+	// Location is the registry, optionally followed by the repository prefix replacing the mirrored one.
+	Location  string `mapstructure:"location"`
+	PlainHTTP bool   `mapstructure:"plainHTTP"`
+}
+
+// ArtifactSpec represents an artifact listed in the install or follow configuration. Besides the
+// reference, it can override the settings given by the command flags for this artifact only.
+type ArtifactSpec struct {
+	Ref           string `mapstructure:"ref"`
+	RulesfilesDir string `mapstructure:"rulesfilesDir"`
+	PluginsDir    string `mapstructure:"pluginsDir"`
+	AssetsDir     string `mapstructure:"assetsDir"`
+	// Platform in the OS/ARCH format.
+	Platform string `mapstructure:"platform"`
+	NoVerify *bool  `mapstructure:"noVerify"`
+	// Schedule is either a duration, as accepted by the "every" flag, or a cron expression.
+	Schedule  string     `mapstructure:"schedule"`
+	Signature *Signature `mapstructure:"signature"`
+}
+
+// Signature represents the signature verification policy of an artifact.
+type Signature struct {
+	Cosign *CosignSignature `mapstructure:"cosign" yaml:"cosign,omitempty"`
+}
+
+// CosignSignature represents the cosign verification policy of an artifact. The fields mirror the ones
+// of the signatures found in the indexes.
+type CosignSignature struct {
+	CertificateOidcIssuer       string `mapstructure:"certificate-oidc-issuer" yaml:"certificate-oidc-issuer,omitempty"`
+	CertificateOidcIssuerRegexp string `mapstructure:"certificate-oidc-issuer-regexp" yaml:"certificate-oidc-issuer-regexp,omitempty"`
+	CertificateIdentity         string `mapstructure:"certificate-identity" yaml:"certificate-identity,omitempty"`
+	CertificateIdentityRegexp   string `mapstructure:"certificate-identity-regexp" yaml:"certificate-identity-regexp,omitempty"`
+	CertificateGithubWorkflow   string `mapstructure:"certificate-github-workflow" yaml:"certificate-github-workflow,omitempty"`
+	KeyRef                      string `mapstructure:"key" yaml:"key,omitempty"`
+	IgnoreTlog                  bool   `mapstructure:"ignore-tlog" yaml:"ignore-tlog,omitempty"`
+}
+
+// ArtifactRefs returns the references of the given artifacts.
+func ArtifactRefs(artifacts []ArtifactSpec) []string {
+	refs := make([]string, 0, len(artifacts))
+	for _, a := range artifacts {
+		refs = append(refs, a.Ref)
+	}
+	return refs
+}
+
 // Follow represents the follower configuration.
 type Follow struct {
-	Every            time.Duration `mapstructure:"every"`
-	Artifacts        []string      `mapstructure:"artifacts"`
-	DiginfraVersions string        `mapstructure:"diginfraVersions"`
-	RulesfilesDir    string        `mapstructure:"rulesFilesDir"`
-	PluginsDir       string        `mapstructure:"pluginsDir"`
-	TmpDir           string        `mapstructure:"pluginsDir"`
-	NoVerify         bool          `mapstructure:"noVerify"`
+	Every            time.Duration  `mapstructure:"every"`
+	Artifacts        []ArtifactSpec `mapstructure:"artifacts"`
+	DiginfraVersions string         `mapstructure:"diginfraVersions"`
+	RulesfilesDir    string         `mapstructure:"rulesFilesDir"`
+	PluginsDir       string         `mapstructure:"pluginsDir"`
+	TmpDir           string         `mapstructure:"pluginsDir"`
+	NoVerify         bool           `mapstructure:"noVerify"`
+	ControlSocket    string         `mapstructure:"controlSocket"`
+	WebhookAddr      string         `mapstructure:"webhookAddr"`
+	WebhookSecret    string         `mapstructure:"webhookSecret"`
+	StagingDir       string         `mapstructure:"stagingDir"`
+	PromoteAfter     time.Duration  `mapstructure:"promoteAfter"`
+	Workers          int            `mapstructure:"workers"`
+	HealthProbe      string         `mapstructure:"healthProbe"`
+	HealthGrace      time.Duration  `mapstructure:"healthGracePeriod"`
+	HealthInterval   time.Duration  `mapstructure:"healthInterval"`
 }
 
 // Install represents the installer configuration.
 type Install struct {
-	Artifacts     []string `mapstructure:"artifacts"`
-	RulesfilesDir string   `mapstructure:"rulesFilesDir"`
-	PluginsDir    string   `mapstructure:"pluginsDir"`
-	ResolveDeps   bool     `mapstructure:"resolveDeps"`
-	NoVerify      bool     `mapstructure:"noVerify"`
+	Artifacts     []ArtifactSpec `mapstructure:"artifacts"`
+	RulesfilesDir string         `mapstructure:"rulesFilesDir"`
+	PluginsDir    string         `mapstructure:"pluginsDir"`
+	ResolveDeps   bool           `mapstructure:"resolveDeps"`
+	NoVerify      bool           `mapstructure:"noVerify"`
 }
 
 // Driver represents the internal driver configuration (with Type string).
@@ -206,6 +333,7 @@ func init() {
 	IndexesFile = filepath.Join(DiginfractlPath, "indexes.yaml")
 	IndexesDir = filepath.Join(DiginfractlPath, "indexes")
 	ClientCredentialsFile = filepath.Join(DiginfractlPath, "clientcredentials.json")
+	AuditLogFile = filepath.Join(DiginfractlPath, "audit.log")
 	DefaultIndex = Index{
 		Name:    "diginfra",
 		URL:     "https://diginfra.github.io/diginfractl/index.yaml",
@@ -251,11 +379,16 @@ func Load(path string) error {
 	err = viper.ReadInConfig()
 	if errors.As(err, &viper.ConfigFileNotFoundError{}) || os.IsNotExist(err) {
 		// If the config is not found, we create the file with the
-		// already set up default values
+		// already set up default values, unless another process did it meanwhile.
 		if err = os.MkdirAll(filepath.Dir(absolutePath), 0o700); err != nil {
 			return fmt.Errorf("unable to create config directory: %w", err)
 		}
-		if err = viper.WriteConfigAs(path); err != nil {
+		if err = lockedfile.Update(absolutePath, 0o644, func(data []byte) ([]byte, error) {
+			if data != nil {
+				return data, nil
+			}
+			return yaml.Marshal(viper.AllSettings())
+		}); err != nil {
 			return fmt.Errorf("unable to write config file: %w", err)
 		}
 	} else if err != nil {
@@ -274,14 +407,22 @@ func Load(path string) error {
 	// Bind to environment variables.
 	viper.AutomaticEnv()
 
+	if viper.IsSet(LockTimeoutKey) {
+		lockedfile.Timeout = viper.GetDuration(LockTimeoutKey)
+	}
+
 	return nil
 }
 
 // Indexes retrieves the indexes section of the config file.
 func Indexes() ([]Index, error) {
+	return indexesFrom(viper.GetViper())
+}
+
+func indexesFrom(v *viper.Viper) ([]Index, error) {
 	var indexes []Index
 
-	if err := viper.UnmarshalKey(IndexesKey, &indexes, viper.DecodeHook(indexListHookFunc())); err != nil {
+	if err := v.UnmarshalKey(IndexesKey, &indexes, viper.DecodeHook(indexListHookFunc())); err != nil {
 		return nil, fmt.Errorf("unable to get indexes from configuration: %w", err)
 	}
 
@@ -290,15 +431,30 @@ func Indexes() ([]Index, error) {
 
 // Gcps retrieves the gcp auth section of the config file.
 func Gcps() ([]GcpAuth, error) {
+	return gcpsFrom(viper.GetViper())
+}
+
+func gcpsFrom(v *viper.Viper) ([]GcpAuth, error) {
 	var auths []GcpAuth
 
-	if err := viper.UnmarshalKey(RegistryAuthGcpKey, &auths, viper.DecodeHook(gcpAuthListHookFunc())); err != nil {
+	if err := v.UnmarshalKey(RegistryAuthGcpKey, &auths, viper.DecodeHook(gcpAuthListHookFunc())); err != nil {
 		return nil, fmt.Errorf("unable to get gcpAuths: %w", err)
 	}
 
 	return auths, nil
 }
 
+// RegistryMirrors retrieves the registry mirrors section of the config file.
+func RegistryMirrors() ([]RegistryMirror, error) {
+	var mirrors []RegistryMirror
+
+	if err := viper.UnmarshalKey(RegistryMirrorsKey, &mirrors); err != nil {
+		return nil, fmt.Errorf("unable to get registry mirrors: %w", err)
+	}
+
+	return mirrors, nil
+}
+
 // indexListHookFunc returns a DecodeHookFunc that converts
 // strings to string slices, when the target type is DotSeparatedStringList.
 // when passed as env should be in the following format:
@@ -338,8 +494,16 @@ func indexListHookFunc() mapstructure.DecodeHookFuncType {
 			return indexes, nil
 		case reflect.Slice:
 			var indexes []Index
-			if err := mapstructure.WeakDecode(data, &indexes); err != nil {
-				return err, nil
+			decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+				DecodeHook:       mapstructure.ComposeDecodeHookFunc(secretHookFunc(), mapstructure.StringToTimeDurationHookFunc()),
+				WeaklyTypedInput: true,
+				Result:           &indexes,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := decoder.Decode(data); err != nil {
+				return nil, err
 			}
 			return indexes, nil
 		default:
@@ -517,14 +681,9 @@ func gcpAuthListHookFunc() mapstructure.DecodeHookFuncType {
 
 // Follower retrieves the follower section of the config file.
 func Follower() (Follow, error) {
-	// with Follow we can just use nested keys.
-	// env variables can just make use of ";" to separat
-	artifacts := viper.GetStringSlice(ArtifactFollowRefsKey)
-	if len(artifacts) == 1 { // in this case it might come from the env
-		if !SemicolonSeparatedRegexp.MatchString(artifacts[0]) {
-			return Follow{}, fmt.Errorf("env variable not correctly set, should match %q, got %q", SemicolonSeparatedRegexp.String(), artifacts[0])
-		}
-		artifacts = strings.Split(artifacts[0], ";")
+	artifacts, err := artifactSpecs(ArtifactFollowRefsKey)
+	if err != nil {
+		return Follow{}, err
 	}
 
 	return Follow{
@@ -535,19 +694,23 @@ func Follower() (Follow, error) {
 		PluginsDir:       viper.GetString(ArtifactFollowPluginsDirKey),
 		TmpDir:           viper.GetString(ArtifactFollowTmpDirKey),
 		NoVerify:         viper.GetBool(ArtifactNoVerifyKey),
+		ControlSocket:    viper.GetString(ArtifactFollowControlSocketKey),
+		WebhookAddr:      viper.GetString(ArtifactFollowWebhookAddrKey),
+		WebhookSecret:    viper.GetString(ArtifactFollowWebhookSecretKey),
+		StagingDir:       viper.GetString(ArtifactFollowStagingDirKey),
+		PromoteAfter:     viper.GetDuration(ArtifactFollowPromoteAfterKey),
+		Workers:          viper.GetInt(ArtifactFollowWorkersKey),
+		HealthProbe:      viper.GetString(ArtifactFollowHealthProbeKey),
+		HealthGrace:      viper.GetDuration(ArtifactFollowHealthGracePeriodKey),
+		HealthInterval:   viper.GetDuration(ArtifactFollowHealthIntervalKey),
 	}, nil
 }
 
 // Installer retrieves the installer section of the config file.
 func Installer() (Install, error) {
-	// with Install we can just use nested keys.
-	// env variables can just make use of ";" to separat
-	artifacts := viper.GetStringSlice(ArtifactInstallArtifactsKey)
-	if len(artifacts) == 1 { // in this case it might come from the env
-		if !SemicolonSeparatedRegexp.MatchString(artifacts[0]) {
-			return Install{}, fmt.Errorf("env variable not correctly set, should match %q, got %q", SemicolonSeparatedRegexp.String(), artifacts[0])
-		}
-		artifacts = strings.Split(artifacts[0], ";")
+	artifacts, err := artifactSpecs(ArtifactInstallArtifactsKey)
+	if err != nil {
+		return Install{}, err
 	}
 
 	return Install{
@@ -559,6 +722,52 @@ func Installer() (Install, error) {
 	}, nil
 }
 
+// artifactSpecs retrieves the artifacts listed under key. Each artifact is either a plain reference
+// or an object overriding the command settings for it.
+func artifactSpecs(key string) ([]ArtifactSpec, error) {
+	var artifacts []ArtifactSpec
+
+	if err := viper.UnmarshalKey(key, &artifacts, viper.DecodeHook(artifactListHookFunc())); err != nil {
+		return nil, fmt.Errorf("unable to get artifacts from configuration: %w", err)
+	}
+
+	for i, a := range artifacts {
+		if a.Ref == "" {
+			return nil, fmt.Errorf("missing ref for artifact #%d in %q", i+1, key)
+		}
+	}
+
+	return artifacts, nil
+}
+
+// artifactListHookFunc returns a DecodeHookFunc that converts plain references to ArtifactSpec.
+// When passed as env, the references should be in the following format:
+// "diginfra-rules:0;ghcr.io/diginfra/plugins/ruleset/k8saudit:latest".
+func artifactListHookFunc() mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String {
+			return data, nil
+		}
+
+		switch t {
+		case reflect.TypeOf([]ArtifactSpec{}):
+			if !SemicolonSeparatedRegexp.MatchString(data.(string)) {
+				return data, fmt.Errorf("env variable not correctly set, should match %q, got %q", SemicolonSeparatedRegexp.String(), data.(string))
+			}
+			tokens := strings.Split(data.(string), ";")
+			artifacts := make([]ArtifactSpec, len(tokens))
+			for i, token := range tokens {
+				artifacts[i] = ArtifactSpec{Ref: token}
+			}
+			return artifacts, nil
+		case reflect.TypeOf(ArtifactSpec{}):
+			return ArtifactSpec{Ref: data.(string)}, nil
+		default:
+			return data, nil
+		}
+	}
+}
+
 // DriverTypes retrieves the driver types of the config file.
 func DriverTypes() ([]string, error) {
 	// manage driver.Type as ";" separated list.
@@ -620,34 +829,45 @@ func ArtifactAllowedTypes() (*oci.ArtifactTypeSlice, error) {
 }
 
 // UpdateConfigFile is used to update a section of the config file.
-// We create a brand new viper instance for doing it so that we are sure that modifications
-// are scoped to the passed key with no side effects (e.g user forgot to unset one env variable for
-// another config setting, avoid to mistakenly update it).
 func UpdateConfigFile(key string, value interface{}, path string) error {
-	v := viper.New()
-	// we keep these for consistency, but not actually used
-	// since we explicitly set the filepath later
-	v.SetConfigName("diginfractl")
-	v.SetConfigType("yaml")
+	if err := updateConfigFile(path, func(v *viper.Viper) error {
+		v.Set(key, value)
+		return nil
+	}); err != nil {
+		return fmt.Errorf("unable to set key %q to config file: %w", key, err)
+	}
 
+	return nil
+}
+
+// updateConfigFile lets update modify the config file and writes the result back. The config file is
+// locked meanwhile, so that the updates made by concurrent processes are not lost.
+// We create a brand new viper instance for doing it so that we are sure that modifications
+// are scoped to the updated keys with no side effects (e.g user forgot to unset one env variable for
+// another config setting, avoid to mistakenly update it).
+func updateConfigFile(path string, update func(v *viper.Viper) error) error {
 	absolutePath, err := filepath.Abs(path)
 	if err != nil {
 		return err
 	}
 
-	v.SetConfigFile(absolutePath)
-
-	if err := v.ReadInConfig(); err != nil {
-		return fmt.Errorf("config: error reading config file: %w", err)
-	}
+	return lockedfile.Update(absolutePath, 0o644, func(data []byte) ([]byte, error) {
+		if data == nil {
+			return nil, fmt.Errorf("config: config file %q not found", absolutePath)
+		}
 
-	v.Set(key, value)
+		v := viper.New()
+		v.SetConfigType("yaml")
+		if err := v.ReadConfig(bytes.NewReader(data)); err != nil {
+			return nil, fmt.Errorf("config: error reading config file: %w", err)
+		}
 
-	if err := v.WriteConfig(); err != nil {
-		return fmt.Errorf("unable to set key %q to config file: %w", key, err)
-	}
+		if err := update(v); err != nil {
+			return nil, err
+		}
 
-	return nil
+		return yaml.Marshal(v.AllSettings())
+	})
 }
 
 // DiginfraVersions represent the map for Diginfra requirements
@@ -658,20 +878,20 @@ type DiginfraVersions map[string]string
 
 // AddIndexes appends the provided indexes to a configuration file if not present.
 func AddIndexes(indexes []Index, configFile string) error {
-	var currIndexes []Index
-	var err error
-
-	// Retrieve the current indexes from configuration.
-	if currIndexes, err = Indexes(); err != nil {
-		return err
-	}
-	for i, idx := range indexes {
-		if _, ok := findIndexInSlice(currIndexes, &indexes[i]); !ok {
-			currIndexes = append(currIndexes, idx)
+	if err := updateConfigFile(configFile, func(v *viper.Viper) error {
+		// Retrieve the current indexes from the config file, they may have changed since it was loaded.
+		currIndexes, err := currentIndexes(v)
+		if err != nil {
+			return err
 		}
-	}
-
-	if err := UpdateConfigFile(IndexesKey, currIndexes, configFile); err != nil {
+		for i, idx := range indexes {
+			if _, ok := findIndexInSlice(currIndexes, &indexes[i]); !ok {
+				currIndexes = append(currIndexes, idx)
+			}
+		}
+		v.Set(IndexesKey, currIndexes)
+		return nil
+	}); err != nil {
 		return fmt.Errorf("unable to update indexes list in the config file %q: %w", configFile, err)
 	}
 
@@ -680,26 +900,34 @@ func AddIndexes(indexes []Index, configFile string) error {
 
 // RemoveIndexes removes the index entries from a configuration file if any is found.
 func RemoveIndexes(names []string, configFile string) error {
-	var currIndexes []Index
-	var err error
-
-	// Retrieve the current indexes from configuration.
-	if currIndexes, err = Indexes(); err != nil {
-		return err
-	}
-	for _, name := range names {
-		if i, ok := findIndexInSlice(currIndexes, &Index{Name: name}); ok {
-			currIndexes = append(currIndexes[:i], currIndexes[i+1:]...)
+	if err := updateConfigFile(configFile, func(v *viper.Viper) error {
+		// Retrieve the current indexes from the config file, they may have changed since it was loaded.
+		currIndexes, err := currentIndexes(v)
+		if err != nil {
+			return err
 		}
-	}
-
-	if err := UpdateConfigFile(IndexesKey, currIndexes, configFile); err != nil {
+		for _, name := range names {
+			if i, ok := findIndexInSlice(currIndexes, &Index{Name: name}); ok {
+				currIndexes = append(currIndexes[:i], currIndexes[i+1:]...)
+			}
+		}
+		v.Set(IndexesKey, currIndexes)
+		return nil
+	}); err != nil {
 		return fmt.Errorf("unable to update indexes list in the config file %q: %w", configFile, err)
 	}
 
 	return nil
 }
 
+// currentIndexes returns the indexes of a config file, or the default one if it has none.
+func currentIndexes(v *viper.Viper) ([]Index, error) {
+	if !v.IsSet(IndexesKey) {
+		return []Index{DefaultIndex}, nil
+	}
+	return indexesFrom(v)
+}
+
 func findIndexInSlice(slice []Index, val *Index) (int, bool) {
 	for i, item := range slice {
 		if item.Name == val.Name {
@@ -711,20 +939,20 @@ func findIndexInSlice(slice []Index, val *Index) (int, bool) {
 
 // AddGcp appends the provided gcps to a configuration file if not present.
 func AddGcp(gcps []GcpAuth, configFile string) error {
-	var currGcps []GcpAuth
-	var err error
-
-	// Retrieve the current gcps from configuration.
-	if currGcps, err = Gcps(); err != nil {
-		return err
-	}
-	for i, gcp := range gcps {
-		if _, ok := findGcpInSlice(currGcps, &gcps[i]); !ok {
-			currGcps = append(currGcps, gcp)
+	if err := updateConfigFile(configFile, func(v *viper.Viper) error {
+		// Retrieve the current gcps from the config file, they may have changed since it was loaded.
+		currGcps, err := gcpsFrom(v)
+		if err != nil {
+			return err
 		}
-	}
-
-	if err := UpdateConfigFile(RegistryAuthGcpKey, currGcps, configFile); err != nil {
+		for i, gcp := range gcps {
+			if _, ok := findGcpInSlice(currGcps, &gcps[i]); !ok {
+				currGcps = append(currGcps, gcp)
+			}
+		}
+		v.Set(RegistryAuthGcpKey, currGcps)
+		return nil
+	}); err != nil {
 		return fmt.Errorf("unable to update gcps list in the config file %q: %w", configFile, err)
 	}
 
@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
+)
+
+// Secret is a sensitive value given either inline, or read from a file or an environment variable.
+// In the configuration, a plain string is an inline value.
+type Secret struct {
+	Value string `mapstructure:"value" yaml:"value,omitempty"`
+	File  string `mapstructure:"file" yaml:"file,omitempty"`
+	Env   string `mapstructure:"env" yaml:"env,omitempty"`
+}
+
+// IsZero returns true if no value is set.
+func (s Secret) IsZero() bool {
+	return s == Secret{}
+}
+
+// Resolve returns the value of the secret, reading it from its file or environment variable if needed.
+// Leading and trailing whitespaces are trimmed from the values read from files.
+func (s Secret) Resolve() (string, error) {
+	switch {
+	case s.File != "":
+		data, err := os.ReadFile(s.File)
+		if err != nil {
+			return "", fmt.Errorf("unable to read secret file: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	case s.Env != "":
+		val, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("environment variable %q not set", s.Env)
+		}
+		return val, nil
+	default:
+		return s.Value, nil
+	}
+}
+
+// UnmarshalYAML decodes a Secret from either a plain string or a mapping.
+func (s *Secret) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*s = Secret{Value: node.Value}
+		return nil
+	}
+
+	type plain Secret
+	return node.Decode((*plain)(s))
+}
+
+// MarshalYAML encodes an inline Secret as a plain string.
+func (s Secret) MarshalYAML() (interface{}, error) {
+	if s.File == "" && s.Env == "" {
+		return s.Value, nil
+	}
+
+	type plain Secret
+	return plain(s), nil
+}
+
+// IndexAuth represents the credentials and the TLS settings used to fetch an index from an HTTP/S backend.
+type IndexAuth struct {
+	// BearerToken is sent in the "Authorization: Bearer" header.
+	BearerToken Secret `mapstructure:"bearerToken" yaml:"bearerToken,omitempty"`
+	// Username and Password are sent as basic credentials.
+	Username string `mapstructure:"username" yaml:"username,omitempty"`
+	Password Secret `mapstructure:"password" yaml:"password,omitempty"`
+	// Headers are additional headers sent with the requests.
+	Headers map[string]Secret `mapstructure:"headers" yaml:"headers,omitempty"`
+	// CAFile is a PEM bundle of certificate authorities trusted besides the system ones.
+	CAFile string `mapstructure:"caFile" yaml:"caFile,omitempty"`
+	// CertFile and KeyFile are the PEM client certificate and key used for mutual TLS.
+	CertFile string `mapstructure:"certFile" yaml:"certFile,omitempty"`
+	KeyFile  string `mapstructure:"keyFile" yaml:"keyFile,omitempty"`
+}
+
+// HasInlineSecrets returns true if any of the secrets is stored inline.
+func (a *IndexAuth) HasInlineSecrets() bool {
+	if a == nil {
+		return false
+	}
+	if a.BearerToken.Value != "" || a.Password.Value != "" {
+		return true
+	}
+	for _, h := range a.Headers {
+		if h.Value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// secretHookFunc returns a DecodeHookFunc that converts plain strings to inline secrets.
+func secretHookFunc() mapstructure.DecodeHookFuncType {
+	return func(f reflect.Type, t reflect.Type, data interface{}) (interface{}, error) {
+		if f.Kind() != reflect.String || t != reflect.TypeOf(Secret{}) {
+			return data, nil
+		}
+		return Secret{Value: data.(string)}, nil
+	}
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+// IndexS3 represents the settings used to fetch an index from an S3 or S3-compatible backend.
+type IndexS3 struct {
+	// Endpoint is the URL of S3-compatible stores, such as MinIO or Ceph. AWS S3 is used if empty.
+	Endpoint string `mapstructure:"endpoint" yaml:"endpoint,omitempty"`
+	// Region defaults to the one of the AWS configuration, if any.
+	Region string `mapstructure:"region" yaml:"region,omitempty"`
+	// PathStyle addresses the objects as ENDPOINT/BUCKET/KEY instead of BUCKET.ENDPOINT/KEY,
+	// as required by most S3-compatible stores.
+	PathStyle bool `mapstructure:"pathStyle" yaml:"pathStyle,omitempty"`
+	// AccessKeyID, SecretAccessKey and SessionToken are static credentials. The standard AWS
+	// credential chain is used if they are not set.
+	AccessKeyID     Secret `mapstructure:"accessKeyID" yaml:"accessKeyID,omitempty"`
+	SecretAccessKey Secret `mapstructure:"secretAccessKey" yaml:"secretAccessKey,omitempty"`
+	SessionToken    Secret `mapstructure:"sessionToken" yaml:"sessionToken,omitempty"`
+}
+
+// HasStaticCredentials returns true if static credentials are set.
+func (s *IndexS3) HasStaticCredentials() bool {
+	return s != nil && (!s.AccessKeyID.IsZero() || !s.SecretAccessKey.IsZero())
+}
+
+// HasInlineSecrets returns true if any of the secrets is stored inline.
+func (s *IndexS3) HasInlineSecrets() bool {
+	return s != nil && (s.AccessKeyID.Value != "" || s.SecretAccessKey.Value != "" || s.SessionToken.Value != "")
+}
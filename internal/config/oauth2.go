@@ -22,6 +22,8 @@ import (
 	"os"
 
 	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/diginfra/diginfractl/internal/lockedfile"
 )
 
 // RegistryClientCredentials is used to store registry:clientCrendetials key value.
@@ -68,22 +70,23 @@ func ClientCredentials(reg string) (*clientcredentials.Config, error) {
 
 // WriteClientCredentials writes client credentials to config file.
 func WriteClientCredentials(registry string, cred *clientcredentials.Config) error {
-	creds, err := readClientCredentials()
-	if err != nil && !errors.Is(err, os.ErrNotExist) {
-		return err
-	}
-
-	if creds == nil {
-		creds = make(RegistryClientCredentials)
-	}
-	creds[registry] = *cred
-
-	data, err := json.Marshal(creds)
+	// The file is locked while updated, so that the credentials stored by concurrent processes are not lost.
+	err := lockedfile.Update(ClientCredentialsFile, 0o600, func(data []byte) ([]byte, error) {
+		creds := make(RegistryClientCredentials)
+		if data != nil {
+			if err := json.Unmarshal(data, &creds); err != nil {
+				return nil, fmt.Errorf("unable to unmarshal client credentials: %w", err)
+			}
+		}
+		creds[registry] = *cred
+
+		data, err := json.Marshal(creds)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal %+v", creds)
+		}
+		return data, nil
+	})
 	if err != nil {
-		return fmt.Errorf("unable to marshal %+v", creds)
-	}
-
-	if err = os.WriteFile(ClientCredentialsFile, data, 0o600); err != nil {
 		return fmt.Errorf("unable to write to %s: %w", ClientCredentialsFile, err)
 	}
 
@@ -28,6 +28,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 
 	"github.com/google/go-containerregistry/pkg/name"
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/fulcio"
@@ -39,6 +40,7 @@ import (
 	"github.com/sigstore/cosign/v2/pkg/cosign"
 	"github.com/sigstore/cosign/v2/pkg/cosign/pivkey"
 	"github.com/sigstore/cosign/v2/pkg/cosign/pkcs11key"
+	"github.com/sigstore/cosign/v2/pkg/oci"
 	sigs "github.com/sigstore/cosign/v2/pkg/signature"
 	"github.com/sigstore/sigstore/pkg/cryptoutils"
 	"github.com/sigstore/sigstore/pkg/signature"
@@ -79,6 +81,8 @@ type VerifyCommand struct {
 	Offline                      bool
 	TSACertChainPath             string
 	IgnoreTlog                   bool
+	// Signers is filled by DoVerify with the identities found in the certificates of the verified signatures.
+	Signers []string
 }
 
 //nolint:gocyclo,revive // cosign v2 verification
@@ -259,10 +263,11 @@ func (c *VerifyCommand) DoVerify(ctx context.Context, images []string) (err erro
 
 	for _, img := range images {
 		if c.LocalImage {
-			_, _, err := cosign.VerifyLocalImageSignatures(ctx, img, co)
+			checked, _, err := cosign.VerifyLocalImageSignatures(ctx, img, co)
 			if err != nil {
 				return err
 			}
+			c.Signers = appendSigners(c.Signers, checked)
 		} else {
 			ref, err := name.ParseReference(img, c.NameOptions...)
 			if err != nil {
@@ -273,16 +278,33 @@ func (c *VerifyCommand) DoVerify(ctx context.Context, images []string) (err erro
 				return fmt.Errorf("resolving attachment type %s for image %s: %w", c.Attachment, img, err)
 			}
 
-			_, _, err = cosign.VerifyImageSignatures(ctx, ref, co)
+			checked, _, err := cosign.VerifyImageSignatures(ctx, ref, co)
 			if err != nil {
 				return cosignError.WrapError(err)
 			}
+			c.Signers = appendSigners(c.Signers, checked)
 		}
 	}
 
 	return nil
 }
 
+// appendSigners appends to signers the subject alternative names of the signing certificates, skipping duplicates.
+func appendSigners(signers []string, checked []oci.Signature) []string {
+	for _, s := range checked {
+		cert, err := s.Cert()
+		if err != nil || cert == nil {
+			continue
+		}
+		for _, id := range cryptoutils.GetSubjectAlternateNames(cert) {
+			if !slices.Contains(signers, id) {
+				signers = append(signers, id)
+			}
+		}
+	}
+	return signers
+}
+
 func loadCertFromFileOrURL(path string) (*x509.Certificate, error) {
 	pems, err := blob.LoadFileOrURL(path)
 	if err != nil {
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2023 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+//nolint:goheader // code from Sigstore
+
+package cosign
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/fulcio"
+	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
+	"github.com/sigstore/cosign/v2/pkg/cosign"
+	"github.com/sigstore/cosign/v2/pkg/cosign/pkcs11key"
+	"github.com/sigstore/cosign/v2/pkg/oci"
+	"github.com/sigstore/cosign/v2/pkg/oci/static"
+	sigs "github.com/sigstore/cosign/v2/pkg/signature"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+)
+
+// VerifyBlobCommand verifies a detached signature of a blob.
+type VerifyBlobCommand struct {
+	options.CertVerifyOptions
+	KeyRef     string
+	IgnoreSCT  bool
+	IgnoreTlog bool
+	// Signers is filled by DoVerify with the identities found in the certificate of the verified signature.
+	Signers []string
+}
+
+// DoVerify verifies the signature of the blob. The signature is either the base64 signature written by
+// "cosign sign-blob --output-signature", or the bundle written by "cosign sign-blob --bundle", which also
+// carries the signing certificate and the transparency log entry needed by keyless verifications.
+//
+//nolint:gocyclo // cosign v2 verification
+func (c *VerifyBlobCommand) DoVerify(ctx context.Context, blob, signature []byte) (err error) {
+	co := &cosign.CheckOpts{
+		IgnoreSCT:  c.IgnoreSCT,
+		IgnoreTlog: c.IgnoreTlog,
+	}
+
+	if c.KeyRef == "" {
+		co.Identities, err = c.Identities()
+		if err != nil {
+			return err
+		}
+	}
+
+	var b64sig string
+	var certPEM []byte
+	var opts []static.Option
+	var payload cosign.LocalSignedPayload
+	if json.Unmarshal(signature, &payload) == nil && payload.Base64Signature != "" {
+		b64sig = payload.Base64Signature
+		if payload.Bundle != nil {
+			opts = append(opts, static.WithBundle(payload.Bundle))
+		}
+		if payload.Cert != "" && c.KeyRef == "" {
+			cert, err := loadCertFromPEM([]byte(payload.Cert))
+			if err != nil {
+				return fmt.Errorf("loading certificate from bundle: %w", err)
+			}
+			if certPEM, err = cryptoutils.MarshalCertificateToPEM(cert); err != nil {
+				return err
+			}
+		}
+	} else {
+		b64sig = strings.TrimSpace(string(signature))
+	}
+
+	if !c.IgnoreTlog {
+		// This performs an online fetch of the Rekor public keys, but this is needed
+		// for verifying tlog entries (both online and offline).
+		co.RekorPubKeys, err = cosign.GetRekorPubs(ctx)
+		if err != nil {
+			return fmt.Errorf("getting Rekor public keys: %w", err)
+		}
+	}
+
+	if c.KeyRef != "" {
+		co.SigVerifier, err = sigs.PublicKeyFromKeyRef(ctx, c.KeyRef)
+		if err != nil {
+			return fmt.Errorf("loading public key: %w", err)
+		}
+		if pkcs11Key, ok := co.SigVerifier.(*pkcs11key.Key); ok {
+			defer pkcs11Key.Close()
+		}
+	} else {
+		if certPEM == nil {
+			return errors.New("keyless verification requires a bundle carrying the signing certificate")
+		}
+		// This performs an online fetch of the Fulcio roots. This is needed
+		// for verifying keyless certificates (both online and offline).
+		co.RootCerts, err = fulcio.GetRoots()
+		if err != nil {
+			return fmt.Errorf("getting Fulcio roots: %w", err)
+		}
+		co.IntermediateCerts, err = fulcio.GetIntermediates()
+		if err != nil {
+			return fmt.Errorf("getting Fulcio intermediates: %w", err)
+		}
+		if !c.IgnoreSCT {
+			co.CTLogPubKeys, err = cosign.GetCTLogPubs(ctx)
+			if err != nil {
+				return fmt.Errorf("getting ctlog public keys: %w", err)
+			}
+		}
+		opts = append(opts, static.WithCertChain(certPEM, nil))
+	}
+
+	sig, err := static.NewSignature(blob, b64sig, opts...)
+	if err != nil {
+		return err
+	}
+	if _, err = cosign.VerifyBlobSignature(ctx, sig, co); err != nil {
+		return err
+	}
+
+	c.Signers = appendSigners(c.Signers, []oci.Signature{sig})
+	return nil
+}
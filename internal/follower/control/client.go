@@ -0,0 +1,116 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/diginfra/diginfractl/internal/follower"
+)
+
+// Client talks to the control API exposed by a running follower process.
+type Client struct {
+	httpClient *http.Client
+}
+
+// NewClient returns a Client that connects to the Unix socket at socketPath.
+func NewClient(socketPath string) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", socketPath)
+				},
+			},
+		},
+	}
+}
+
+// Status returns the status of the follower for ref, or of all followers if ref is empty.
+func (c *Client) Status(ctx context.Context, ref string) ([]follower.Status, error) {
+	return c.do(ctx, http.MethodGet, FollowersPath, ref)
+}
+
+// Sync triggers an immediate sync of the follower for ref, or of all followers if ref is empty.
+func (c *Client) Sync(ctx context.Context, ref string) ([]follower.Status, error) {
+	return c.do(ctx, http.MethodPost, SyncPath, ref)
+}
+
+// Pause suspends the updates of the follower for ref, or of all followers if ref is empty.
+func (c *Client) Pause(ctx context.Context, ref string) ([]follower.Status, error) {
+	return c.do(ctx, http.MethodPost, PausePath, ref)
+}
+
+// Resume resumes the updates of the follower for ref, or of all followers if ref is empty.
+func (c *Client) Resume(ctx context.Context, ref string) ([]follower.Status, error) {
+	return c.do(ctx, http.MethodPost, ResumePath, ref)
+}
+
+// Approve installs the version staged by the follower for ref, or by all followers if ref is empty.
+func (c *Client) Approve(ctx context.Context, ref string) ([]follower.Status, error) {
+	return c.do(ctx, http.MethodPost, ApprovePath, ref)
+}
+
+// Reject discards the version staged by the follower for ref, or by all followers if ref is empty.
+func (c *Client) Reject(ctx context.Context, ref string) ([]follower.Status, error) {
+	return c.do(ctx, http.MethodPost, RejectPath, ref)
+}
+
+func (c *Client) do(ctx context.Context, method, path, ref string) ([]follower.Status, error) {
+	// The host is ignored since we always dial the Unix socket.
+	u := url.URL{Scheme: "http", Host: "diginfractl", Path: path}
+	if ref != "" {
+		u.RawQuery = url.Values{RefParam: []string{ref}}.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to reach the follower control API: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var errResp errorResponse
+		if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
+			return nil, fmt.Errorf("request failed: %s", errResp.Error)
+		}
+		return nil, fmt.Errorf("request failed: %s", resp.Status)
+	}
+
+	var res []follower.Status
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil, fmt.Errorf("unable to decode response: %w", err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,168 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diginfra/diginfractl/internal/follower"
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+type fakeTarget struct {
+	mu       sync.Mutex
+	ref      string
+	paused   bool
+	syncs    int
+	staged   bool
+	approved int
+}
+
+func (f *fakeTarget) Ref() string { return f.ref }
+
+func (f *fakeTarget) Status() follower.Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return follower.Status{Ref: f.ref, Paused: f.paused}
+}
+
+func (f *fakeTarget) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.paused {
+		return follower.ErrPaused
+	}
+	f.syncs++
+	return nil
+}
+
+func (f *fakeTarget) Pause() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = true
+}
+
+func (f *fakeTarget) Resume() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = false
+}
+
+func (f *fakeTarget) Approve() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.staged {
+		return follower.ErrNothingStaged
+	}
+	f.staged = false
+	f.approved++
+	return nil
+}
+
+func (f *fakeTarget) Reject() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.staged {
+		return follower.ErrNothingStaged
+	}
+	f.staged = false
+	return nil
+}
+
+func TestControlAPI(t *testing.T) {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	first := &fakeTarget{ref: "ghcr.io/diginfra/rules/first:0"}
+	second := &fakeTarget{ref: "ghcr.io/diginfra/rules/second:0"}
+
+	// Keep the path short, Unix socket paths are limited in length.
+	dir, err := os.MkdirTemp("", "ctl")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "follow.sock")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- NewServer(printer, first, second).ListenAndServe(ctx, socket)
+	}()
+
+	client := NewClient(socket)
+	require.Eventually(t, func() bool {
+		_, err := client.Status(ctx, "")
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	st, err := client.Status(ctx, "")
+	assert.NoError(t, err)
+	assert.Len(t, st, 2)
+	assert.Equal(t, first.ref, st[0].Ref)
+	assert.Equal(t, second.ref, st[1].Ref)
+
+	_, err = client.Status(ctx, "unknown")
+	assert.ErrorContains(t, err, "no follower found")
+
+	st, err = client.Pause(ctx, first.ref)
+	assert.NoError(t, err)
+	assert.Len(t, st, 1)
+	assert.True(t, st[0].Paused)
+
+	_, err = client.Sync(ctx, first.ref)
+	assert.ErrorContains(t, err, follower.ErrPaused.Error())
+
+	// When syncing all the followers, the paused ones are skipped and reported.
+	st, err = client.Sync(ctx, "")
+	assert.NoError(t, err)
+	require.Len(t, st, 2)
+	assert.Equal(t, follower.ErrPaused.Error(), st[0].Skipped)
+	assert.Empty(t, st[1].Skipped)
+	assert.Equal(t, 0, first.syncs)
+	assert.Equal(t, 1, second.syncs)
+
+	_, err = client.Resume(ctx, "")
+	assert.NoError(t, err)
+
+	_, err = client.Sync(ctx, "")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, first.syncs)
+	assert.Equal(t, 2, second.syncs)
+
+	_, err = client.Approve(ctx, first.ref)
+	assert.ErrorContains(t, err, follower.ErrNothingStaged.Error())
+
+	first.staged = true
+	_, err = client.Approve(ctx, first.ref)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, first.approved)
+
+	second.staged = true
+	_, err = client.Reject(ctx, second.ref)
+	assert.NoError(t, err)
+	assert.False(t, second.staged)
+
+	cancel()
+	assert.NoError(t, <-done)
+	_, err = os.Stat(socket)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,224 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package control
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/diginfra/diginfractl/internal/follower"
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+const (
+	// FollowersPath is the endpoint used to list the followers.
+	FollowersPath = "/v1/followers"
+	// SyncPath is the endpoint used to trigger an immediate sync.
+	SyncPath = "/v1/followers/sync"
+	// PausePath is the endpoint used to pause the scheduled updates.
+	PausePath = "/v1/followers/pause"
+	// ResumePath is the endpoint used to resume the scheduled updates.
+	ResumePath = "/v1/followers/resume"
+	// ApprovePath is the endpoint used to approve the staged versions.
+	ApprovePath = "/v1/followers/approve"
+	// RejectPath is the endpoint used to reject the staged versions.
+	RejectPath = "/v1/followers/reject"
+	// RefParam is the query parameter used to select a single follower. When not set, all followers are selected.
+	RefParam = "ref"
+
+	shutdownTimeout = 5 * time.Second
+)
+
+// Target is implemented by the objects that can be managed through the control API.
+type Target interface {
+	Ref() string
+	Status() follower.Status
+	Sync() error
+	Pause()
+	Resume()
+	Approve() error
+	Reject() error
+}
+
+// errorResponse is the body returned when a request fails.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server serves the control API for a set of targets.
+type Server struct {
+	targets map[string]Target
+	printer *output.Printer
+}
+
+// NewServer returns a new Server for the given targets.
+func NewServer(printer *output.Printer, targets ...Target) *Server {
+	s := &Server{
+		targets: make(map[string]Target, len(targets)),
+		printer: printer,
+	}
+	for _, t := range targets {
+		s.targets[t.Ref()] = t
+	}
+
+	return s
+}
+
+// Handler returns the http.Handler implementing the control API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+FollowersPath, s.handleStatus)
+	mux.HandleFunc("POST "+SyncPath, s.handleAction(func(t Target) error { return t.Sync() }))
+	mux.HandleFunc("POST "+PausePath, s.handleAction(func(t Target) error { t.Pause(); return nil }))
+	mux.HandleFunc("POST "+ResumePath, s.handleAction(func(t Target) error { t.Resume(); return nil }))
+	mux.HandleFunc("POST "+ApprovePath, s.handleAction(Target.Approve))
+	mux.HandleFunc("POST "+RejectPath, s.handleAction(Target.Reject))
+
+	return mux
+}
+
+// ListenAndServe listens on the Unix socket at socketPath and serves the control API
+// until the context is canceled. A stale socket file left by a previous run is removed.
+func (s *Server) ListenAndServe(ctx context.Context, socketPath string) error {
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0o750); err != nil {
+		return fmt.Errorf("unable to create directory for control socket %q: %w", socketPath, err)
+	}
+	if err := os.Remove(socketPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unable to remove stale control socket %q: %w", socketPath, err)
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		return fmt.Errorf("unable to listen on control socket %q: %w", socketPath, err)
+	}
+	defer os.Remove(socketPath)
+
+	// Only the owner is allowed to drive the followers.
+	if err := os.Chmod(socketPath, 0o600); err != nil {
+		_ = listener.Close()
+		return fmt.Errorf("unable to set permissions on control socket %q: %w", socketPath, err)
+	}
+
+	srv := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: shutdownTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	s.printer.Logger.Info("Serving control API", s.printer.Logger.Args("socket", socketPath))
+	if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("control API server failed: %w", err)
+	}
+
+	return nil
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	targets, err := s.selectTargets(r.URL.Query().Get(RefParam))
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, statuses(targets))
+}
+
+func (s *Server) handleAction(action func(Target) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ref := r.URL.Query().Get(RefParam)
+		targets, err := s.selectTargets(ref)
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: err.Error()})
+			return
+		}
+
+		skipped := make(map[string]string)
+		for _, t := range targets {
+			if err := action(t); err != nil {
+				conflict := errors.Is(err, follower.ErrPaused) || errors.Is(err, follower.ErrNothingStaged)
+				// When acting on all the followers, the paused ones and the ones without a staged version
+				// are skipped, so that the action is applied to all the others.
+				if ref == "" && conflict {
+					skipped[t.Ref()] = err.Error()
+					continue
+				}
+				code := http.StatusInternalServerError
+				if conflict {
+					code = http.StatusConflict
+				}
+				writeJSON(w, code, errorResponse{Error: fmt.Sprintf("%s: %s", t.Ref(), err.Error())})
+				return
+			}
+		}
+
+		s.printer.Logger.Debug("Control request served", s.printer.Logger.Args("path", r.URL.Path, "followers", len(targets),
+			"skipped", len(skipped)))
+		res := statuses(targets)
+		for i := range res {
+			res[i].Skipped = skipped[res[i].Ref]
+		}
+		writeJSON(w, http.StatusOK, res)
+	}
+}
+
+// selectTargets returns the target matching ref or all of them if ref is empty.
+func (s *Server) selectTargets(ref string) ([]Target, error) {
+	if ref != "" {
+		t, ok := s.targets[ref]
+		if !ok {
+			return nil, fmt.Errorf("no follower found for ref %q", ref)
+		}
+		return []Target{t}, nil
+	}
+
+	targets := make([]Target, 0, len(s.targets))
+	for _, t := range s.targets {
+		targets = append(targets, t)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Ref() < targets[j].Ref()
+	})
+
+	return targets, nil
+}
+
+func statuses(targets []Target) []follower.Status {
+	res := make([]follower.Status, 0, len(targets))
+	for _, t := range targets {
+		res = append(res, t.Status())
+	}
+	return res
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(body)
+}
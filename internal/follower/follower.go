@@ -18,8 +18,10 @@ package follower
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -32,25 +34,53 @@ import (
 	"github.com/pterm/pterm"
 	"github.com/robfig/cron/v3"
 	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
 
+	"github.com/diginfra/diginfractl/internal/audit"
 	"github.com/diginfra/diginfractl/internal/config"
+	"github.com/diginfra/diginfractl/internal/health"
+	"github.com/diginfra/diginfractl/internal/kube"
+	"github.com/diginfra/diginfractl/internal/layout"
 	"github.com/diginfra/diginfractl/internal/signature"
 	"github.com/diginfra/diginfractl/internal/utils"
 	"github.com/diginfra/diginfractl/pkg/index/index"
 	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/mirror"
 	ocipuller "github.com/diginfra/diginfractl/pkg/oci/puller"
 	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
 	"github.com/diginfra/diginfractl/pkg/output"
 )
 
 // Follower knows how to track an artifact in a remote repository given the reference.
-// It starts a new goroutine to check for updates periodically. If an update is available
+// It is run periodically by a Scheduler to check for updates. If an update is available
 // it pulls the new version and installs it in the correct directory.
 type Follower struct {
 	ref           string
 	tag           string
+	repo          string
 	tmpDir        string
 	currentDigest string
+	// mu protects the state exposed through Status.
+	mu       sync.Mutex
+	lastErr  error
+	lastSync time.Time
+	nextRun  time.Time
+	paused   bool
+	// staged is the version waiting for approval, if any.
+	staged *stagedArtifact
+	// rejectedDigest is the digest of the last rejected version, which is not staged again.
+	rejectedDigest string
+	// unhealthyDigest is the digest of the last version that turned Diginfra unhealthy, which is not installed again.
+	unhealthyDigest string
+	// syncChan is used to request an immediate sync.
+	syncChan chan struct{}
+	// approveChan and rejectChan are used to approve or reject the staged version.
+	approveChan chan struct{}
+	rejectChan  chan struct{}
+	// nextStagingCheck is the time of the next check of the staging directory.
+	nextStagingCheck time.Time
+	// notify, if set, is called to wake up the scheduler when a request is made.
+	notify func()
 	*ocipuller.Puller
 	*Config
 	logger *pterm.Logger
@@ -60,9 +90,6 @@ type Follower struct {
 
 // Config configuration options for the Follower.
 type Config struct {
-	WaitGroup *sync.WaitGroup
-	// CloseChan used to close the follower.
-	CloseChan <-chan bool
 	// Resync time after which periodically it checks for new a new version.
 	Resync cron.Schedule
 	// RulesfilesDir directory where the rulesfile are stored.
@@ -75,19 +102,88 @@ type Config struct {
 	ArtifactReference string
 	// PlainHTTP is set to true if all registry interaction must be in plain http.
 	PlainHTTP bool
+	// Client is the registry client, which can be shared by several followers so that they
+	// reuse the same cached tokens. If nil, a new client is created.
+	Client remote.Client
 	// TmpDir directory where to save temporary files.
 	TmpDir string
 	// DiginfraVersions is a struct containing all the required Diginfra versions that this follower
 	// has to take into account when installing artifacts.
 	DiginfraVersions config.DiginfraVersions
+	// RefreshDiginfraVersions, if set, is used to fetch the Diginfra versions again before checking the
+	// requirements of a new artifact version. On failure, the last known versions are used.
+	RefreshDiginfraVersions func(ctx context.Context) (config.DiginfraVersions, error)
 	// AllowedTypes specify a list of artifacts that we are allowed to download.
 	AllowedTypes oci.ArtifactTypeSlice
 	// Signature has the data needed for signature checking
 	Signature *index.Signature
+	// StagingDir directory where new versions wait for approval before being installed.
+	// If empty, new versions are installed right away.
+	StagingDir string
+	// PromoteAfter delay after which a staged version is installed unless rejected.
+	// If zero, staged versions wait for an explicit approval.
+	PromoteAfter time.Duration
+	// Audit is where the outcome of each sync is recorded. If nil, nothing is recorded.
+	Audit *audit.Log
+	// Kube, if set, is the ConfigMap or Secret where the artifacts are installed instead of the local directories.
+	Kube *kube.Destination
+	// PlatformOS and PlatformArch are the platform of the artifact to be pulled.
+	// If empty, the ones of the running system are used.
+	PlatformOS   string
+	PlatformArch string
+	// Versioned, if true, installs each version in its own directory and switches the active one
+	// atomically, see the layout package.
+	Versioned bool
+	// KeepVersions is the number of versions kept for each artifact with the versioned layout.
+	KeepVersions int
+	// Health, if set, is used to watch Diginfra after each installation in the local directories.
+	// If Diginfra turns unhealthy, the previous version is restored and the new one is not installed again.
+	Health *health.Check
+	// Mirrors are tried, in order, before the upstream registry of the artifact.
+	Mirrors mirror.Mirrors
+	// IndexEntry, if set, is the index entry of the artifact, whose lifecycle metadata are checked
+	// before installing a new version.
+	IndexEntry *index.Entry
+	// VersionPolicy tells whether new versions that are deprecated, past their end of life or affected
+	// by a security advisory are installed with a warning or refused.
+	VersionPolicy index.VersionPolicy
 }
 
+// Status reports the current state of a Follower.
+type Status struct {
+	// Ref is the reference of the followed artifact.
+	Ref string `json:"ref"`
+	// Schedule is a human-readable description of the resync schedule.
+	Schedule string `json:"schedule"`
+	// CurrentDigest is the digest of the last installed version.
+	CurrentDigest string `json:"currentDigest"`
+	// LastError is the error returned by the last sync, if any.
+	LastError string `json:"lastError,omitempty"`
+	// LastSync is the time when the last sync completed.
+	LastSync time.Time `json:"lastSync"`
+	// NextRun is the time when the next scheduled sync will run.
+	NextRun time.Time `json:"nextRun"`
+	// Paused is true when scheduled updates are suspended.
+	Paused bool `json:"paused"`
+	// StagedDigest is the digest of the version waiting for approval, if any.
+	StagedDigest string `json:"stagedDigest,omitempty"`
+	// StagedAt is the time when the version waiting for approval has been staged.
+	StagedAt time.Time `json:"stagedAt,omitempty"`
+	// UnhealthyDigest is the digest of the last version that turned Diginfra unhealthy, if any.
+	UnhealthyDigest string `json:"unhealthyDigest,omitempty"`
+	// Skipped is set in the responses of the control API to the reason why the requested action was not
+	// applied to the follower, when acting on all of them.
+	Skipped string `json:"skipped,omitempty"`
+}
+
+// restoreTimeout is the timeout of the requests made to restore the state of a follower at creation time.
+const restoreTimeout = 10 * time.Second
+
 var (
 	isInt = regexp.MustCompile(`^(0|([1-9]\d*))$`)
+
+	// ErrPaused is returned when a sync is requested for a paused follower.
+	ErrPaused = errors.New("follower is paused")
 )
 
 // New creates a Follower configured with the passed parameters and ready to be used.
@@ -104,15 +200,15 @@ func New(ref string, printer *output.Printer, conf *Config) (*Follower, error) {
 	}
 	tag := parsedRef.Reference
 
-	client, err := ociutils.Client(false)
-	if err != nil {
-		return nil, err
+	client := conf.Client
+	if client == nil {
+		if client, err = ociutils.Client(true); err != nil {
+			return nil, err
+		}
 	}
 
 	puller := ocipuller.NewPuller(client, conf.PlainHTTP, nil)
-	if err != nil {
-		return nil, err
-	}
+	puller.Mirrors = conf.Mirrors
 
 	// Create temp dir where to put pulled artifacts.
 	tmpDir, err := os.MkdirTemp(conf.TmpDir, "diginfractl-")
@@ -120,90 +216,506 @@ func New(ref string, printer *output.Printer, conf *Config) (*Follower, error) {
 		return nil, fmt.Errorf("unable to create temporary directory: %w", err)
 	}
 
-	return &Follower{
+	f := &Follower{
 		ref:              ref,
 		tag:              tag,
+		repo:             parsedRef.Registry + "/" + parsedRef.Repository,
 		tmpDir:           tmpDir,
 		Puller:           puller,
 		Config:           conf,
+		syncChan:         make(chan struct{}, 1),
+		approveChan:      make(chan struct{}, 1),
+		rejectChan:       make(chan struct{}, 1),
 		logger:           printer.Logger,
 		DiginfraVersions: conf.DiginfraVersions,
-	}, nil
-}
-
-// Follow starts a goroutine that periodically checks for updates for the configured artifact.
-func (f *Follower) Follow(ctx context.Context) {
-	// At start up time of the follower we sync immediately without waiting the resync time.
-	f.follow(ctx)
-
-	for {
-		now := time.Now()
-		next := f.Resync.Next(now)
-		select {
-		case <-f.CloseChan:
-			f.cleanUp()
-			f.logger.Info("Follower stopped", f.logger.Args("followerName", f.ref))
-			// Notify that the follower is done.
-			f.WaitGroup.Done()
+	}
+
+	if f.stagingEnabled() {
+		if err := f.loadStaged(); err != nil {
+			return nil, fmt.Errorf("unable to load staged version for ref %q: %w", ref, err)
+		}
+	}
+
+	f.restoreDigest()
+	f.restoreUnhealthyDigest()
+
+	return f, nil
+}
+
+// restoreDigest initializes the current digest with the last version recorded in the audit log,
+// provided that the files it installed are still there. Otherwise, the artifact is installed again
+// at the first sync.
+func (f *Follower) restoreDigest() {
+	// A Kubernetes destination records the digest of the stored artifacts by itself.
+	if f.Kube != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), restoreTimeout)
+		defer cancel()
+		info, err := f.Kube.Artifact(ctx, f.ref)
+		if err != nil {
+			f.logger.Warn("Unable to read Kubernetes destination", f.logger.Args("followerName", f.ref, "reason", err.Error()))
 			return
-		case <-time.After(next.Sub(now)):
+		}
+		if info != nil {
+			f.currentDigest = info.Digest
+		}
+		return
+	}
+
+	last, err := f.Audit.Last(f.ref)
+	if err != nil {
+		f.logger.Warn("Unable to read audit log", f.logger.Args("followerName", f.ref, "reason", err.Error()))
+		return
+	}
+	if last == nil {
+		return
+	}
+
+	for _, file := range last.Files {
+		if ok, err := utils.FileExists(file); err != nil || !ok {
+			f.logger.Debug("Installed files missing, ignoring the recorded digest",
+				f.logger.Args("followerName", f.ref, "file", file))
+			return
+		}
+	}
+
+	f.currentDigest = last.NewDigest
+}
+
+// run serves the pending requests and, when due, the scheduled sync and the check of the staging directory.
+func (f *Follower) run(ctx context.Context, now time.Time) {
+	select {
+	case <-f.approveChan:
+		f.logger.Info("Approval requested", f.logger.Args("followerName", f.ref))
+		_, err := f.promote(ctx)
+		f.recordStagingErr(err)
+	default:
+	}
+
+	select {
+	case <-f.rejectChan:
+		f.logger.Info("Rejection requested", f.logger.Args("followerName", f.ref))
+		_, err := f.reject()
+		f.recordStagingErr(err)
+	default:
+	}
+
+	select {
+	case <-f.syncChan:
+		f.logger.Info("Sync requested", f.logger.Args("followerName", f.ref))
+		f.sync(ctx)
+		f.schedule()
+	default:
+		if now.Before(f.nextRunTime()) {
+			break
+		}
+		if f.IsPaused() {
+			f.logger.Debug("Follower paused, skipping scheduled sync", f.logger.Args("followerName", f.ref))
+		} else {
 			// Start following the artifact.
-			f.follow(ctx)
+			f.sync(ctx)
+		}
+		f.schedule()
+	}
+
+	// The staging directory is checked periodically for approvals and expired delays.
+	if f.stagingEnabled() && !now.Before(f.nextStagingCheck) {
+		if !f.IsPaused() {
+			_, err := f.checkStaged(ctx)
+			f.recordStagingErr(err)
+		}
+		f.nextStagingCheck = now.Add(stagingPollInterval)
+	}
+}
+
+// wakeAt returns the time at which the follower has to be run next.
+func (f *Follower) wakeAt() time.Time {
+	if len(f.syncChan) > 0 || len(f.approveChan) > 0 || len(f.rejectChan) > 0 {
+		return time.Time{}
+	}
+
+	at := f.nextRunTime()
+	if f.stagingEnabled() && f.nextStagingCheck.Before(at) {
+		at = f.nextStagingCheck
+	}
+	return at
+}
+
+// schedule computes and records the time of the next scheduled sync.
+func (f *Follower) schedule() time.Time {
+	next := f.Resync.Next(time.Now())
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextRun = next
+	return next
+}
+
+// nextRunTime returns the time of the next scheduled sync.
+func (f *Follower) nextRunTime() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.nextRun
+}
+
+// Sync requests an immediate sync of the followed artifact. The request is served
+// asynchronously by the Scheduler running the follower. It returns ErrPaused if the
+// follower is paused.
+func (f *Follower) Sync() error {
+	if f.IsPaused() {
+		return ErrPaused
+	}
+
+	select {
+	case f.syncChan <- struct{}{}:
+	default:
+		// A sync is already pending, nothing to do.
+	}
+	f.wake()
+
+	return nil
+}
+
+// wake notifies the scheduler, if any, that a request is pending.
+func (f *Follower) wake() {
+	if f.notify != nil {
+		f.notify()
+	}
+}
+
+// Pause suspends the scheduled updates until Resume is called.
+func (f *Follower) Pause() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = true
+}
+
+// Resume re-enables the scheduled updates.
+func (f *Follower) Resume() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paused = false
+}
+
+// IsPaused returns true if the scheduled updates are suspended.
+func (f *Follower) IsPaused() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.paused
+}
+
+// Ref returns the reference of the followed artifact.
+func (f *Follower) Ref() string {
+	return f.ref
+}
+
+// Status returns the current state of the follower.
+func (f *Follower) Status() Status {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	st := Status{
+		Ref:             f.ref,
+		CurrentDigest:   f.currentDigest,
+		LastSync:        f.lastSync,
+		NextRun:         f.nextRun,
+		Paused:          f.paused,
+		UnhealthyDigest: f.unhealthyDigest,
+	}
+	if f.staged != nil {
+		st.StagedDigest = f.staged.Digest
+		st.StagedAt = f.staged.StagedAt
+	}
+	if s, ok := f.Resync.(fmt.Stringer); ok {
+		st.Schedule = s.String()
+	}
+	if f.lastErr != nil {
+		st.LastError = f.lastErr.Error()
+	}
+
+	return st
+}
+
+// RunOnce runs a single sync of the followed artifact, without scheduling further ones, and
+// returns the audit entries describing what happened. When staging is enabled, the staged
+// version is promoted or rejected first, according to the marker files and the promotion delay.
+// The follower cannot be used anymore after RunOnce returns.
+func (f *Follower) RunOnce(ctx context.Context) []*audit.Entry {
+	defer f.cleanUp()
+
+	var entries []*audit.Entry
+	if f.stagingEnabled() {
+		entry, err := f.checkStaged(ctx)
+		if err != nil && entry == nil {
+			entry = f.auditEntry()
+			entry.Outcome = audit.OutcomeFailed
+			entry.Error = err.Error()
+		}
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+
+	return append(entries, f.sync(ctx))
+}
+
+// sync runs a follow iteration, records its outcome and returns the related audit entry.
+func (f *Follower) sync(ctx context.Context) *audit.Entry {
+	entry := f.auditEntry()
+	err := f.follow(ctx, entry)
+	if err != nil {
+		entry.Outcome = failedOutcome(err)
+		entry.Error = err.Error()
+	}
+	f.audit(entry)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastErr = err
+	f.lastSync = time.Now()
+
+	return entry
+}
+
+// recordStagingErr records the error returned by a promotion or a rejection, if any.
+func (f *Follower) recordStagingErr(err error) {
+	if err == nil {
+		return
+	}
+	f.logger.Error("Unable to process staged artifact", f.logger.Args("followerName", f.ref, "reason", err.Error()))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastErr = err
+}
+
+// auditEntry returns a new audit entry for the followed artifact.
+func (f *Follower) auditEntry() *audit.Entry {
+	entry := &audit.Entry{
+		Operation: audit.OperationFollow,
+		Ref:       f.ref,
+		OldDigest: f.digest(),
+	}
+
+	// After a restart the installed digest is only known by the audit log.
+	if entry.OldDigest == "" {
+		digest, err := f.Audit.LastDigest(f.ref)
+		if err != nil {
+			f.logger.Warn("Unable to read audit log", f.logger.Args("followerName", f.ref, "reason", err.Error()))
 		}
+		entry.OldDigest = digest
 	}
+
+	return entry
 }
 
-func (f *Follower) follow(ctx context.Context) {
+// audit records the entry in the audit log, if configured.
+func (f *Follower) audit(entry *audit.Entry) {
+	if err := f.Audit.Record(entry); err != nil {
+		f.logger.Warn("Unable to write audit log", f.logger.Args("followerName", f.ref, "reason", err.Error()))
+	}
+}
+
+func (f *Follower) follow(ctx context.Context, entry *audit.Entry) error {
 	// First thing get the descriptor from remote repo.
 	f.logger.Debug("Fetching descriptor from remote repository...", f.logger.Args("followerName", f.ref))
 	desc, err := f.Descriptor(ctx, f.ref)
 	if err != nil {
 		f.logger.Debug(fmt.Sprintf("an error occurred while fetching descriptor from remote repository: %v", err))
-		return
+		return fmt.Errorf("unable to fetch descriptor: %w", err)
 	}
 	f.logger.Debug("Descriptor correctly fetched", f.logger.Args("followerName", f.ref))
+	entry.NewDigest = desc.Digest.String()
+	entry.Outcome = audit.OutcomeSkipped
 
 	// If we have already processed then do nothing.
 	// TODO(alacuku): check that the file also exists to cover the case when someone has removed the file.
-	if desc.Digest.String() == f.currentDigest {
+	if desc.Digest.String() == f.digest() {
 		f.logger.Debug("Nothing to do, artifact already up to date.", f.logger.Args("followerName", f.ref))
-		return
+		return nil
+	}
+
+	f.mu.Lock()
+	unhealthy := f.unhealthyDigest == desc.Digest.String()
+	f.mu.Unlock()
+	if unhealthy {
+		f.logger.Debug("Nothing to do, artifact version turned Diginfra unhealthy", f.logger.Args("followerName", f.ref))
+		return nil
+	}
+
+	if f.stagingEnabled() {
+		f.mu.Lock()
+		staged, rejected := f.staged != nil && f.staged.Digest == desc.Digest.String(), f.rejectedDigest == desc.Digest.String()
+		f.mu.Unlock()
+		if staged {
+			f.logger.Debug("Nothing to do, artifact already staged and waiting for approval", f.logger.Args("followerName", f.ref))
+			return nil
+		}
+		if rejected {
+			f.logger.Debug("Nothing to do, artifact version has been rejected", f.logger.Args("followerName", f.ref))
+			return nil
+		}
+	}
+
+	rolledBack, err := f.rolledBack(desc.Digest.String())
+	if err != nil {
+		return err
+	}
+	if rolledBack {
+		f.logger.Debug("Nothing to do, artifact version has been rolled back", f.logger.Args("followerName", f.ref))
+		return nil
 	}
 
 	f.logger.Info("Found new artifact version", f.logger.Args("followerName", f.ref, "tag", f.tag))
 
 	// Pull config layer to check diginfra versions
-	artifactConfig, err := f.ArtifactConfig(ctx, f.ref, runtime.GOOS, runtime.GOARCH)
+	artifactConfig, err := f.ArtifactConfig(ctx, f.ref, f.platformOS(), f.platformArch())
 	if err != nil {
 		f.logger.Error("Unable to pull config layer", f.logger.Args("followerName", f.ref, "reason", err.Error()))
-		return
+		return fmt.Errorf("unable to pull config layer: %w", err)
 	}
+	entry.Version = artifactConfig.Version
 
+	f.refreshDiginfraVersions(ctx)
 	err = f.checkRequirements(artifactConfig)
 	if err != nil {
 		f.logger.Error("Unmet requirements", f.logger.Args("followerName", f.ref, "reason", err.Error()))
-		return
+		return fmt.Errorf("unmet requirements: %w", err)
+	}
+
+	if err := f.checkVersion(artifactConfig.Version); err != nil {
+		return err
 	}
 
 	f.logger.Debug("Pulling artifact", f.logger.Args("followerName", f.ref))
 	// Pull the artifact from the repository.
-	filePaths, res, err := f.pull(ctx)
+	filePaths, res, err := f.pull(ctx, entry)
 	if err != nil {
 		f.logger.Error("Unable to pull artifact", f.logger.Args("followerName", f.ref, "reason", err.Error()))
-		return
+		return err
 	}
 	f.logger.Debug("Artifact correctly pulled", f.logger.Args("followerName", f.ref))
 
-	dstDir := f.destinationDir(res)
+	if f.stagingEnabled() {
+		return f.stage(ctx, entry, res, filePaths)
+	}
 
+	installed, dst, err := f.deliver(ctx, res.Type, entry.NewDigest, entry.Version, filePaths)
+	if err != nil {
+		return err
+	}
+	entry.Files = installed
+	entry.SetInstalled()
+	if len(installed) == 0 {
+		// The files already had the same content, nothing changed.
+		entry.Outcome = audit.OutcomeSkipped
+	}
+
+	f.logger.Info("Artifact correctly installed",
+		f.logger.Args("followerName", f.ref, "artifactName", f.ref, "type", res.Type, "digest", res.Digest, "destination", dst))
+	f.mu.Lock()
+	f.currentDigest = desc.Digest.String()
+	f.mu.Unlock()
+
+	return nil
+}
+
+// deliver installs the files of an artifact of the given type in the Kubernetes destination, if configured,
+// or in the destination directory of the type. It returns the locations of the written files and the destination.
+func (f *Follower) deliver(ctx context.Context, artifactType oci.ArtifactType, digest, version string,
+	filePaths []string) (installed []string, dst string, err error) {
+	if f.Kube != nil {
+		dst = f.Kube.String()
+		if artifactType == oci.Plugin {
+			return nil, dst, fmt.Errorf("plugins cannot be installed in %s", dst)
+		}
+		installed, err = f.Kube.Write(ctx, f.ref, digest, version, filePaths)
+		if err != nil {
+			f.logger.Error("Unable to write Kubernetes destination", f.logger.Args("followerName", f.ref, "destination", dst, "reason", err.Error()))
+		}
+		return installed, dst, err
+	}
+
+	dst = f.destinationDir(artifactType)
 	// Check if directory exists and is writable.
-	err = utils.ExistsAndIsWritable(dstDir)
+	if err := utils.ExistsAndIsWritable(dst); err != nil {
+		f.logger.Error("Invalid destination", f.logger.Args("followerName", f.ref, "directory", dst, "reason", err.Error()))
+		return nil, dst, fmt.Errorf("invalid destination %q: %w", dst, err)
+	}
+
+	var restore func() error
+	if f.Health != nil {
+		var cleanUp func()
+		if restore, cleanUp, err = f.prepareRestore(filePaths, dst); err != nil {
+			return nil, dst, err
+		}
+		defer cleanUp()
+	}
+
+	if f.Versioned {
+		installed, err = f.installVersion(filePaths, dst, digest, version)
+	} else {
+		installed, err = f.install(filePaths, dst)
+	}
+	if err != nil || f.Health == nil || len(installed) == 0 {
+		return installed, dst, err
+	}
+
+	return installed, dst, f.checkHealth(ctx, digest, installed, restore)
+}
+
+// installVersion stores the files as a new version in dstDir and activates it.
+// It returns the paths of the links written in dstDir.
+func (f *Follower) installVersion(filePaths []string, dstDir, digest, version string) ([]string, error) {
+	ver := layout.Version{Ref: f.ref, Digest: digest, Version: version}
+	installed, err := layout.New(dstDir, f.KeepVersions).Install(layout.Name(f.ref), ver, func(dir string) error {
+		for _, path := range filePaths {
+			if err := utils.Move(path, filepath.Join(dir, filepath.Base(path))); err != nil {
+				return fmt.Errorf("unable to move file %q: %w", path, err)
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		f.logger.Error("Invalid destination", f.logger.Args("followerName", f.ref, "directory", dstDir, "reason", err.Error()))
-		return
+		f.logger.Error("Unable to install version", f.logger.Args("followerName", f.ref, "directory", dstDir, "reason", err.Error()))
+		return nil, err
+	}
+
+	return installed, nil
+}
+
+// rolledBack returns true if the version with the given digest has been rolled back in one of the
+// destination directories. Rolled back versions are not installed again.
+func (f *Follower) rolledBack(digest string) (bool, error) {
+	if !f.Versioned || f.Kube != nil {
+		return false, nil
 	}
 
-	// Install the artifacts if necessary.
+	for _, dir := range []string{f.RulesfilesDir, f.PluginsDir, f.AssetsDir} {
+		if dir == "" {
+			continue
+		}
+		ok, err := layout.New(dir, f.KeepVersions).RolledBack(layout.Name(f.ref), digest)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// upToDate returns true if the files are already installed, with the same content, in their destination.
+func (f *Follower) upToDate(ctx context.Context, artifactType oci.ArtifactType, filePaths []string) (bool, error) {
+	if f.Kube != nil {
+		return f.Kube.Equal(ctx, filePaths)
+	}
+	return f.installed(filePaths, f.destinationDir(artifactType))
+}
+
+// install moves the files in dstDir, overwriting the existing ones if they differ.
+// It returns the paths of the files written in dstDir.
+func (f *Follower) install(filePaths []string, dstDir string) (installed []string, err error) {
 	for _, path := range filePaths {
 		baseName := filepath.Base(path)
 		f.logger.Debug("Installing file", f.logger.Args("followerName", f.ref, "fileName", baseName))
@@ -213,15 +725,16 @@ func (f *Follower) follow(ctx context.Context) {
 		exists, err := utils.FileExists(dstPath)
 		if err != nil {
 			f.logger.Error("Unable to check existence for file", f.logger.Args("followerName", f.ref, "fileName", baseName, "reason", err.Error()))
-			return
+			return installed, fmt.Errorf("unable to check existence for file %q: %w", dstPath, err)
 		}
 
 		if !exists {
 			f.logger.Debug("Moving file", f.logger.Args("followerName", f.ref, "fileName", baseName, "destDirectory", dstDir))
 			if err = utils.Move(path, dstPath); err != nil {
 				f.logger.Error("Unable to move file", f.logger.Args("followerName", f.ref, "fileName", baseName, "destDirectory", dstDir, "reason", err.Error()))
-				return
+				return installed, fmt.Errorf("unable to move file %q: %w", path, err)
 			}
+			installed = append(installed, dstPath)
 			f.logger.Debug("File correctly installed", f.logger.Args("followerName", f.ref, "path", path))
 			// It's done, move to the next file.
 			continue
@@ -232,40 +745,47 @@ func (f *Follower) follow(ctx context.Context) {
 		eq, err := equal([]string{path, dstPath})
 		if err != nil {
 			f.logger.Error("Unable to compare files", f.logger.Args("followerName", f.ref, "newFile", path, "existingFile", dstPath, "reason", err.Error()))
-			return
+			return installed, fmt.Errorf("unable to compare files: %w", err)
 		}
 
 		if !eq {
 			f.logger.Debug(fmt.Sprintf("Overwriting file %q with file %q", dstPath, path), f.logger.Args("followerName", f.ref))
 			if err = utils.Move(path, dstPath); err != nil {
 				f.logger.Error("Unable to overwrite file", f.logger.Args("followerName", f.ref, "existingFile", dstPath, "reason", err.Error()))
-				return
+				return installed, fmt.Errorf("unable to overwrite file %q: %w", dstPath, err)
 			}
+			installed = append(installed, dstPath)
 		} else {
 			f.logger.Debug("The two file are equal, nothing to be done")
 		}
 	}
 
-	f.logger.Info("Artifact correctly installed",
-		f.logger.Args("followerName", f.ref, "artifactName", f.ref, "type", res.Type, "digest", res.Digest, "directory", dstDir))
-	f.currentDigest = desc.Digest.String()
+	return installed, nil
+}
+
+// digest returns the digest of the currently installed artifact.
+func (f *Follower) digest() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.currentDigest
 }
 
 // pull downloads, extracts, and installs the artifact.
-func (f *Follower) pull(ctx context.Context) (filePaths []string, res *oci.RegistryResult, err error) {
+func (f *Follower) pull(ctx context.Context, entry *audit.Entry) (filePaths []string, res *oci.RegistryResult, err error) {
 	f.logger.Debug("Check if pulling an allowed type of artifact", f.logger.Args("followerName", f.ref))
-	if err := f.Puller.CheckAllowedType(ctx, f.ref, runtime.GOOS, runtime.GOARCH, f.Config.AllowedTypes.Types); err != nil {
+	if err := f.Puller.CheckAllowedType(ctx, f.ref, f.platformOS(), f.platformArch(), f.Config.AllowedTypes.Types); err != nil {
 		return nil, nil, err
 	}
 
 	// Pull the artifact from the repository.
 	f.logger.Debug("Pulling artifact %q", f.logger.Args("followerName", f.ref, "artifactName", f.ref))
-	res, err = f.Pull(ctx, f.ref, f.tmpDir, runtime.GOOS, runtime.GOARCH)
+	res, err = f.Pull(ctx, f.ref, f.tmpDir, f.platformOS(), f.platformArch())
 	if err != nil {
 		return filePaths, res, fmt.Errorf("unable to pull artifact %q: %w", f.ref, err)
 	}
 
-	repo, err := utils.RepositoryFromRef(f.ref)
+	// The signature is checked where the artifact has been pulled from, which may be a mirror.
+	repo, err := utils.RepositoryFromRef(res.Source)
 	if err != nil {
 		return filePaths, res, err
 	}
@@ -275,7 +795,7 @@ func (f *Follower) pull(ctx context.Context) (filePaths []string, res *oci.Regis
 	// Verify the signature if needed
 	if f.Config.Signature != nil {
 		f.logger.Debug("Verifying signature", f.logger.Args("followerName", f.ref, "digest", digestRef))
-		err = signature.Verify(ctx, digestRef, f.Config.Signature)
+		entry.Signer, err = signature.VerifySigner(ctx, digestRef, res.PlainHTTP, f.Config.Signature)
 		if err != nil {
 			return filePaths, res, fmt.Errorf("could not verify signature for %s: %w", res.RootDigest, err)
 		}
@@ -305,10 +825,26 @@ func (f *Follower) pull(ctx context.Context) (filePaths []string, res *oci.Regis
 	return filePaths, res, err
 }
 
+// platformOS returns the OS of the artifact to be pulled.
+func (f *Follower) platformOS() string {
+	if f.PlatformOS != "" {
+		return f.PlatformOS
+	}
+	return runtime.GOOS
+}
+
+// platformArch returns the architecture of the artifact to be pulled.
+func (f *Follower) platformArch() string {
+	if f.PlatformArch != "" {
+		return f.PlatformArch
+	}
+	return runtime.GOARCH
+}
+
 // destinationDir returns the dir where to save the artifact.
-func (f *Follower) destinationDir(res *oci.RegistryResult) string {
+func (f *Follower) destinationDir(artifactType oci.ArtifactType) string {
 	var dir string
-	switch res.Type {
+	switch artifactType {
 	case oci.Plugin:
 		dir = f.PluginsDir
 	case oci.Rulesfile:
@@ -319,6 +855,27 @@ func (f *Follower) destinationDir(res *oci.RegistryResult) string {
 	return dir
 }
 
+// refreshDiginfraVersions updates the Diginfra versions used to check the requirements, keeping
+// the last known ones if they cannot be retrieved.
+func (f *Follower) refreshDiginfraVersions(ctx context.Context) {
+	if f.RefreshDiginfraVersions == nil {
+		return
+	}
+
+	f.logger.Debug("Refreshing Diginfra versions", f.logger.Args("followerName", f.ref))
+	versions, err := f.RefreshDiginfraVersions(ctx)
+	if err != nil {
+		f.logger.Warn("Unable to refresh Diginfra versions, using the last known ones",
+			f.logger.Args("followerName", f.ref, "reason", err.Error()))
+		return
+	}
+
+	if !maps.Equal(versions, f.DiginfraVersions) {
+		f.logger.Info("Diginfra versions changed", f.logger.Args("followerName", f.ref, "versions", versions))
+	}
+	f.DiginfraVersions = versions
+}
+
 func (f *Follower) checkRequirements(artifactConfig *oci.ArtifactConfig) error {
 	// Check if each requirement specified in a config layer meet the needs of the
 	// currently running Diginfra.
@@ -366,6 +923,24 @@ func (f *Follower) checkRequirements(artifactConfig *oci.ArtifactConfig) error {
 	return nil
 }
 
+// checkVersion logs the lifecycle notices of a new version, as published by the index,
+// and refuses the version if required by the version policy.
+func (f *Follower) checkVersion(version string) error {
+	if f.IndexEntry == nil {
+		return nil
+	}
+
+	notices, err := f.IndexEntry.CheckVersion(version, f.VersionPolicy, time.Now())
+	for _, n := range notices {
+		f.logger.Warn(n.Message, f.logger.Args("followerName", f.ref, "kind", string(n.Kind)))
+	}
+	if err != nil {
+		f.logger.Error("Version refused", f.logger.Args("followerName", f.ref, "reason", err.Error()))
+	}
+
+	return err
+}
+
 func (f *Follower) cleanUp() {
 	if err := os.RemoveAll(f.tmpDir); err != nil {
 		f.logger.Warn("Unable to clean working directory", f.logger.Args("followerName", f.ref, "directory", f.tmpDir, "reason", err))
@@ -16,12 +16,23 @@
 package follower
 
 import (
+	"context"
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/pterm/pterm"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes/fake"
 
+	"github.com/diginfra/diginfractl/internal/audit"
+	"github.com/diginfra/diginfractl/internal/config"
+	"github.com/diginfra/diginfractl/internal/kube"
+	"github.com/diginfra/diginfractl/internal/layout"
+	"github.com/diginfra/diginfractl/pkg/index/index"
 	"github.com/diginfra/diginfractl/pkg/oci"
 	"github.com/diginfra/diginfractl/pkg/output"
 )
@@ -135,3 +146,179 @@ func TestCheckRequirements(t *testing.T) {
 		})
 	}
 }
+
+func TestRefreshDiginfraVersions(t *testing.T) {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	artifactConfig := &oci.ArtifactConfig{
+		Name:         "my_rule",
+		Version:      "0.1.0",
+		Requirements: []oci.ArtifactRequirement{{Name: "engine_version_semver", Version: "0.27.0"}},
+	}
+
+	var refreshErr error
+	refreshed := config.DiginfraVersions{"engine_version_semver": "0.27.0"}
+	f, err := New("ghcr.io/diginfra/rules/my_rule:0.1.0", printer, &Config{
+		DiginfraVersions: config.DiginfraVersions{"engine_version_semver": "0.26.0"},
+		RefreshDiginfraVersions: func(context.Context) (config.DiginfraVersions, error) {
+			return refreshed, refreshErr
+		},
+	})
+	assert.NoError(t, err)
+	defer f.cleanUp()
+
+	assert.Error(t, f.checkRequirements(artifactConfig))
+
+	// Diginfra has been upgraded, the new versions unlock the artifact.
+	f.refreshDiginfraVersions(context.Background())
+	assert.NoError(t, f.checkRequirements(artifactConfig))
+
+	// When the endpoint is unavailable the last known versions are kept.
+	refreshed, refreshErr = nil, errors.New("connection refused")
+	f.refreshDiginfraVersions(context.Background())
+	assert.NoError(t, f.checkRequirements(artifactConfig))
+}
+
+func TestCheckVersion(t *testing.T) {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	entry := &index.Entry{
+		Name:       "my_rule",
+		Advisories: []index.Advisory{{ID: "GHSA-1234", Affected: []string{"<0.1.1"}}},
+	}
+
+	f, err := New("ghcr.io/diginfra/rules/my_rule:0.1.0", printer, &Config{IndexEntry: entry})
+	assert.NoError(t, err)
+	defer f.cleanUp()
+
+	// Vulnerable versions are installed with a warning by default.
+	assert.NoError(t, f.checkVersion("0.1.0"))
+
+	f.VersionPolicy = index.VersionPolicyRefuse
+	assert.ErrorContains(t, f.checkVersion("0.1.0"), "refusing version 0.1.0 of my_rule: version 0.1.0 of my_rule is affected by GHSA-1234")
+	assert.NoError(t, f.checkVersion("0.1.1"))
+
+	// Without an index entry there is nothing to check.
+	f.IndexEntry = nil
+	assert.NoError(t, f.checkVersion("0.1.0"))
+}
+
+func TestPauseResumeSync(t *testing.T) {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	ref := "ghcr.io/diginfra/rules/my_rule:0.1.0"
+
+	f, err := New(ref, printer, &Config{Resync: everyHour{}})
+	assert.NoError(t, err)
+	defer f.cleanUp()
+
+	st := f.Status()
+	assert.Equal(t, ref, st.Ref)
+	assert.Equal(t, "1h0m0s", st.Schedule)
+	assert.False(t, st.Paused)
+
+	f.Pause()
+	assert.True(t, f.Status().Paused)
+	assert.ErrorIs(t, f.Sync(), ErrPaused)
+
+	f.Resume()
+	assert.False(t, f.Status().Paused)
+	assert.NoError(t, f.Sync())
+	// A second request while the first one is pending must not block.
+	assert.NoError(t, f.Sync())
+	assert.Len(t, f.syncChan, 1)
+}
+
+func TestRestoreDigest(t *testing.T) {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	ref := "ghcr.io/diginfra/rules/my_rule:0.1.0"
+	dir := t.TempDir()
+	installed := filepath.Join(dir, "rules.yaml")
+	require.NoError(t, os.WriteFile(installed, []byte("rules"), 0o600))
+
+	log := audit.New(filepath.Join(dir, "audit.log"))
+	require.NoError(t, log.Record(&audit.Entry{Ref: ref, NewDigest: "sha256:old", Outcome: audit.OutcomeInstalled,
+		Files: []string{installed}}))
+	require.NoError(t, log.Record(&audit.Entry{Ref: ref, NewDigest: "sha256:failed", Outcome: audit.OutcomeFailed}))
+
+	f, err := New(ref, printer, &Config{Resync: everyHour{}, Audit: log})
+	require.NoError(t, err)
+	defer f.cleanUp()
+	assert.Equal(t, "sha256:old", f.Status().CurrentDigest)
+
+	// The recorded digest is ignored when the installed files have been removed.
+	require.NoError(t, os.Remove(installed))
+	g, err := New(ref, printer, &Config{Resync: everyHour{}, Audit: log})
+	require.NoError(t, err)
+	defer g.cleanUp()
+	assert.Empty(t, g.Status().CurrentDigest)
+}
+
+func TestDeliverKube(t *testing.T) {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	ref := "ghcr.io/diginfra/rules/my_rule:0.1.0"
+	ctx := context.Background()
+	dest, err := kube.NewDestination(fake.NewSimpleClientset(), "diginfra", "configmap/diginfra-rules")
+	require.NoError(t, err)
+
+	f, err := New(ref, printer, &Config{Resync: everyHour{}, Kube: dest})
+	require.NoError(t, err)
+	defer f.cleanUp()
+	assert.Empty(t, f.Status().CurrentDigest)
+
+	path := filepath.Join(f.tmpDir, "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("- rule: a"), 0o600))
+
+	_, _, err = f.deliver(ctx, oci.Plugin, "sha256:1", "0.1.0", []string{path})
+	assert.ErrorContains(t, err, "plugins cannot be installed")
+
+	installed, dst, err := f.deliver(ctx, oci.Rulesfile, "sha256:1", "0.1.0", []string{path})
+	assert.NoError(t, err)
+	assert.Equal(t, "configmap/diginfra/diginfra-rules", dst)
+	assert.Equal(t, []string{dest.Location("rules.yaml")}, installed)
+
+	ok, err := f.upToDate(ctx, oci.Rulesfile, []string{path})
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// A new follower picks up the digest recorded in the destination.
+	g, err := New(ref, printer, &Config{Resync: everyHour{}, Kube: dest})
+	require.NoError(t, err)
+	defer g.cleanUp()
+	assert.Equal(t, "sha256:1", g.Status().CurrentDigest)
+}
+
+func TestDeliverVersioned(t *testing.T) {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	ref := "ghcr.io/diginfra/rules/my_rule:0.1.0"
+	ctx := context.Background()
+	rulesDir := t.TempDir()
+
+	f, err := New(ref, printer, &Config{Resync: everyHour{}, RulesfilesDir: rulesDir, Versioned: true, KeepVersions: 2})
+	require.NoError(t, err)
+	defer f.cleanUp()
+
+	for _, digest := range []string{"sha256:1", "sha256:2"} {
+		path := filepath.Join(f.tmpDir, "rules.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(digest), 0o600))
+		installed, _, err := f.deliver(ctx, oci.Rulesfile, digest, "", []string{path})
+		require.NoError(t, err)
+		assert.Equal(t, []string{filepath.Join(rulesDir, "rules.yaml")}, installed)
+	}
+	content, err := os.ReadFile(filepath.Join(rulesDir, "rules.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:2", string(content))
+
+	rolledBack, err := f.rolledBack("sha256:2")
+	require.NoError(t, err)
+	assert.False(t, rolledBack)
+
+	_, _, err = layout.New(rulesDir, 2).Rollback(layout.Name(f.ref))
+	require.NoError(t, err)
+	rolledBack, err = f.rolledBack("sha256:2")
+	require.NoError(t, err)
+	assert.True(t, rolledBack)
+}
+
+type everyHour struct{}
+
+func (everyHour) Next(t time.Time) time.Time { return t.Add(time.Hour) }
+
+func (everyHour) String() string { return time.Hour.String() }
@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/diginfra/diginfractl/internal/audit"
+	"github.com/diginfra/diginfractl/internal/layout"
+	"github.com/diginfra/diginfractl/internal/utils"
+)
+
+// ErrUnhealthy is returned when Diginfra turns unhealthy after the installation of a new version.
+var ErrUnhealthy = errors.New("diginfra turned unhealthy after the update")
+
+// failedOutcome returns the outcome of an operation that returned err.
+func failedOutcome(err error) audit.Outcome {
+	if errors.Is(err, ErrUnhealthy) {
+		return audit.OutcomeUnhealthy
+	}
+	return audit.OutcomeFailed
+}
+
+// restoreUnhealthyDigest initializes the unhealthy digest with the last one recorded in the audit log,
+// so that a version that turned Diginfra unhealthy is not installed again after a restart.
+func (f *Follower) restoreUnhealthyDigest() {
+	if f.Health == nil {
+		return
+	}
+
+	last, err := f.Audit.LastOutcome(f.ref, audit.OutcomeUnhealthy)
+	if err != nil {
+		f.logger.Warn("Unable to read audit log", f.logger.Args("followerName", f.ref, "reason", err.Error()))
+		return
+	}
+	if last != nil {
+		f.unhealthyDigest = last.NewDigest
+	}
+}
+
+// prepareRestore returns a function restoring the content of dstDir as it was before the installation of
+// the files, and a function releasing the resources held to do so. With the versioned layout the previous
+// version is activated again, otherwise the files about to be overwritten are copied in the working
+// directory of the follower.
+func (f *Follower) prepareRestore(filePaths []string, dstDir string) (restore func() error, cleanUp func(), err error) {
+	if f.Versioned {
+		return func() error {
+			_, _, err := layout.New(dstDir, f.KeepVersions).Rollback(layout.Name(f.ref))
+			if errors.Is(err, layout.ErrNoPreviousVersion) {
+				return f.removeInstalled(filePaths, dstDir)
+			}
+			return err
+		}, func() {}, nil
+	}
+
+	backupDir, err := os.MkdirTemp(f.tmpDir, "backup-")
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create backup directory: %w", err)
+	}
+	cleanUp = func() { _ = os.RemoveAll(backupDir) }
+
+	// existed records, for each file, whether there was a previous version to restore.
+	existed := make(map[string]bool, len(filePaths))
+	for _, path := range filePaths {
+		name := filepath.Base(path)
+		data, err := os.ReadFile(filepath.Join(dstDir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			existed[name] = false
+			continue
+		} else if err != nil {
+			cleanUp()
+			return nil, nil, fmt.Errorf("unable to back up file %q: %w", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(backupDir, name), data, 0o600); err != nil {
+			cleanUp()
+			return nil, nil, fmt.Errorf("unable to back up file %q: %w", name, err)
+		}
+		existed[name] = true
+	}
+
+	return func() error {
+		for name, ok := range existed {
+			dstPath := filepath.Join(dstDir, name)
+			if !ok {
+				if err := os.Remove(dstPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return fmt.Errorf("unable to remove file %q: %w", dstPath, err)
+				}
+				continue
+			}
+			if err := utils.Move(filepath.Join(backupDir, name), dstPath); err != nil {
+				return fmt.Errorf("unable to restore file %q: %w", dstPath, err)
+			}
+		}
+		return nil
+	}, cleanUp, nil
+}
+
+// removeInstalled removes the files installed in dstDir, when there is no previous version to restore.
+func (f *Follower) removeInstalled(filePaths []string, dstDir string) error {
+	for _, path := range filePaths {
+		dstPath := filepath.Join(dstDir, filepath.Base(path))
+		if err := os.Remove(dstPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("unable to remove file %q: %w", dstPath, err)
+		}
+	}
+	return nil
+}
+
+// checkHealth watches Diginfra after the installation of the version with the given digest. If Diginfra
+// turns unhealthy, the previous version is restored and the digest is not installed again until it changes.
+func (f *Follower) checkHealth(ctx context.Context, digest string, installed []string, restore func() error) error {
+	f.logger.Info("Checking Diginfra health", f.logger.Args("followerName", f.ref, "probe", f.Health.String(),
+		"gracePeriod", f.Health.GracePeriod.String()))
+
+	err := f.Health.Run(ctx)
+	if err == nil {
+		f.logger.Debug("Diginfra healthy after the update", f.logger.Args("followerName", f.ref, "digest", digest))
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	f.logger.Error("Diginfra unhealthy after the update, restoring the previous version",
+		f.logger.Args("followerName", f.ref, "digest", digest, "reason", err.Error()))
+	if restoreErr := restore(); restoreErr != nil {
+		f.logger.Error("Unable to restore the previous version", f.logger.Args("followerName", f.ref,
+			"files", installed, "reason", restoreErr.Error()))
+		err = errors.Join(err, fmt.Errorf("unable to restore the previous version: %w", restoreErr))
+	}
+
+	f.mu.Lock()
+	f.unhealthyDigest = digest
+	f.mu.Unlock()
+
+	return fmt.Errorf("%w: %w", ErrUnhealthy, err)
+}
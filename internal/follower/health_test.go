@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diginfra/diginfractl/internal/audit"
+	"github.com/diginfra/diginfractl/internal/health"
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+// probeFunc adapts a function to the health.Probe interface.
+type probeFunc func() error
+
+func (p probeFunc) Check(context.Context) error { return p() }
+
+func (p probeFunc) String() string { return "func" }
+
+func newHealthFollower(t *testing.T, rulesDir string, versioned bool, probe probeFunc) *Follower {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	f, err := New("ghcr.io/diginfra/rules/my_rule:0.1.0", printer, &Config{
+		Resync:        everyHour{},
+		RulesfilesDir: rulesDir,
+		Versioned:     versioned,
+		KeepVersions:  3,
+		Health:        &health.Check{Probe: probe, FailureThreshold: 1},
+	})
+	require.NoError(t, err)
+	t.Cleanup(f.cleanUp)
+	return f
+}
+
+func TestDeliverUnhealthy(t *testing.T) {
+	ctx := context.Background()
+	healthy := probeFunc(func() error { return nil })
+	broken := probeFunc(func() error { return assert.AnError })
+
+	for _, versioned := range []bool{false, true} {
+		rulesDir := t.TempDir()
+		f := newHealthFollower(t, rulesDir, versioned, healthy)
+
+		_, _, err := f.deliver(ctx, oci.Rulesfile, "sha256:1", "", pulled(t, f, "first"))
+		require.NoError(t, err)
+
+		f.Health.Probe = broken
+		_, _, err = f.deliver(ctx, oci.Rulesfile, "sha256:2", "", append(pulled(t, f, "second"),
+			writeTmp(t, f, "extra.yaml", "extra")))
+		require.ErrorIs(t, err, ErrUnhealthy)
+		assert.Equal(t, audit.OutcomeUnhealthy, failedOutcome(err))
+		assert.Equal(t, "sha256:2", f.Status().UnhealthyDigest)
+
+		// The previous files are back, the new ones are gone.
+		content, err := os.ReadFile(filepath.Join(rulesDir, "rules.yaml"))
+		require.NoError(t, err)
+		assert.Equal(t, "first", string(content), "versioned: %v", versioned)
+		assert.NoFileExists(t, filepath.Join(rulesDir, "extra.yaml"))
+	}
+}
+
+func TestRestoreUnhealthyDigest(t *testing.T) {
+	rulesDir := t.TempDir()
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	require.NoError(t, audit.New(auditPath).Record(&audit.Entry{Operation: audit.OperationFollow,
+		Ref: "ghcr.io/diginfra/rules/my_rule:0.1.0", NewDigest: "sha256:2", Outcome: audit.OutcomeUnhealthy}))
+
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	f, err := New("ghcr.io/diginfra/rules/my_rule:0.1.0", printer, &Config{
+		Resync:        everyHour{},
+		RulesfilesDir: rulesDir,
+		Audit:         audit.New(auditPath),
+		Health:        &health.Check{Probe: probeFunc(func() error { return nil })},
+	})
+	require.NoError(t, err)
+	defer f.cleanUp()
+	assert.Equal(t, "sha256:2", f.Status().UnhealthyDigest)
+}
+
+// writeTmp writes a file in the follower working directory.
+func writeTmp(t *testing.T, f *Follower, name, content string) string {
+	path := filepath.Join(f.tmpDir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
@@ -0,0 +1,190 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pterm/pterm"
+
+	"github.com/diginfra/diginfractl/internal/audit"
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+// Scheduler runs a set of followers on a bounded pool of workers. Followers of the same
+// repository are never run at the same time, so that they do not race for the same tokens.
+type Scheduler struct {
+	followers []*Follower
+	workers   int
+	logger    *pterm.Logger
+	// wakeChan is used by the followers to notify pending requests.
+	wakeChan chan struct{}
+}
+
+// NewScheduler returns a scheduler running the followers on at most workers goroutines.
+func NewScheduler(printer *output.Printer, workers int, followers ...*Follower) *Scheduler {
+	if workers < 1 {
+		workers = 1
+	}
+
+	s := &Scheduler{
+		followers: followers,
+		workers:   workers,
+		logger:    printer.Logger,
+		wakeChan:  make(chan struct{}, 1),
+	}
+	for _, f := range followers {
+		f.notify = s.wake
+	}
+
+	return s
+}
+
+func (s *Scheduler) wake() {
+	select {
+	case s.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// Run runs the followers until ctx is done. Each follower is synced right away, then according
+// to its schedule and whenever a request is made. Before returning, Run waits for the running
+// syncs to complete and cleans up the working directories of the followers.
+func (s *Scheduler) Run(ctx context.Context) {
+	jobs := make(chan *Follower)
+	done := make(chan *Follower)
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				f.run(ctx, time.Now())
+				done <- f
+			}
+		}()
+	}
+
+	running := make(map[*Follower]bool, s.workers)
+	// busyRepos are the repositories of the running followers.
+	busyRepos := make(map[string]bool, s.workers)
+	// timer wakes the scheduler up when the next follower is due, it is reset on each iteration.
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		var next time.Time
+		now := time.Now()
+		for _, f := range s.followers {
+			if running[f] {
+				continue
+			}
+			if at := f.wakeAt(); at.After(now) {
+				if next.IsZero() || at.Before(next) {
+					next = at
+				}
+				continue
+			}
+			// The follower is due: it waits for a worker, or for the follower of the same repository, to be done.
+			if len(running) == s.workers || busyRepos[f.repo] {
+				continue
+			}
+			running[f] = true
+			busyRepos[f.repo] = true
+			jobs <- f
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		var timerC <-chan time.Time
+		if !next.IsZero() {
+			timer.Reset(time.Until(next))
+			timerC = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			close(jobs)
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+			for range done {
+				// Let the workers complete the running syncs.
+			}
+			for _, f := range s.followers {
+				f.cleanUp()
+				s.logger.Info("Follower stopped", s.logger.Args("followerName", f.ref))
+			}
+			return
+		case f := <-done:
+			delete(running, f)
+			delete(busyRepos, f.repo)
+		case <-s.wakeChan:
+		case <-timerC:
+		}
+	}
+}
+
+// RunOnce runs a single sync of each follower, at most workers at a time, and returns the audit
+// entries of each follower, by reference. As in Run, followers of the same repository are never run
+// at the same time. The followers cannot be used anymore after RunOnce returns.
+func (s *Scheduler) RunOnce(ctx context.Context) map[string][]*audit.Entry {
+	var mu sync.Mutex
+	results := make(map[string][]*audit.Entry, len(s.followers))
+	s.runEach(func(f *Follower) {
+		entries := f.RunOnce(ctx)
+		mu.Lock()
+		results[f.ref] = entries
+		mu.Unlock()
+	})
+
+	return results
+}
+
+// runEach calls run once for each follower, on at most workers goroutines and never for two
+// followers of the same repository at the same time. It returns when all the calls are done.
+func (s *Scheduler) runEach(run func(f *Follower)) {
+	pending := slices.Clone(s.followers)
+	done := make(chan *Follower)
+	// busyRepos are the repositories of the running followers.
+	busyRepos := make(map[string]bool, s.workers)
+	for running := 0; len(pending) > 0 || running > 0; running-- {
+		waiting := pending[:0]
+		for _, f := range pending {
+			if running == s.workers || busyRepos[f.repo] {
+				waiting = append(waiting, f)
+				continue
+			}
+			running++
+			busyRepos[f.repo] = true
+			go func() {
+				run(f)
+				done <- f
+			}()
+		}
+		pending = waiting
+
+		f := <-done
+		delete(busyRepos, f.repo)
+	}
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diginfra/diginfractl/internal/audit"
+	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+// unreachableRefs point to a registry that refuses the connections, so that each sync fails right away.
+var unreachableRefs = []string{
+	"localhost:1/diginfra/rules/first:0",
+	"localhost:1/diginfra/rules/second:0",
+	"localhost:1/diginfra/rules/second:1",
+}
+
+func newFollowers(t *testing.T) []*Follower {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	client, err := ociutils.Client(true)
+	require.NoError(t, err)
+
+	followers := make([]*Follower, 0, len(unreachableRefs))
+	for _, ref := range unreachableRefs {
+		f, err := New(ref, printer, &Config{Resync: everyHour{}, PlainHTTP: true, Client: client})
+		require.NoError(t, err)
+		followers = append(followers, f)
+	}
+	return followers
+}
+
+func TestSchedulerRun(t *testing.T) {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	followers := newFollowers(t)
+	s := NewScheduler(printer, 2, followers...)
+	assert.Same(t, followers[0].Puller.Client, followers[1].Puller.Client)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	// All the followers are synced right away, then scheduled for the next hour.
+	require.Eventually(t, func() bool {
+		for _, f := range followers {
+			if f.Status().LastSync.IsZero() {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+	for _, f := range followers {
+		st := f.Status()
+		assert.NotEmpty(t, st.LastError)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), st.NextRun, time.Minute)
+	}
+
+	// A requested sync wakes up the scheduler.
+	lastSync := followers[0].Status().LastSync
+	assert.NoError(t, followers[0].Sync())
+	assert.Eventually(t, func() bool {
+		return followers[0].Status().LastSync.After(lastSync)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+	for _, f := range followers {
+		_, err := os.Stat(f.tmpDir)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	}
+}
+
+func TestSchedulerRunOnce(t *testing.T) {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	followers := newFollowers(t)
+
+	results := NewScheduler(printer, 1, followers...).RunOnce(context.Background())
+	assert.Len(t, results, len(unreachableRefs))
+	for _, ref := range unreachableRefs {
+		require.Len(t, results[ref], 1)
+		assert.Equal(t, audit.OutcomeFailed, results[ref][0].Outcome)
+	}
+}
+
+func TestSchedulerRunEachSerializesRepositories(t *testing.T) {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	followers := newFollowers(t)
+	for _, f := range followers {
+		defer f.cleanUp()
+	}
+
+	var (
+		mu        sync.Mutex
+		running   = make(map[string]int)
+		overlaps  int
+		maxActive int
+		active    int
+		runs      int
+	)
+	NewScheduler(printer, 3, followers...).runEach(func(f *Follower) {
+		mu.Lock()
+		running[f.repo]++
+		if running[f.repo] > 1 {
+			overlaps++
+		}
+		active++
+		maxActive = max(maxActive, active)
+		runs++
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		running[f.repo]--
+		active--
+		mu.Unlock()
+	})
+
+	assert.Equal(t, len(followers), runs)
+	// The two followers of the second repository never run together, the first one runs alongside.
+	assert.Zero(t, overlaps)
+	assert.Equal(t, 2, maxActive)
+}
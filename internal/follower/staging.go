@@ -0,0 +1,373 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/diginfra/diginfractl/internal/audit"
+	"github.com/diginfra/diginfractl/internal/lockedfile"
+	"github.com/diginfra/diginfractl/internal/utils"
+	"github.com/diginfra/diginfractl/pkg/oci"
+)
+
+const (
+	// ApproveMarker is the name of the file that, once created in the staging directory of
+	// a follower, approves the staged version.
+	ApproveMarker = "approved"
+	// RejectMarker is the name of the file that, once created in the staging directory of
+	// a follower, rejects the staged version.
+	RejectMarker = "rejected"
+
+	stagedMetadataFile = "staged.json"
+	stagedFilesDir     = "files"
+	// stagingPollInterval is how often the staging directory is checked for markers
+	// and expired promotion delays.
+	stagingPollInterval = 10 * time.Second
+)
+
+var (
+	// ErrNothingStaged is returned when an approval or a rejection is requested but
+	// no version is waiting in the staging directory.
+	ErrNothingStaged = errors.New("no staged version waiting for approval")
+
+	unsafeDirChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+)
+
+// stagedArtifact is the metadata of an artifact version waiting for approval.
+// It is persisted in the staging directory so that pending approvals survive restarts.
+type stagedArtifact struct {
+	Ref      string           `json:"ref"`
+	Digest   string           `json:"digest"`
+	Type     oci.ArtifactType `json:"type"`
+	Version  string           `json:"version,omitempty"`
+	Signer   string           `json:"signer,omitempty"`
+	Files    []string         `json:"files"`
+	StagedAt time.Time        `json:"stagedAt"`
+	Rejected bool             `json:"rejected,omitempty"`
+}
+
+// Approve requests the installation of the staged version. The request is served
+// asynchronously by the Scheduler running the follower. It returns ErrNothingStaged if
+// no version is waiting for approval.
+func (f *Follower) Approve() error {
+	if !f.hasStaged() {
+		return ErrNothingStaged
+	}
+
+	select {
+	case f.approveChan <- struct{}{}:
+	default:
+	}
+	f.wake()
+
+	return nil
+}
+
+// Reject requests the removal of the staged version, which will not be staged again.
+// The request is served asynchronously by the Scheduler running the follower. It returns
+// ErrNothingStaged if no version is waiting for approval.
+func (f *Follower) Reject() error {
+	if !f.hasStaged() {
+		return ErrNothingStaged
+	}
+
+	select {
+	case f.rejectChan <- struct{}{}:
+	default:
+	}
+	f.wake()
+
+	return nil
+}
+
+func (f *Follower) hasStaged() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.staged != nil
+}
+
+// stagingEnabled returns true if new versions must be approved before being installed.
+func (f *Follower) stagingEnabled() bool {
+	return f.StagingDir != ""
+}
+
+// stageDir returns the staging directory of the follower.
+func (f *Follower) stageDir() string {
+	return filepath.Join(f.StagingDir, unsafeDirChars.ReplaceAllString(f.ref, "_"))
+}
+
+// loadStaged restores the staged state persisted by a previous run, if any.
+func (f *Follower) loadStaged() error {
+	data, err := os.ReadFile(filepath.Join(f.stageDir(), stagedMetadataFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to read staged metadata: %w", err)
+	}
+
+	var s stagedArtifact
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("unable to decode staged metadata: %w", err)
+	}
+
+	if s.Rejected {
+		f.rejectedDigest = s.Digest
+		return nil
+	}
+	f.staged = &s
+
+	return nil
+}
+
+func (f *Follower) saveStaged(s *stagedArtifact) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return lockedfile.WriteFile(filepath.Join(f.stageDir(), stagedMetadataFile), data, 0o600)
+}
+
+// stage moves the pulled files in the staging directory, where they wait for approval.
+func (f *Follower) stage(ctx context.Context, entry *audit.Entry, res *oci.RegistryResult, filePaths []string) error {
+	digest := entry.NewDigest
+
+	// If the pulled files are already installed there is nothing to approve, e.g. after a restart.
+	installed, err := f.upToDate(ctx, res.Type, filePaths)
+	if err != nil {
+		return err
+	}
+	if installed {
+		f.logger.Info("Artifact already installed, nothing to stage", f.logger.Args("followerName", f.ref, "digest", digest))
+		f.mu.Lock()
+		f.currentDigest = digest
+		f.mu.Unlock()
+		entry.Outcome = audit.OutcomeSkipped
+		return nil
+	}
+
+	dir := f.stageDir()
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("unable to clean staging directory %q: %w", dir, err)
+	}
+	filesDir := filepath.Join(dir, stagedFilesDir)
+	if err := os.MkdirAll(filesDir, 0o750); err != nil {
+		return fmt.Errorf("unable to create staging directory %q: %w", dir, err)
+	}
+
+	s := &stagedArtifact{
+		Ref:      f.ref,
+		Digest:   digest,
+		Type:     res.Type,
+		Version:  entry.Version,
+		Signer:   entry.Signer,
+		StagedAt: time.Now(),
+	}
+	for _, path := range filePaths {
+		baseName := filepath.Base(path)
+		if err := utils.Move(path, filepath.Join(filesDir, baseName)); err != nil {
+			return fmt.Errorf("unable to stage file %q: %w", path, err)
+		}
+		s.Files = append(s.Files, baseName)
+	}
+
+	if err := f.saveStaged(s); err != nil {
+		return fmt.Errorf("unable to save staged metadata: %w", err)
+	}
+
+	f.mu.Lock()
+	f.staged = s
+	f.mu.Unlock()
+	entry.Files = s.Files
+	entry.Outcome = audit.OutcomeStaged
+
+	args := []interface{}{"followerName", f.ref, "digest", digest, "directory", dir}
+	if f.PromoteAfter > 0 {
+		args = append(args, "promoteAt", s.StagedAt.Add(f.PromoteAfter).Format(time.RFC3339))
+	}
+	f.logger.Info("Artifact staged, waiting for approval", f.logger.Args(args...))
+
+	return nil
+}
+
+// checkStaged promotes or rejects the staged version according to the marker files
+// and the promotion delay. It returns the audit entry of the decision, if any was taken.
+func (f *Follower) checkStaged(ctx context.Context) (*audit.Entry, error) {
+	f.mu.Lock()
+	s := f.staged
+	f.mu.Unlock()
+	if s == nil {
+		return nil, nil
+	}
+
+	dir := f.stageDir()
+	if ok, err := utils.FileExists(filepath.Join(dir, RejectMarker)); err != nil {
+		return nil, err
+	} else if ok {
+		return f.reject()
+	}
+
+	if ok, err := utils.FileExists(filepath.Join(dir, ApproveMarker)); err != nil {
+		return nil, err
+	} else if ok {
+		return f.promote(ctx)
+	}
+
+	if f.PromoteAfter > 0 && time.Since(s.StagedAt) >= f.PromoteAfter {
+		f.logger.Info("Promotion delay expired", f.logger.Args("followerName", f.ref, "digest", s.Digest))
+		return f.promote(ctx)
+	}
+
+	return nil, nil
+}
+
+// promote installs the staged version and returns the related audit entry.
+func (f *Follower) promote(ctx context.Context) (*audit.Entry, error) {
+	f.mu.Lock()
+	s := f.staged
+	f.mu.Unlock()
+	if s == nil {
+		return nil, ErrNothingStaged
+	}
+
+	entry := f.stagedAuditEntry(s)
+	err := f.doPromote(ctx, s, entry)
+	if err != nil {
+		entry.Outcome = failedOutcome(err)
+		entry.Error = err.Error()
+	}
+	f.audit(entry)
+
+	return entry, err
+}
+
+func (f *Follower) doPromote(ctx context.Context, s *stagedArtifact, entry *audit.Entry) error {
+	dir := f.stageDir()
+	filePaths := make([]string, 0, len(s.Files))
+	for _, name := range s.Files {
+		filePaths = append(filePaths, filepath.Join(dir, stagedFilesDir, name))
+	}
+
+	installed, dst, err := f.deliver(ctx, s.Type, s.Digest, s.Version, filePaths)
+	if errors.Is(err, ErrUnhealthy) {
+		// The previous version has been restored, there is nothing left to approve.
+		if err := os.RemoveAll(dir); err != nil {
+			f.logger.Warn("Unable to clean staging directory", f.logger.Args("followerName", f.ref, "directory", dir, "reason", err.Error()))
+		}
+		f.mu.Lock()
+		f.staged = nil
+		f.mu.Unlock()
+	}
+	if err != nil {
+		return err
+	}
+	entry.Files = installed
+	entry.SetInstalled()
+
+	if err := os.RemoveAll(dir); err != nil {
+		f.logger.Warn("Unable to clean staging directory", f.logger.Args("followerName", f.ref, "directory", dir, "reason", err.Error()))
+	}
+
+	f.mu.Lock()
+	f.currentDigest = s.Digest
+	f.staged = nil
+	f.lastErr = nil
+	f.mu.Unlock()
+
+	f.logger.Info("Staged artifact promoted",
+		f.logger.Args("followerName", f.ref, "type", s.Type, "digest", s.Digest, "destination", dst))
+
+	return nil
+}
+
+// reject discards the staged version and returns the related audit entry. The rejection
+// is persisted so that the same version is not staged again.
+func (f *Follower) reject() (*audit.Entry, error) {
+	f.mu.Lock()
+	s := f.staged
+	f.mu.Unlock()
+	if s == nil {
+		return nil, ErrNothingStaged
+	}
+
+	dir := f.stageDir()
+	if err := os.RemoveAll(dir); err != nil {
+		return nil, fmt.Errorf("unable to clean staging directory %q: %w", dir, err)
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("unable to create staging directory %q: %w", dir, err)
+	}
+
+	rejected := *s
+	rejected.Files = nil
+	rejected.Rejected = true
+	if err := f.saveStaged(&rejected); err != nil {
+		return nil, fmt.Errorf("unable to save staged metadata: %w", err)
+	}
+
+	f.mu.Lock()
+	f.rejectedDigest = s.Digest
+	f.staged = nil
+	f.lastErr = nil
+	f.mu.Unlock()
+
+	f.logger.Info("Staged artifact rejected", f.logger.Args("followerName", f.ref, "digest", s.Digest))
+	entry := f.stagedAuditEntry(s)
+	entry.Outcome = audit.OutcomeRejected
+	f.audit(entry)
+
+	return entry, nil
+}
+
+// stagedAuditEntry returns a new audit entry for the staged version.
+func (f *Follower) stagedAuditEntry(s *stagedArtifact) *audit.Entry {
+	entry := f.auditEntry()
+	entry.NewDigest = s.Digest
+	entry.Version = s.Version
+	entry.Signer = s.Signer
+	return entry
+}
+
+// installed returns true if all the files are already present, with the same content, in dstDir.
+func (f *Follower) installed(filePaths []string, dstDir string) (bool, error) {
+	for _, path := range filePaths {
+		dstPath := filepath.Join(dstDir, filepath.Base(path))
+		exists, err := utils.FileExists(dstPath)
+		if err != nil {
+			return false, fmt.Errorf("unable to check existence for file %q: %w", dstPath, err)
+		}
+		if !exists {
+			return false, nil
+		}
+		eq, err := equal([]string{path, dstPath})
+		if err != nil {
+			return false, fmt.Errorf("unable to compare files: %w", err)
+		}
+		if !eq {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diginfra/diginfractl/internal/audit"
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+const stagingRef = "ghcr.io/diginfra/rules/my_rule:0.1.0"
+
+func newStagingFollower(t *testing.T, stagingDir, rulesDir string, promoteAfter time.Duration) *Follower {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	f, err := New(stagingRef, printer, &Config{
+		Resync:        everyHour{},
+		RulesfilesDir: rulesDir,
+		StagingDir:    stagingDir,
+		PromoteAfter:  promoteAfter,
+	})
+	require.NoError(t, err)
+	t.Cleanup(f.cleanUp)
+	return f
+}
+
+// pulled simulates a pulled artifact by writing a rules file in the follower working directory.
+func pulled(t *testing.T, f *Follower, content string) []string {
+	path := filepath.Join(f.tmpDir, "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return []string{path}
+}
+
+func TestStageAndApprove(t *testing.T) {
+	stagingDir, rulesDir := t.TempDir(), t.TempDir()
+	f := newStagingFollower(t, stagingDir, rulesDir, 0)
+	res := &oci.RegistryResult{Type: oci.Rulesfile}
+
+	assert.ErrorIs(t, f.Approve(), ErrNothingStaged)
+	assert.ErrorIs(t, f.Reject(), ErrNothingStaged)
+
+	require.NoError(t, f.stage(context.Background(), &audit.Entry{NewDigest: "sha256:first"}, res, pulled(t, f, "first")))
+	assert.FileExists(t, filepath.Join(f.stageDir(), stagedFilesDir, "rules.yaml"))
+	assert.NoFileExists(t, filepath.Join(rulesDir, "rules.yaml"))
+	assert.Equal(t, "sha256:first", f.Status().StagedDigest)
+
+	// Without approval nothing happens.
+	_, err := f.checkStaged(context.Background())
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(rulesDir, "rules.yaml"))
+
+	assert.NoError(t, f.Approve())
+	assert.Len(t, f.approveChan, 1)
+	_, err = f.promote(context.Background())
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(rulesDir, "rules.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(content))
+	st := f.Status()
+	assert.Equal(t, "sha256:first", st.CurrentDigest)
+	assert.Empty(t, st.StagedDigest)
+	assert.NoDirExists(t, f.stageDir())
+
+	// The same content is already installed, there is nothing to approve.
+	require.NoError(t, f.stage(context.Background(), &audit.Entry{NewDigest: "sha256:same"}, res, pulled(t, f, "first")))
+	assert.Equal(t, "sha256:same", f.Status().CurrentDigest)
+	assert.Empty(t, f.Status().StagedDigest)
+}
+
+func TestStageMarkers(t *testing.T) {
+	stagingDir, rulesDir := t.TempDir(), t.TempDir()
+	f := newStagingFollower(t, stagingDir, rulesDir, 0)
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	f.Audit = audit.New(auditPath)
+	res := &oci.RegistryResult{Type: oci.Rulesfile}
+
+	require.NoError(t, f.stage(context.Background(), &audit.Entry{NewDigest: "sha256:first"}, res, pulled(t, f, "first")))
+	require.NoError(t, os.WriteFile(filepath.Join(f.stageDir(), ApproveMarker), nil, 0o600))
+	_, err := f.checkStaged(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:first", f.Status().CurrentDigest)
+
+	require.NoError(t, f.stage(context.Background(), &audit.Entry{NewDigest: "sha256:second"}, res, pulled(t, f, "second")))
+	require.NoError(t, os.WriteFile(filepath.Join(f.stageDir(), RejectMarker), nil, 0o600))
+	_, err = f.checkStaged(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:first", f.Status().CurrentDigest)
+	assert.Empty(t, f.Status().StagedDigest)
+	assert.Equal(t, "sha256:second", f.rejectedDigest)
+
+	content, err := os.ReadFile(filepath.Join(rulesDir, "rules.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(content))
+
+	entries, err := audit.Read(auditPath)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, audit.OutcomeInstalled, entries[0].Outcome)
+	assert.Equal(t, []string{filepath.Join(rulesDir, "rules.yaml")}, entries[0].Files)
+	assert.Equal(t, audit.OutcomeRejected, entries[1].Outcome)
+	assert.Equal(t, "sha256:first", entries[1].OldDigest)
+	assert.Equal(t, "sha256:second", entries[1].NewDigest)
+
+	// The rejection survives a restart.
+	restarted := newStagingFollower(t, stagingDir, rulesDir, 0)
+	assert.Equal(t, "sha256:second", restarted.rejectedDigest)
+	assert.Nil(t, restarted.staged)
+}
+
+func TestStagePromoteAfter(t *testing.T) {
+	stagingDir, rulesDir := t.TempDir(), t.TempDir()
+	f := newStagingFollower(t, stagingDir, rulesDir, time.Hour)
+	res := &oci.RegistryResult{Type: oci.Rulesfile}
+
+	require.NoError(t, f.stage(context.Background(), &audit.Entry{NewDigest: "sha256:first"}, res, pulled(t, f, "first")))
+
+	// The pending approval survives a restart.
+	restarted := newStagingFollower(t, stagingDir, rulesDir, time.Hour)
+	require.NotNil(t, restarted.staged)
+	assert.Equal(t, "sha256:first", restarted.Status().StagedDigest)
+
+	_, err := restarted.checkStaged(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, restarted.Status().CurrentDigest)
+
+	// Pretend the version has been staged long enough.
+	restarted.staged.StagedAt = time.Now().Add(-2 * time.Hour)
+	_, err = restarted.checkStaged(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:first", restarted.Status().CurrentDigest)
+	assert.FileExists(t, filepath.Join(rulesDir, "rules.yaml"))
+}
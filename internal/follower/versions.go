@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+
+	"github.com/diginfra/diginfractl/internal/config"
+)
+
+// versionsRetryAfter is how long a failed fetch of the Diginfra versions is shared before fetching them again.
+const versionsRetryAfter = 5 * time.Second
+
+// VersionsCache shares the Diginfra versions between the followers, so that the versions endpoint
+// is queried at most once per interval however many followers check for new versions.
+type VersionsCache struct {
+	fetch      func(ctx context.Context) (config.DiginfraVersions, error)
+	interval   time.Duration
+	retryAfter time.Duration
+
+	mu        sync.Mutex
+	fetchedAt time.Time
+	versions  config.DiginfraVersions
+	err       error
+}
+
+// NewVersionsCache returns a cache calling fetch to get the Diginfra versions, at most once per interval.
+func NewVersionsCache(fetch func(ctx context.Context) (config.DiginfraVersions, error), interval time.Duration) *VersionsCache {
+	return &VersionsCache{fetch: fetch, interval: interval, retryAfter: versionsRetryAfter}
+}
+
+// Get returns the Diginfra versions, fetching them again if the last fetch is older than the interval.
+// Concurrent callers wait for the same fetch. A failed fetch is only shared for a few seconds, so that the
+// followers waiting for it do not query the endpoint again one after the other, while the versions are
+// fetched again as soon as the endpoint is back instead of at the next interval.
+func (c *VersionsCache) Get(ctx context.Context) (config.DiginfraVersions, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ttl := c.interval
+	if c.err != nil {
+		ttl = min(ttl, c.retryAfter)
+	}
+	if c.fetchedAt.IsZero() || time.Since(c.fetchedAt) >= ttl {
+		c.versions, c.err = c.fetch(ctx)
+		c.fetchedAt = time.Now()
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return maps.Clone(c.versions), nil
+}
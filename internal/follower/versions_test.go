@@ -0,0 +1,87 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package follower
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diginfra/diginfractl/internal/config"
+)
+
+func TestVersionsCache(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	var fetchErr error
+	cache := NewVersionsCache(func(context.Context) (config.DiginfraVersions, error) {
+		calls++
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return config.DiginfraVersions{"engine_version_semver": "0.27.0"}, nil
+	}, time.Hour)
+
+	// The versions are fetched once and shared.
+	for i := 0; i < 3; i++ {
+		versions, err := cache.Get(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "0.27.0", versions["engine_version_semver"])
+	}
+	assert.Equal(t, 1, calls)
+
+	// Failures are only shared for a short time.
+	fetchErr = errors.New("connection refused")
+	cache.interval = 0
+	_, err := cache.Get(ctx)
+	assert.ErrorIs(t, err, fetchErr)
+	cache.interval = time.Hour
+	_, err = cache.Get(ctx)
+	assert.ErrorIs(t, err, fetchErr)
+	assert.Equal(t, 2, calls)
+}
+
+func TestVersionsCacheRetriesFailures(t *testing.T) {
+	ctx := context.Background()
+	calls := 0
+	fetchErr := errors.New("connection refused")
+	cache := NewVersionsCache(func(context.Context) (config.DiginfraVersions, error) {
+		calls++
+		if fetchErr != nil {
+			return nil, fetchErr
+		}
+		return config.DiginfraVersions{"engine_version_semver": "0.28.0"}, nil
+	}, time.Hour)
+	cache.retryAfter = 0
+
+	_, err := cache.Get(ctx)
+	assert.ErrorIs(t, err, fetchErr)
+
+	// The endpoint is back within the interval: the versions are fetched again.
+	fetchErr = nil
+	versions, err := cache.Get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "0.28.0", versions["engine_version_semver"])
+	assert.Equal(t, 2, calls)
+
+	// Successful fetches are shared for the whole interval.
+	_, err = cache.Get(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
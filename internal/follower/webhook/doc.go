@@ -0,0 +1,23 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook implements an HTTP receiver for registry notifications. When a push event
+// matches the repository of a followed artifact, the related follower is asked to sync right away
+// instead of waiting for its next scheduled run.
+//
+// Two payload formats are supported:
+//   - the CNCF distribution notification envelope, served under DistributionPath;
+//   - a generic JSON payload signed with a shared-secret HMAC, served under GenericPath.
+package webhook
@@ -0,0 +1,262 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/diginfra/diginfractl/internal/follower"
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+const (
+	// DistributionPath is the endpoint receiving CNCF distribution notification envelopes.
+	DistributionPath = "/webhook/distribution"
+	// GenericPath is the endpoint receiving generic JSON payloads.
+	GenericPath = "/webhook/generic"
+	// SignatureHeader is the header carrying the HMAC of generic payloads, formatted as "sha256=<hex digest>".
+	SignatureHeader = "X-Diginfractl-Signature"
+
+	signaturePrefix = "sha256="
+	pushAction      = "push"
+	maxBodySize     = 1 << 20
+	shutdownTimeout = 5 * time.Second
+)
+
+// Target is implemented by the objects that can be synced when a matching event is received.
+type Target interface {
+	Ref() string
+	Sync() error
+}
+
+// Envelope is the CNCF distribution notification envelope.
+type Envelope struct {
+	Events []Event `json:"events"`
+}
+
+// Event is a single event of a CNCF distribution notification envelope.
+// Only the fields needed to match a followed repository are decoded.
+type Event struct {
+	Action string `json:"action"`
+	Target struct {
+		Repository string `json:"repository"`
+		Tag        string `json:"tag,omitempty"`
+		URL        string `json:"url,omitempty"`
+	} `json:"target"`
+	Request struct {
+		Host string `json:"host,omitempty"`
+	} `json:"request"`
+}
+
+// GenericEvent is the generic JSON payload.
+type GenericEvent struct {
+	Registry   string `json:"registry,omitempty"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+}
+
+// Receiver handles registry notifications for a set of targets.
+type Receiver struct {
+	targets []Target
+	secret  []byte
+	printer *output.Printer
+}
+
+// NewReceiver returns a new Receiver for the given targets. The secret is used to verify the HMAC
+// of generic payloads and, if set, the bearer token sent along distribution notifications.
+func NewReceiver(printer *output.Printer, secret string, targets ...Target) *Receiver {
+	return &Receiver{
+		targets: targets,
+		secret:  []byte(secret),
+		printer: printer,
+	}
+}
+
+// Handler returns the http.Handler serving the webhook endpoints.
+func (rc *Receiver) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST "+DistributionPath, rc.handleDistribution)
+	mux.HandleFunc("POST "+GenericPath, rc.handleGeneric)
+	return mux
+}
+
+// ListenAndServe listens on addr and serves the webhook endpoints until the context is canceled.
+func (rc *Receiver) ListenAndServe(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           rc.Handler(),
+		ReadHeaderTimeout: shutdownTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	rc.printer.Logger.Info("Serving registry webhooks", rc.printer.Logger.Args("address", addr))
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("webhook server failed: %w", err)
+	}
+
+	return nil
+}
+
+func (rc *Receiver) handleDistribution(w http.ResponseWriter, r *http.Request) {
+	if len(rc.secret) > 0 {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), rc.secret) != 1 {
+			http.Error(w, "invalid credentials", http.StatusUnauthorized)
+			return
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		http.Error(w, fmt.Sprintf("unable to decode envelope: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	for i := range envelope.Events {
+		ev := &envelope.Events[i]
+		// Manifests pushed by digest, such as the ones of the platforms of an image index, are followed
+		// by the push of a tag.
+		if ev.Action != pushAction || ev.Target.Tag == "" {
+			continue
+		}
+		rc.trigger(eventRegistry(ev), ev.Target.Repository, ev.Target.Tag)
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (rc *Receiver) handleGeneric(w http.ResponseWriter, r *http.Request) {
+	if len(rc.secret) == 0 {
+		http.Error(w, "generic webhooks require a shared secret to be configured", http.StatusForbidden)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "unable to read body", http.StatusBadRequest)
+		return
+	}
+
+	if !rc.validSignature(body, r.Header.Get(SignatureHeader)) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	var ev GenericEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		http.Error(w, fmt.Sprintf("unable to decode payload: %s", err), http.StatusBadRequest)
+		return
+	}
+	if ev.Repository == "" {
+		http.Error(w, "missing repository", http.StatusBadRequest)
+		return
+	}
+
+	rc.trigger(ev.Registry, ev.Repository, ev.Tag)
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// validSignature checks that header carries the HMAC-SHA256 of body computed with the shared secret.
+func (rc *Receiver) validSignature(body []byte, header string) bool {
+	if !strings.HasPrefix(header, signaturePrefix) {
+		return false
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(header, signaturePrefix))
+	if err != nil {
+		return false
+	}
+
+	return hmac.Equal(got, Sign(rc.secret, body))
+}
+
+// trigger syncs all the targets following the tag of reg/repo. An empty reg matches any registry and
+// an empty tag any tag. Targets following a digest never need to be synced.
+func (rc *Receiver) trigger(reg, repo, tag string) {
+	logger := rc.printer.Logger
+	matched := false
+
+	for _, t := range rc.targets {
+		ref, err := registry.ParseReference(t.Ref())
+		if err != nil {
+			continue
+		}
+		if ref.Repository != repo || (reg != "" && ref.Registry != reg) {
+			continue
+		}
+		if ref.ValidateReferenceAsDigest() == nil || (tag != "" && ref.ReferenceOrDefault() != tag) {
+			continue
+		}
+
+		matched = true
+		logger.Info("Push event received, syncing follower", logger.Args("followerName", t.Ref()))
+		if err := t.Sync(); err != nil {
+			if errors.Is(err, follower.ErrPaused) {
+				logger.Info("Follower paused, ignoring push event", logger.Args("followerName", t.Ref()))
+				continue
+			}
+			logger.Warn("Unable to sync follower", logger.Args("followerName", t.Ref(), "reason", err.Error()))
+		}
+	}
+
+	if !matched {
+		logger.Debug("Push event does not match any follower", logger.Args("registry", reg, "repository", repo, "tag", tag))
+	}
+}
+
+// eventRegistry extracts the registry host from a distribution event. The host of the target URL,
+// built from the address the registry is configured with, is preferred to the host used by the
+// pusher, which may have reached the registry through another name.
+func eventRegistry(ev *Event) string {
+	if u, err := url.Parse(ev.Target.URL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return ev.Request.Host
+}
+
+// Sign returns the HMAC-SHA256 of body computed with secret.
+func Sign(secret, body []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/pterm/pterm"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+type fakeTarget struct {
+	ref   string
+	syncs int
+}
+
+func (f *fakeTarget) Ref() string { return f.ref }
+
+func (f *fakeTarget) Sync() error {
+	f.syncs++
+	return nil
+}
+
+func post(h http.Handler, path string, body []byte, headers map[string]string) int {
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestDistributionEnvelope(t *testing.T) {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	rules := &fakeTarget{ref: "ghcr.io/diginfra/rules/diginfra-rules:3"}
+	plugin := &fakeTarget{ref: "ghcr.io/diginfra/plugins/k8saudit:0"}
+	pinned := &fakeTarget{ref: "ghcr.io/diginfra/rules/diginfra-rules@sha256:" + strings.Repeat("a", 64)}
+	h := NewReceiver(printer, "s3cret", rules, plugin, pinned).Handler()
+
+	envelope := []byte(`{"events":[
+		{"action":"pull","target":{"repository":"diginfra/plugins/k8saudit","tag":"0"},"request":{"host":"ghcr.io"}},
+		{"action":"push","target":{"repository":"diginfra/rules/diginfra-rules","tag":"3.1.0"},"request":{"host":"ghcr.io"}},
+		{"action":"push","target":{"repository":"diginfra/rules/diginfra-rules","tag":"3"},"request":{"host":"ghcr.io"}},
+		{"action":"push","target":{"repository":"diginfra/rules/diginfra-rules","tag":"sha256-` + strings.Repeat("b", 64) + `.sig"},"request":{"host":"ghcr.io"}},
+		{"action":"push","target":{"repository":"diginfra/rules/diginfra-rules"},"request":{"host":"ghcr.io"}},
+		{"action":"push","target":{"repository":"diginfra/rules/diginfra-rules","tag":"3"},"request":{"host":"other.io"}}
+	]}`)
+
+	assert.Equal(t, http.StatusUnauthorized, post(h, DistributionPath, envelope, nil))
+	assert.Equal(t, 0, rules.syncs)
+
+	// Only the push of the followed tag syncs the follower.
+	auth := map[string]string{"Authorization": "Bearer s3cret"}
+	assert.Equal(t, http.StatusAccepted, post(h, DistributionPath, envelope, auth))
+	assert.Equal(t, 1, rules.syncs)
+	assert.Equal(t, 0, plugin.syncs)
+	assert.Equal(t, 0, pinned.syncs)
+
+	// The registry of the target URL is preferred to the host used by the pusher.
+	envelope = []byte(`{"events":[
+		{"action":"push","target":{"repository":"diginfra/rules/diginfra-rules","tag":"3",` +
+		`"url":"https://ghcr.io/v2/diginfra/rules/diginfra-rules/manifests/sha256:` + strings.Repeat("c", 64) + `"},` +
+		`"request":{"host":"mirror.example.com"}}
+	]}`)
+	assert.Equal(t, http.StatusAccepted, post(h, DistributionPath, envelope, auth))
+	assert.Equal(t, 2, rules.syncs)
+
+	assert.Equal(t, http.StatusBadRequest, post(h, DistributionPath, []byte("{"), auth))
+}
+
+func TestGenericPayload(t *testing.T) {
+	printer := output.NewPrinter(pterm.LogLevelDebug, pterm.LogFormatterJSON, os.Stdout)
+	rules := &fakeTarget{ref: "ghcr.io/diginfra/rules/diginfra-rules:3"}
+	body := []byte(`{"repository":"diginfra/rules/diginfra-rules","tag":"3"}`)
+	signature := signaturePrefix + hex.EncodeToString(Sign([]byte("s3cret"), body))
+
+	// Generic payloads are refused when no secret is configured.
+	h := NewReceiver(printer, "", rules).Handler()
+	assert.Equal(t, http.StatusForbidden, post(h, GenericPath, body, map[string]string{SignatureHeader: signature}))
+
+	h = NewReceiver(printer, "s3cret", rules).Handler()
+	assert.Equal(t, http.StatusUnauthorized, post(h, GenericPath, body, nil))
+	assert.Equal(t, http.StatusUnauthorized, post(h, GenericPath, body, map[string]string{SignatureHeader: "sha256=00"}))
+	assert.Equal(t, 0, rules.syncs)
+
+	assert.Equal(t, http.StatusAccepted, post(h, GenericPath, body, map[string]string{SignatureHeader: signature}))
+	assert.Equal(t, 1, rules.syncs)
+
+	missing := []byte(`{"tag":"3.1.0"}`)
+	assert.Equal(t, http.StatusBadRequest,
+		post(h, GenericPath, missing, map[string]string{SignatureHeader: signaturePrefix + hex.EncodeToString(Sign([]byte("s3cret"), missing))}))
+}
@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultGracePeriod is the default time during which Diginfra is watched after an update.
+	DefaultGracePeriod = 30 * time.Second
+	// DefaultInterval is the default time between two probes.
+	DefaultInterval = 2 * time.Second
+	// DefaultFailureThreshold is the default number of consecutive failures after which Diginfra is unhealthy.
+	DefaultFailureThreshold = 3
+
+	// VersionProbe is the probe specification checking the Diginfra versions endpoint.
+	VersionProbe = "version"
+	// ExecPrefix is the prefix of the probe specifications running a command.
+	ExecPrefix = "exec:"
+
+	probeTimeout = 10 * time.Second
+)
+
+// Probe checks whether Diginfra is healthy.
+type Probe interface {
+	// Check returns an error if Diginfra is not healthy.
+	Check(ctx context.Context) error
+	// String returns a human-readable description of the probe.
+	String() string
+}
+
+// ParseProbe returns the probe described by spec, which is either:
+//   - an "http://" or "https://" URL, healthy when it answers with a 2xx status;
+//   - "version", healthy when the Diginfra versions endpoint at versionsURL answers with the versions;
+//   - "exec:<command>", healthy when the command, run by "sh -c", exits with status 0.
+func ParseProbe(spec, versionsURL string) (Probe, error) {
+	switch {
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"):
+		return &httpProbe{url: spec}, nil
+	case spec == VersionProbe:
+		if versionsURL == "" {
+			return nil, errors.New("the version probe requires the Diginfra versions endpoint")
+		}
+		return &httpProbe{url: versionsURL, versions: true}, nil
+	case strings.HasPrefix(spec, ExecPrefix) && strings.TrimPrefix(spec, ExecPrefix) != "":
+		return &execProbe{command: strings.TrimPrefix(spec, ExecPrefix)}, nil
+	default:
+		return nil, fmt.Errorf("invalid health probe %q, it must be an HTTP URL, %q or %q followed by a command",
+			spec, VersionProbe, ExecPrefix)
+	}
+}
+
+// httpProbe checks an HTTP endpoint. If versions is true, the body must be a non-empty JSON object.
+type httpProbe struct {
+	url      string
+	versions bool
+}
+
+func (p *httpProbe) Check(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %q from %q", resp.Status, p.url)
+	}
+	if !p.versions {
+		return nil
+	}
+
+	var versions map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return fmt.Errorf("unable to decode versions from %q: %w", p.url, err)
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("no versions returned by %q", p.url)
+	}
+
+	return nil
+}
+
+func (p *httpProbe) String() string {
+	if p.versions {
+		return VersionProbe + " " + p.url
+	}
+	return p.url
+}
+
+// execProbe runs a command.
+type execProbe struct {
+	command string
+}
+
+func (p *execProbe) Check(ctx context.Context) error {
+	//nolint:gosec // the command is given by the user on purpose
+	out, err := exec.CommandContext(ctx, "sh", "-c", p.command).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (p *execProbe) String() string {
+	return ExecPrefix + p.command
+}
+
+// Check watches Diginfra through a probe for a grace period.
+type Check struct {
+	Probe
+	// GracePeriod is how long Diginfra is watched.
+	GracePeriod time.Duration
+	// Interval is the time between two probes.
+	Interval time.Duration
+	// FailureThreshold is the number of consecutive failures after which Diginfra is unhealthy.
+	FailureThreshold int
+}
+
+// Run probes Diginfra every Interval until the grace period expires. It returns the error of the
+// last probe if FailureThreshold consecutive probes fail, or if the probe is still failing when the
+// grace period expires.
+func (c *Check) Run(ctx context.Context) error {
+	threshold := max(c.FailureThreshold, 1)
+	interval := c.Interval
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	deadline := time.Now().Add(c.GracePeriod)
+	failures := 0
+
+	for {
+		probeCtx, cancel := context.WithTimeout(ctx, probeTimeout)
+		err := c.Probe.Check(probeCtx)
+		cancel()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			failures++
+			if failures >= threshold {
+				return err
+			}
+		} else {
+			failures = 0
+		}
+
+		if !time.Now().Before(deadline) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(min(interval, time.Until(deadline))):
+		}
+	}
+}
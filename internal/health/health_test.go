@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProbe(t *testing.T) {
+	p, err := ParseProbe("http://localhost:8765/healthz", "")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8765/healthz", p.String())
+
+	p, err = ParseProbe(VersionProbe, "http://localhost:8765/versions")
+	require.NoError(t, err)
+	assert.Equal(t, "version http://localhost:8765/versions", p.String())
+
+	p, err = ParseProbe("exec:pgrep diginfra", "")
+	require.NoError(t, err)
+	assert.Equal(t, "exec:pgrep diginfra", p.String())
+
+	_, err = ParseProbe(VersionProbe, "")
+	assert.Error(t, err)
+	_, err = ParseProbe("exec:", "")
+	assert.Error(t, err)
+	_, err = ParseProbe("localhost:8765", "")
+	assert.Error(t, err)
+}
+
+func TestProbes(t *testing.T) {
+	ctx := context.Background()
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+		if r.URL.Path == "/versions" {
+			_, _ = w.Write([]byte(`{"diginfra_version": "0.38.0"}`))
+		} else {
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	assert.NoError(t, (&httpProbe{url: server.URL + "/healthz"}).Check(ctx))
+	assert.NoError(t, (&httpProbe{url: server.URL + "/versions", versions: true}).Check(ctx))
+	assert.ErrorContains(t, (&httpProbe{url: server.URL + "/healthz", versions: true}).Check(ctx), "no versions")
+
+	status.Store(http.StatusServiceUnavailable)
+	assert.ErrorContains(t, (&httpProbe{url: server.URL + "/healthz"}).Check(ctx), "503")
+
+	assert.NoError(t, (&execProbe{command: "true"}).Check(ctx))
+	assert.ErrorContains(t, (&execProbe{command: "echo broken; false"}).Check(ctx), "broken")
+}
+
+// probeFunc adapts a function to the Probe interface.
+type probeFunc func() error
+
+func (p probeFunc) Check(context.Context) error { return p() }
+
+func (p probeFunc) String() string { return "func" }
+
+func TestCheckRun(t *testing.T) {
+	ctx := context.Background()
+	healthy := probeFunc(func() error { return nil })
+	broken := probeFunc(func() error { return assert.AnError })
+
+	c := &Check{Probe: healthy, GracePeriod: 20 * time.Millisecond, Interval: 5 * time.Millisecond, FailureThreshold: 3}
+	assert.NoError(t, c.Run(ctx))
+
+	c.Probe = broken
+	c.GracePeriod = time.Hour
+	assert.ErrorIs(t, c.Run(ctx), assert.AnError)
+
+	// A single failure is tolerated, as long as the probe recovers.
+	var calls atomic.Int32
+	c.Probe = probeFunc(func() error {
+		if calls.Add(1) == 1 {
+			return assert.AnError
+		}
+		return nil
+	})
+	c.GracePeriod = 20 * time.Millisecond
+	assert.NoError(t, c.Run(ctx))
+
+	// The probe must not be failing when the grace period expires.
+	c.Probe = broken
+	c.GracePeriod = 0
+	assert.ErrorIs(t, c.Run(ctx), assert.AnError)
+}
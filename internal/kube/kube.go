@@ -0,0 +1,373 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+)
+
+// Kind is the kind of the Kubernetes object where the artifacts are stored.
+type Kind string
+
+const (
+	// ConfigMap stores the artifacts in a ConfigMap.
+	ConfigMap Kind = "configmap"
+	// Secret stores the artifacts in a Secret.
+	Secret Kind = "secret"
+
+	// ArtifactsAnnotation is the annotation recording, for each reference stored in the object,
+	// the digest and the version of the installed artifact.
+	ArtifactsAnnotation = "diginfractl.diginfra.org/artifacts"
+	// ManagedByLabel is the label set on the objects created by diginfractl.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+
+	managedByValue = "diginfractl"
+)
+
+// ArtifactInfo is the metadata recorded in the ArtifactsAnnotation for an installed artifact.
+type ArtifactInfo struct {
+	Digest  string   `json:"digest"`
+	Version string   `json:"version,omitempty"`
+	Keys    []string `json:"keys,omitempty"`
+}
+
+// NewClientset returns a Kubernetes clientset built from the given kubeconfig file or,
+// if empty, from the in-cluster configuration.
+func NewClientset(kubeconfig string) (kubernetes.Interface, error) {
+	var (
+		err error
+		cfg *rest.Config
+	)
+
+	if kubeconfig != "" {
+		cfg, err = clientcmd.BuildConfigFromFlags("", kubeconfig)
+	} else {
+		cfg, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return kubernetes.NewForConfig(cfg)
+}
+
+// Destination is a ConfigMap or a Secret where the files of the artifacts are stored, one key per file.
+type Destination struct {
+	client    kubernetes.Interface
+	namespace string
+	kind      Kind
+	name      string
+}
+
+// ParseDestination parses a destination in the "<kind>/<name>" format, where kind is either
+// "configmap" or "secret".
+func ParseDestination(dest string) (Kind, string, error) {
+	kind, name, ok := strings.Cut(dest, "/")
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid destination %q: must be in the format configmap/<name> or secret/<name>", dest)
+	}
+
+	switch k := Kind(strings.ToLower(kind)); k {
+	case ConfigMap, Secret:
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return "", "", fmt.Errorf("invalid destination name %q: %s", name, strings.Join(errs, ", "))
+		}
+		return k, name, nil
+	default:
+		return "", "", fmt.Errorf("invalid destination kind %q: must be %q or %q", kind, ConfigMap, Secret)
+	}
+}
+
+// NewDestination returns the destination described by dest, in the "<kind>/<name>" format, in the given namespace.
+func NewDestination(client kubernetes.Interface, namespace, dest string) (*Destination, error) {
+	kind, name, err := ParseDestination(dest)
+	if err != nil {
+		return nil, err
+	}
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	return &Destination{client: client, namespace: namespace, kind: kind, name: name}, nil
+}
+
+// String returns the destination in the "<kind>/<namespace>/<name>" format.
+func (d *Destination) String() string {
+	return fmt.Sprintf("%s/%s/%s", d.kind, d.namespace, d.name)
+}
+
+// Location returns the location of a key of the destination, as recorded in the audit log.
+func (d *Destination) Location(key string) string {
+	return d.String() + "/" + key
+}
+
+// object is the common view over ConfigMaps and Secrets.
+type object struct {
+	meta *metav1.ObjectMeta
+	data map[string][]byte
+	// set stores the content of a key in the underlying object.
+	set func(key string, content []byte)
+	// remove deletes a key from the underlying object.
+	remove func(key string)
+}
+
+func (d *Destination) wrap(obj interface{}) *object {
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		data := make(map[string][]byte, len(o.Data)+len(o.BinaryData))
+		for k, v := range o.Data {
+			data[k] = []byte(v)
+		}
+		for k, v := range o.BinaryData {
+			data[k] = v
+		}
+		return &object{meta: &o.ObjectMeta, data: data, set: func(key string, content []byte) {
+			// Text goes in Data so that the ConfigMap stays readable, anything else in BinaryData.
+			if utf8.Valid(content) {
+				if o.Data == nil {
+					o.Data = make(map[string]string)
+				}
+				o.Data[key] = string(content)
+				delete(o.BinaryData, key)
+				return
+			}
+			if o.BinaryData == nil {
+				o.BinaryData = make(map[string][]byte)
+			}
+			o.BinaryData[key] = content
+			delete(o.Data, key)
+		}, remove: func(key string) {
+			delete(o.Data, key)
+			delete(o.BinaryData, key)
+		}}
+	case *corev1.Secret:
+		if o.Data == nil {
+			o.Data = make(map[string][]byte)
+		}
+		return &object{meta: &o.ObjectMeta, data: o.Data, set: func(key string, content []byte) {
+			o.Data[key] = content
+		}, remove: func(key string) {
+			delete(o.Data, key)
+		}}
+	}
+	return nil
+}
+
+// get returns the destination object, or a new one if it does not exist yet.
+func (d *Destination) get(ctx context.Context) (obj interface{}, exists bool, err error) {
+	meta := metav1.ObjectMeta{
+		Name:      d.name,
+		Namespace: d.namespace,
+		Labels:    map[string]string{ManagedByLabel: managedByValue},
+	}
+
+	switch d.kind {
+	case ConfigMap:
+		obj, err = d.client.CoreV1().ConfigMaps(d.namespace).Get(ctx, d.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return &corev1.ConfigMap{ObjectMeta: meta}, false, nil
+		}
+	case Secret:
+		obj, err = d.client.CoreV1().Secrets(d.namespace).Get(ctx, d.name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return &corev1.Secret{ObjectMeta: meta, Type: corev1.SecretTypeOpaque}, false, nil
+		}
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("unable to get %s: %w", d, err)
+	}
+
+	return obj, true, nil
+}
+
+func (d *Destination) save(ctx context.Context, obj interface{}, exists bool) error {
+	var err error
+	switch o := obj.(type) {
+	case *corev1.ConfigMap:
+		if exists {
+			_, err = d.client.CoreV1().ConfigMaps(d.namespace).Update(ctx, o, metav1.UpdateOptions{})
+		} else {
+			_, err = d.client.CoreV1().ConfigMaps(d.namespace).Create(ctx, o, metav1.CreateOptions{})
+		}
+	case *corev1.Secret:
+		if exists {
+			_, err = d.client.CoreV1().Secrets(d.namespace).Update(ctx, o, metav1.UpdateOptions{})
+		} else {
+			_, err = d.client.CoreV1().Secrets(d.namespace).Create(ctx, o, metav1.CreateOptions{})
+		}
+	}
+	return err
+}
+
+// Write stores the files as keys of the destination, named after their base names, and records the
+// digest and the version of the artifact in the ArtifactsAnnotation. The object is created if needed.
+// The keys of the previous version of the artifact that are not part of the new one are removed, while
+// files sharing a base name, or stored under a key owned by another artifact, are refused.
+// It returns the locations of the keys whose content changed or that were removed.
+func (d *Destination) Write(ctx context.Context, ref, digest, version string, filePaths []string) ([]string, error) {
+	contents := make(map[string][]byte, len(filePaths))
+	keys := make([]string, 0, len(filePaths))
+	sources := make(map[string]string, len(filePaths))
+	for _, path := range filePaths {
+		key := filepath.Base(path)
+		if prev, ok := sources[key]; ok {
+			return nil, fmt.Errorf("files %q and %q would both be stored as key %q in %s", prev, path, key, d)
+		}
+		sources[key] = path
+		if errs := validation.IsConfigMapKey(key); len(errs) > 0 {
+			return nil, fmt.Errorf("file %q cannot be stored in %s: %s", path, d, strings.Join(errs, ", "))
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read file %q: %w", path, err)
+		}
+		contents[key] = content
+		keys = append(keys, key)
+	}
+
+	var written []string
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		written = nil
+		obj, exists, err := d.get(ctx)
+		if err != nil {
+			return err
+		}
+		o := d.wrap(obj)
+
+		artifacts, err := decodeArtifacts(o.meta.Annotations)
+		if err != nil {
+			return err
+		}
+		for other, otherInfo := range artifacts {
+			if other == ref {
+				continue
+			}
+			for _, key := range keys {
+				if slices.Contains(otherInfo.Keys, key) {
+					return fmt.Errorf("file %q cannot be stored in %s: key %q belongs to %q", sources[key], d, key, other)
+				}
+			}
+		}
+
+		for _, key := range keys {
+			if old, ok := o.data[key]; ok && string(old) == string(contents[key]) {
+				continue
+			}
+			o.set(key, contents[key])
+			written = append(written, d.Location(key))
+		}
+		for _, key := range artifacts[ref].Keys {
+			if _, ok := contents[key]; ok {
+				continue
+			}
+			if _, ok := o.data[key]; ok {
+				o.remove(key)
+				written = append(written, d.Location(key))
+			}
+		}
+
+		info := ArtifactInfo{Digest: digest, Version: version, Keys: keys}
+		if len(written) == 0 && exists && equalInfo(artifacts[ref], info) {
+			return nil
+		}
+		artifacts[ref] = info
+		annotation, err := json.Marshal(artifacts)
+		if err != nil {
+			return err
+		}
+		if o.meta.Annotations == nil {
+			o.meta.Annotations = make(map[string]string)
+		}
+		o.meta.Annotations[ArtifactsAnnotation] = string(annotation)
+
+		return d.save(ctx, obj, exists)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to write %s: %w", d, err)
+	}
+
+	return written, nil
+}
+
+// Equal returns true if all the files are already stored in the destination with the same content.
+func (d *Destination) Equal(ctx context.Context, filePaths []string) (bool, error) {
+	obj, exists, err := d.get(ctx)
+	if err != nil || !exists {
+		return false, err
+	}
+	o := d.wrap(obj)
+
+	for _, path := range filePaths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return false, fmt.Errorf("unable to read file %q: %w", path, err)
+		}
+		if old, ok := o.data[filepath.Base(path)]; !ok || string(old) != string(content) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// Artifact returns the metadata recorded in the destination for ref, or nil if ref has never been stored there.
+func (d *Destination) Artifact(ctx context.Context, ref string) (*ArtifactInfo, error) {
+	obj, exists, err := d.get(ctx)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	artifacts, err := decodeArtifacts(d.wrap(obj).meta.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	info, ok := artifacts[ref]
+	if !ok {
+		return nil, nil
+	}
+
+	return &info, nil
+}
+
+func decodeArtifacts(annotations map[string]string) (map[string]ArtifactInfo, error) {
+	artifacts := make(map[string]ArtifactInfo)
+	if val, ok := annotations[ArtifactsAnnotation]; ok && val != "" {
+		if err := json.Unmarshal([]byte(val), &artifacts); err != nil {
+			return nil, fmt.Errorf("unable to decode annotation %q: %w", ArtifactsAnnotation, err)
+		}
+	}
+	return artifacts, nil
+}
+
+func equalInfo(a, b ArtifactInfo) bool {
+	return a.Digest == b.Digest && a.Version == b.Version && slices.Equal(a.Keys, b.Keys)
+}
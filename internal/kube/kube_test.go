@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testRef = "ghcr.io/diginfra/rules/my_rule:0.1.0"
+
+func writeFile(t *testing.T, dir, name string, content []byte) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, content, 0o600))
+	return path
+}
+
+func TestParseDestination(t *testing.T) {
+	kind, name, err := ParseDestination("ConfigMap/diginfra-rules")
+	assert.NoError(t, err)
+	assert.Equal(t, ConfigMap, kind)
+	assert.Equal(t, "diginfra-rules", name)
+
+	kind, _, err = ParseDestination("secret/diginfra-rules")
+	assert.NoError(t, err)
+	assert.Equal(t, Secret, kind)
+
+	for _, dest := range []string{"diginfra-rules", "configmap/", "pod/diginfra", "configmap/Not_Valid"} {
+		_, _, err = ParseDestination(dest)
+		assert.Error(t, err, dest)
+	}
+}
+
+func TestWriteConfigMap(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	dir := t.TempDir()
+	rules := writeFile(t, dir, "rules.yaml", []byte("- rule: a"))
+	binary := writeFile(t, dir, "data.bin", []byte{0xff, 0xfe})
+
+	d, err := NewDestination(client, "", "configmap/diginfra-rules")
+	require.NoError(t, err)
+	assert.Equal(t, "configmap/default/diginfra-rules", d.String())
+
+	info, err := d.Artifact(ctx, testRef)
+	assert.NoError(t, err)
+	assert.Nil(t, info)
+
+	written, err := d.Write(ctx, testRef, "sha256:1", "0.1.0", []string{rules, binary})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{d.Location("rules.yaml"), d.Location("data.bin")}, written)
+
+	cm, err := client.CoreV1().ConfigMaps("default").Get(ctx, "diginfra-rules", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "- rule: a", cm.Data["rules.yaml"])
+	assert.Equal(t, []byte{0xff, 0xfe}, cm.BinaryData["data.bin"])
+	assert.Equal(t, managedByValue, cm.Labels[ManagedByLabel])
+
+	info, err = d.Artifact(ctx, testRef)
+	assert.NoError(t, err)
+	assert.Equal(t, &ArtifactInfo{Digest: "sha256:1", Version: "0.1.0", Keys: []string{"rules.yaml", "data.bin"}}, info)
+
+	eq, err := d.Equal(ctx, []string{rules})
+	assert.NoError(t, err)
+	assert.True(t, eq)
+
+	// Unchanged content is not written again.
+	written, err = d.Write(ctx, testRef, "sha256:1", "0.1.0", []string{rules, binary})
+	assert.NoError(t, err)
+	assert.Empty(t, written)
+
+	rules = writeFile(t, dir, "rules.yaml", []byte("- rule: b"))
+	eq, err = d.Equal(ctx, []string{rules})
+	assert.NoError(t, err)
+	assert.False(t, eq)
+
+	written, err = d.Write(ctx, testRef, "sha256:2", "0.2.0", []string{rules})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{d.Location("rules.yaml"), d.Location("data.bin")}, written)
+
+	// Other artifacts share the same object without overwriting each other's metadata.
+	other := writeFile(t, dir, "other.yaml", []byte("- rule: c"))
+	_, err = d.Write(ctx, "ghcr.io/diginfra/rules/other:1", "sha256:3", "1.0.0", []string{other})
+	assert.NoError(t, err)
+
+	info, err = d.Artifact(ctx, testRef)
+	assert.NoError(t, err)
+	assert.Equal(t, "sha256:2", info.Digest)
+	assert.Equal(t, "0.2.0", info.Version)
+}
+
+func TestWriteSecret(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	rules := writeFile(t, t.TempDir(), "rules.yaml", []byte("- rule: a"))
+
+	d, err := NewDestination(client, "diginfra", "secret/diginfra-rules")
+	require.NoError(t, err)
+
+	_, err = d.Write(ctx, testRef, "sha256:1", "0.1.0", []string{rules})
+	assert.NoError(t, err)
+
+	s, err := client.CoreV1().Secrets("diginfra").Get(ctx, "diginfra-rules", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("- rule: a"), s.Data["rules.yaml"])
+	assert.Contains(t, s.Annotations[ArtifactsAnnotation], "sha256:1")
+}
+
+func TestWriteRemovesStaleKeys(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	dir := t.TempDir()
+	oldRules := writeFile(t, dir, "old_rules.yaml", []byte("- rule: a"))
+	rules := writeFile(t, dir, "rules.yaml", []byte("- rule: b"))
+
+	d, err := NewDestination(client, "", "configmap/diginfra-rules")
+	require.NoError(t, err)
+
+	_, err = d.Write(ctx, testRef, "sha256:1", "0.1.0", []string{oldRules, rules})
+	require.NoError(t, err)
+
+	// The new version renamed old_rules.yaml, which must not be loaded anymore.
+	renamed := writeFile(t, dir, "new_rules.yaml", []byte("- rule: a"))
+	written, err := d.Write(ctx, testRef, "sha256:2", "0.2.0", []string{renamed, rules})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{d.Location("new_rules.yaml"), d.Location("old_rules.yaml")}, written)
+
+	cm, err := client.CoreV1().ConfigMaps("default").Get(ctx, "diginfra-rules", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, cm.Data, "old_rules.yaml")
+	assert.Equal(t, "- rule: a", cm.Data["new_rules.yaml"])
+	assert.Equal(t, "- rule: b", cm.Data["rules.yaml"])
+
+	info, err := d.Artifact(ctx, testRef)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"new_rules.yaml", "rules.yaml"}, info.Keys)
+}
+
+func TestWriteRefusesKeyClashes(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	dir := t.TempDir()
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o700))
+	rules := writeFile(t, dir, "rules.yaml", []byte("- rule: a"))
+	nested := writeFile(t, filepath.Join(dir, "sub"), "rules.yaml", []byte("- rule: b"))
+
+	d, err := NewDestination(client, "diginfra", "secret/diginfra-rules")
+	require.NoError(t, err)
+
+	// Two files of the same artifact flattened to the same key.
+	_, err = d.Write(ctx, testRef, "sha256:1", "0.1.0", []string{rules, nested})
+	assert.ErrorContains(t, err, `would both be stored as key "rules.yaml"`)
+	_, err = client.CoreV1().Secrets("diginfra").Get(ctx, "diginfra-rules", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	// A key already owned by another artifact.
+	_, err = d.Write(ctx, testRef, "sha256:1", "0.1.0", []string{rules})
+	require.NoError(t, err)
+	_, err = d.Write(ctx, "ghcr.io/diginfra/rules/other:1", "sha256:2", "1.0.0", []string{nested})
+	assert.ErrorContains(t, err, `key "rules.yaml" belongs to "`+testRef+`"`)
+
+	s, err := client.CoreV1().Secrets("diginfra").Get(ctx, "diginfra-rules", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("- rule: a"), s.Data["rules.yaml"])
+	assert.NotContains(t, s.Annotations[ArtifactsAnnotation], "other")
+}
@@ -0,0 +1,415 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/diginfra/diginfractl/internal/lockedfile"
+)
+
+const (
+	// VersionsDir is the directory, relative to an install directory, where the versions are stored.
+	VersionsDir = ".versions"
+	// DefaultKeep is the default number of versions kept for each artifact.
+	DefaultKeep = 3
+
+	currentLink  = "current"
+	metadataFile = "versions.json"
+	tmpPrefix    = ".tmp-"
+	digestLength = 12
+)
+
+var (
+	// ErrNotInstalled is returned when no version of an artifact has been installed with the versioned layout.
+	ErrNotInstalled = errors.New("no version installed with the versioned layout")
+	// ErrNoPreviousVersion is returned when there is no version to roll back to.
+	ErrNoPreviousVersion = errors.New("no previous version to roll back to")
+
+	unsafeDirChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+)
+
+// Version is an installed version of an artifact.
+type Version struct {
+	// Dir is the name of the directory holding the version.
+	Dir string `json:"dir"`
+	// Ref is the reference the version has been installed from.
+	Ref string `json:"ref,omitempty"`
+	// Digest is the digest of the version.
+	Digest string `json:"digest"`
+	// Version is the version declared in the artifact config, if any.
+	Version string `json:"version,omitempty"`
+	// Files are the top level entries of the version, linked in the install directory.
+	Files []string `json:"files"`
+	// InstalledAt is when the version has been installed.
+	InstalledAt time.Time `json:"installedAt"`
+	// RolledBack is true if the version has been rolled back. It is not reinstalled by followers.
+	RolledBack bool `json:"rolledBack,omitempty"`
+}
+
+// History lists the installed versions of an artifact, from the oldest to the newest installed.
+type History struct {
+	// Current is the directory of the active version.
+	Current string `json:"current"`
+	// Versions are the installed versions.
+	Versions []Version `json:"versions"`
+}
+
+// CurrentVersion returns the active version, or nil if there is none.
+func (h *History) CurrentVersion() *Version {
+	if i := h.index(h.Current); i >= 0 {
+		return &h.Versions[i]
+	}
+	return nil
+}
+
+func (h *History) index(dir string) int {
+	return slices.IndexFunc(h.Versions, func(v Version) bool { return v.Dir == dir })
+}
+
+// prune drops the oldest versions exceeding keep, except the active one, and returns them.
+func (h *History) prune(keep int) []Version {
+	if keep <= 0 || len(h.Versions) <= keep {
+		return nil
+	}
+
+	excess := len(h.Versions) - keep
+	var kept, pruned []Version
+	for _, v := range h.Versions {
+		if excess > 0 && v.Dir != h.Current {
+			pruned = append(pruned, v)
+			excess--
+			continue
+		}
+		kept = append(kept, v)
+	}
+	h.Versions = kept
+
+	return pruned
+}
+
+// Versioned installs artifacts in a directory with the versioned layout: each version is stored in
+// <dir>/.versions/<name>/<version>-<digest>/ and each of its top level entries is linked in <dir>
+// through the <dir>/.versions/<name>/current symlink. Activating a version only replaces that symlink
+// with an atomic rename, so that readers of <dir> never see a partially updated artifact.
+type Versioned struct {
+	dir  string
+	keep int
+}
+
+// New returns a Versioned layout rooted at dir, keeping the last keep versions of each artifact.
+// All versions are kept if keep is not positive.
+func New(dir string, keep int) *Versioned {
+	return &Versioned{dir: dir, keep: keep}
+}
+
+// Dir returns the install directory.
+func (v *Versioned) Dir() string {
+	return v.dir
+}
+
+// Name returns the name under which the versions of the artifact referenced by ref are stored: its
+// registry and repository, with the slashes replaced by "+" and the port separator by "_", so that
+// artifacts with the same name in different repositories never share their versions.
+func Name(ref string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return strings.NewReplacer(":", "_", "/", "+").Replace(ref)
+}
+
+func (v *Versioned) base(name string) string {
+	return filepath.Join(v.dir, VersionsDir, name)
+}
+
+// Names returns the names of the artifacts installed in the directory with the versioned layout.
+func (v *Versioned) Names() ([]string, error) {
+	des, err := os.ReadDir(filepath.Join(v.dir, VersionsDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read versions directory of %q: %w", v.dir, err)
+	}
+
+	var names []string
+	for _, de := range des {
+		if de.IsDir() {
+			names = append(names, de.Name())
+		}
+	}
+
+	return names, nil
+}
+
+// Installed returns true if at least a version of the artifact has been installed.
+func (v *Versioned) Installed(name string) (bool, error) {
+	h, err := v.History(name)
+	if err != nil {
+		return false, err
+	}
+	return len(h.Versions) > 0, nil
+}
+
+// History returns the installed versions of the artifact. It is empty if none has been installed.
+func (v *Versioned) History(name string) (*History, error) {
+	data, err := os.ReadFile(filepath.Join(v.base(name), metadataFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return &History{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read versions of %q: %w", name, err)
+	}
+
+	var h History
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("unable to decode versions of %q: %w", name, err)
+	}
+
+	return &h, nil
+}
+
+func (v *Versioned) save(name string, h *History) error {
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(v.base(name), metadataFile)
+	if err := lockedfile.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("unable to write versions of %q: %w", name, err)
+	}
+
+	return nil
+}
+
+// Install stores a new version of the artifact and activates it. The fill function is called to
+// populate the directory of the version, unless the same version is already stored. The oldest
+// versions exceeding the configured number are removed. It returns the paths of the links
+// created in the install directory.
+func (v *Versioned) Install(name string, ver Version, fill func(dir string) error) ([]string, error) {
+	base := v.base(name)
+	if err := os.MkdirAll(base, 0o750); err != nil {
+		return nil, fmt.Errorf("unable to create versions directory %q: %w", base, err)
+	}
+
+	h, err := v.History(name)
+	if err != nil {
+		return nil, err
+	}
+
+	ver.Dir = dirName(ver.Version, ver.Digest)
+	ver.InstalledAt = time.Now()
+	ver.RolledBack = false
+	versionDir := filepath.Join(base, ver.Dir)
+
+	if _, err := os.Stat(versionDir); errors.Is(err, os.ErrNotExist) {
+		// Populate a temporary directory first, so that a failure never leaves a partial version behind.
+		tmp, err := os.MkdirTemp(base, tmpPrefix)
+		if err != nil {
+			return nil, fmt.Errorf("unable to create temporary directory: %w", err)
+		}
+		if err := fill(tmp); err != nil {
+			_ = os.RemoveAll(tmp)
+			return nil, err
+		}
+		if err := os.Chmod(tmp, 0o750); err != nil {
+			_ = os.RemoveAll(tmp)
+			return nil, err
+		}
+		if err := os.Rename(tmp, versionDir); err != nil {
+			_ = os.RemoveAll(tmp)
+			return nil, fmt.Errorf("unable to store version in %q: %w", versionDir, err)
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if ver.Files, err = entries(versionDir); err != nil {
+		return nil, err
+	}
+
+	links, err := v.activate(name, h.CurrentVersion(), &ver)
+	if err != nil {
+		return nil, err
+	}
+
+	if i := h.index(ver.Dir); i >= 0 {
+		h.Versions = slices.Delete(h.Versions, i, i+1)
+	}
+	h.Versions = append(h.Versions, ver)
+	h.Current = ver.Dir
+	pruned := h.prune(v.keep)
+	if err := v.save(name, h); err != nil {
+		return nil, err
+	}
+
+	for _, p := range pruned {
+		if err := os.RemoveAll(filepath.Join(base, p.Dir)); err != nil {
+			return nil, fmt.Errorf("unable to remove old version %q: %w", p.Dir, err)
+		}
+	}
+
+	return links, nil
+}
+
+// Rollback activates the version installed before the current one, skipping the versions already
+// rolled back, and marks the current one as rolled back. It returns the deactivated and the activated versions.
+func (v *Versioned) Rollback(name string) (from, to *Version, err error) {
+	h, err := v.History(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(h.Versions) == 0 {
+		return nil, nil, fmt.Errorf("%w for %q in %q", ErrNotInstalled, name, v.dir)
+	}
+
+	i := h.index(h.Current)
+	j := i - 1
+	for j >= 0 && h.Versions[j].RolledBack {
+		j--
+	}
+	if i < 0 || j < 0 {
+		return nil, nil, fmt.Errorf("%w for %q in %q", ErrNoPreviousVersion, name, v.dir)
+	}
+
+	from, to = &h.Versions[i], &h.Versions[j]
+	if _, err := v.activate(name, from, to); err != nil {
+		return nil, nil, err
+	}
+	from.RolledBack = true
+	h.Current = to.Dir
+	if err := v.save(name, h); err != nil {
+		return nil, nil, err
+	}
+
+	return from, to, nil
+}
+
+// RolledBack returns true if the version with the given digest has been rolled back.
+func (v *Versioned) RolledBack(name, digest string) (bool, error) {
+	h, err := v.History(name)
+	if err != nil {
+		return false, err
+	}
+
+	return slices.ContainsFunc(h.Versions, func(ver Version) bool {
+		return ver.RolledBack && ver.Digest == digest
+	}), nil
+}
+
+// activate points the current symlink to the next version and makes sure that each of its entries
+// is linked in the install directory. The links of the previous version's entries that no longer exist
+// are removed.
+func (v *Versioned) activate(name string, prev, next *Version) ([]string, error) {
+	if err := replaceSymlink(next.Dir, filepath.Join(v.base(name), currentLink)); err != nil {
+		return nil, fmt.Errorf("unable to activate version %q: %w", next.Dir, err)
+	}
+
+	links := make([]string, 0, len(next.Files))
+	for _, file := range next.Files {
+		link := filepath.Join(v.dir, file)
+		if err := replaceSymlink(v.linkTarget(name, file), link); err != nil {
+			return nil, fmt.Errorf("unable to link %q: %w", link, err)
+		}
+		links = append(links, link)
+	}
+
+	if prev == nil {
+		return links, nil
+	}
+	for _, file := range prev.Files {
+		if slices.Contains(next.Files, file) {
+			continue
+		}
+		link := filepath.Join(v.dir, file)
+		if target, err := os.Readlink(link); err == nil && target == v.linkTarget(name, file) {
+			if err := os.Remove(link); err != nil {
+				return nil, fmt.Errorf("unable to remove stale link %q: %w", link, err)
+			}
+		}
+	}
+
+	return links, nil
+}
+
+// linkTarget returns the target, relative to the install directory, of the link to a file of the artifact.
+func (v *Versioned) linkTarget(name, file string) string {
+	return filepath.Join(VersionsDir, name, currentLink, file)
+}
+
+// replaceSymlink atomically replaces link with a symlink to target.
+func replaceSymlink(target, link string) error {
+	if current, err := os.Readlink(link); err == nil && current == target {
+		return nil
+	}
+	if info, err := os.Lstat(link); err == nil && info.IsDir() {
+		return fmt.Errorf("%q is a directory", link)
+	}
+
+	tmp := filepath.Join(filepath.Dir(link), tmpPrefix+filepath.Base(link))
+	if err := os.Remove(tmp); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Symlink(target, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
+
+// entries returns the sorted names of the top level entries of dir.
+func entries(dir string) ([]string, error) {
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read version directory %q: %w", dir, err)
+	}
+
+	names := make([]string, 0, len(des))
+	for _, de := range des {
+		names = append(names, de.Name())
+	}
+
+	return names, nil
+}
+
+// dirName returns the name of the directory of a version, in the <version>-<digest> format.
+func dirName(version, digest string) string {
+	hex := digest
+	if i := strings.Index(hex, ":"); i >= 0 {
+		hex = hex[i+1:]
+	}
+	if len(hex) > digestLength {
+		hex = hex[:digestLength]
+	}
+	if version == "" {
+		return unsafeDirChars.ReplaceAllString(hex, "_")
+	}
+	return unsafeDirChars.ReplaceAllString(version+"-"+hex, "_")
+}
@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package layout
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// write returns a fill function writing the given files.
+func write(files map[string]string) func(dir string) error {
+	return func(dir string) error {
+		for name, content := range files {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func read(t *testing.T, path string) string {
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "ghcr.io+diginfra+rules+diginfra-rules", Name("ghcr.io/diginfra/rules/diginfra-rules:3"))
+	assert.Equal(t, "ghcr.io+diginfra+rules+diginfra-rules", Name("ghcr.io/diginfra/rules/diginfra-rules@sha256:abc"))
+	assert.Equal(t, "localhost_5000+my-rules", Name("localhost:5000/my-rules"))
+	assert.Equal(t, "localhost_5000+my-rules", Name("localhost:5000/my-rules:latest"))
+	// Artifacts with the same name in different repositories do not share their versions.
+	assert.NotEqual(t, Name("ghcr.io/diginfra/rules/foo:1"), Name("registry.example.com/myorg/foo:1"))
+}
+
+func TestInstallAndRollback(t *testing.T) {
+	dir := t.TempDir()
+	v := New(dir, DefaultKeep)
+
+	links, err := v.Install("rules", Version{Digest: "sha256:111111111111111111", Version: "1.0.0"},
+		write(map[string]string{"rules.yaml": "v1", "old.yaml": "old"}))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{filepath.Join(dir, "rules.yaml"), filepath.Join(dir, "old.yaml")}, links)
+	assert.DirExists(t, filepath.Join(dir, VersionsDir, "rules", "1.0.0-111111111111"))
+	assert.Equal(t, "v1", read(t, filepath.Join(dir, "rules.yaml")))
+
+	names, err := v.Names()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rules"}, names)
+
+	_, err = v.Install("rules", Version{Digest: "sha256:222222222222222222", Version: "2.0.0"},
+		write(map[string]string{"rules.yaml": "v2"}))
+	require.NoError(t, err)
+	assert.Equal(t, "v2", read(t, filepath.Join(dir, "rules.yaml")))
+	// Files no longer part of the artifact are unlinked.
+	assert.NoFileExists(t, filepath.Join(dir, "old.yaml"))
+
+	from, to, err := v.Rollback("rules")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:222222222222222222", from.Digest)
+	assert.Equal(t, "sha256:111111111111111111", to.Digest)
+	assert.Equal(t, "v1", read(t, filepath.Join(dir, "rules.yaml")))
+	assert.Equal(t, "old", read(t, filepath.Join(dir, "old.yaml")))
+
+	rolledBack, err := v.RolledBack("rules", "sha256:222222222222222222")
+	require.NoError(t, err)
+	assert.True(t, rolledBack)
+
+	_, _, err = v.Rollback("rules")
+	assert.ErrorIs(t, err, ErrNoPreviousVersion)
+	_, _, err = v.Rollback("other")
+	assert.ErrorIs(t, err, ErrNotInstalled)
+}
+
+func TestInstallReplacesFlatFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "rules.yaml"), []byte("flat"), 0o600))
+
+	_, err := New(dir, DefaultKeep).Install("rules", Version{Digest: "sha256:1"}, write(map[string]string{"rules.yaml": "v1"}))
+	require.NoError(t, err)
+
+	info, err := os.Lstat(filepath.Join(dir, "rules.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode()&os.ModeSymlink)
+	assert.Equal(t, "v1", read(t, filepath.Join(dir, "rules.yaml")))
+}
+
+func TestInstallPrunesOldVersions(t *testing.T) {
+	dir := t.TempDir()
+	v := New(dir, 2)
+
+	for _, digest := range []string{"sha256:1", "sha256:2", "sha256:3"} {
+		_, err := v.Install("rules", Version{Digest: digest}, write(map[string]string{"rules.yaml": digest}))
+		require.NoError(t, err)
+	}
+
+	h, err := v.History("rules")
+	require.NoError(t, err)
+	require.Len(t, h.Versions, 2)
+	assert.Equal(t, "sha256:2", h.Versions[0].Digest)
+	assert.Equal(t, "3", h.Current)
+	assert.NoDirExists(t, filepath.Join(dir, VersionsDir, "rules", "1"))
+
+	// Reinstalling a stored version reuses its directory.
+	_, err = v.Install("rules", Version{Digest: "sha256:2"}, func(string) error {
+		t.Fatal("the stored version must not be filled again")
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:2", read(t, filepath.Join(dir, "rules.yaml")))
+}
@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lockedfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// DefaultTimeout is how long a lock is waited for by default.
+	DefaultTimeout = 30 * time.Second
+	lockSuffix     = ".lock"
+	retryInterval  = 50 * time.Millisecond
+)
+
+var (
+	// Timeout is how long Lock waits for a lock held by another process before giving up.
+	Timeout = DefaultTimeout
+	// ErrTimeout is returned when a lock could not be acquired before the timeout.
+	ErrTimeout = errors.New("timed out waiting for the lock")
+)
+
+// Lock acquires the exclusive lock guarding path, waiting up to Timeout for other processes
+// to release it. The returned function releases the lock.
+func Lock(path string) (release func() error, err error) {
+	lockPath := path + lockSuffix
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0o750); err != nil {
+		return nil, fmt.Errorf("unable to create directory for lock %q: %w", lockPath, err)
+	}
+
+	f, err := os.OpenFile(filepath.Clean(lockPath), os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open lock %q: %w", lockPath, err)
+	}
+
+	deadline := time.Now().Add(Timeout)
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("unable to lock %q: %w", path, err)
+		}
+		if locked {
+			break
+		}
+		if time.Now().After(deadline) {
+			_ = f.Close()
+			return nil, fmt.Errorf("%w on %q after %s: another diginfractl process is modifying it", ErrTimeout, path, Timeout)
+		}
+		time.Sleep(retryInterval)
+	}
+
+	return func() error {
+		err := unlock(f)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}, nil
+}
+
+// WriteFile atomically replaces the content of path with data. The data is written to a temporary
+// file in the same directory, which is then renamed to path. Like os.WriteFile, the permissions of
+// an existing file are kept, perm is used otherwise.
+func WriteFile(path string, data []byte, perm fs.FileMode) (err error) {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Update locks path and atomically replaces its content with the one returned by fn. The function
+// receives the current content of the file, nil if it does not exist. The file is left untouched
+// when fn fails.
+func Update(path string, perm fs.FileMode, fn func(data []byte) ([]byte, error)) (err error) {
+	release, err := Lock(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if uerr := release(); err == nil {
+			err = uerr
+		}
+	}()
+
+	data, err := os.ReadFile(filepath.Clean(path))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if data, err = fn(data); err != nil {
+		return err
+	}
+
+	return WriteFile(path, data, perm)
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lockedfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "indexes.yaml")
+
+	require.NoError(t, WriteFile(path, []byte("first"), 0o600))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(data))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// The permissions of existing files are kept.
+	require.NoError(t, WriteFile(path, []byte("second"), 0o644))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+	info, err = os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	// No temporary file is left behind.
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestLockTimeout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	defer func(timeout time.Duration) { Timeout = timeout }(Timeout)
+	Timeout = 200 * time.Millisecond
+
+	release, err := Lock(path)
+	require.NoError(t, err)
+
+	_, err = Lock(path)
+	assert.True(t, errors.Is(err, ErrTimeout))
+	assert.ErrorContains(t, err, path)
+
+	require.NoError(t, release())
+	release, err = Lock(path)
+	require.NoError(t, err)
+	require.NoError(t, release())
+}
+
+func TestUpdate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "counter")
+	increment := func(data []byte) ([]byte, error) {
+		n := 0
+		if data != nil {
+			var err error
+			if n, err = strconv.Atoi(string(data)); err != nil {
+				return nil, err
+			}
+		}
+		return []byte(strconv.Itoa(n + 1)), nil
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, Update(path, 0o600, increment))
+		}()
+	}
+	wg.Wait()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "20", string(data))
+
+	// The file is left untouched when the update fails.
+	require.Error(t, Update(path, 0o600, func([]byte) ([]byte, error) {
+		return nil, errors.New("boom")
+	}))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "20", string(data))
+}
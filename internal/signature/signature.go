@@ -17,6 +17,7 @@ package signature
 
 import (
 	"context"
+	"strings"
 
 	"github.com/sigstore/cosign/v2/cmd/cosign/cli/options"
 
@@ -26,17 +27,58 @@ import (
 
 // Verify checks that a fully qualified reference is signed according to the parameters.
 func Verify(ctx context.Context, ref string, signature *index.Signature) error {
+	_, err := VerifySigner(ctx, ref, false, signature)
+	return err
+}
+
+// VerifySigner checks the signature of the artifact referenced by ref and returns the identity of
+// the signer: the identities found in the signing certificates for keyless signatures, or the key
+// reference otherwise. It returns an empty identity if there is nothing to verify. The registry is
+// reached in plain http if plainHTTP is set.
+func VerifySigner(ctx context.Context, ref string, plainHTTP bool, signature *index.Signature) (string, error) {
 	if signature == nil {
 		// nothing to do
-		return nil
+		return "", nil
 	}
 
 	if signature.Cosign == nil {
 		// we currently only support cosign
-		return nil
+		return "", nil
 	}
 
+	registryOptions := options.RegistryOptions{AllowHTTPRegistry: plainHTTP}
 	v := cosign.VerifyCommand{
+		CertVerifyOptions: options.CertVerifyOptions{
+			CertIdentity:         signature.Cosign.CertificateIdentity,
+			CertIdentityRegexp:   signature.Cosign.CertificateIdentityRegexp,
+			CertOidcIssuer:       signature.Cosign.CertificateOidcIssuer,
+			CertOidcIssuerRegexp: signature.Cosign.CertificateOidcIssuerRegexp,
+		},
+		RegistryOptions: registryOptions,
+		NameOptions:     registryOptions.NameOptions(),
+		KeyRef:          signature.Cosign.KeyRef,
+		IgnoreTlog:      signature.Cosign.IgnoreTlog,
+	}
+	if err := v.DoVerify(ctx, []string{ref}); err != nil {
+		return "", err
+	}
+
+	if len(v.Signers) == 0 && signature.Cosign.KeyRef != "" {
+		return "key:" + signature.Cosign.KeyRef, nil
+	}
+
+	return strings.Join(v.Signers, ","), nil
+}
+
+// VerifyBlob checks the detached signature of a blob according to the parameters. The signature
+// is either a base64 signature or a cosign bundle.
+func VerifyBlob(ctx context.Context, blob, sig []byte, signature *index.Signature) error {
+	if signature == nil || signature.Cosign == nil {
+		// nothing to do
+		return nil
+	}
+
+	v := cosign.VerifyBlobCommand{
 		CertVerifyOptions: options.CertVerifyOptions{
 			CertIdentity:         signature.Cosign.CertificateIdentity,
 			CertIdentityRegexp:   signature.Cosign.CertificateIdentityRegexp,
@@ -46,5 +88,5 @@ func Verify(ctx context.Context, ref string, signature *index.Signature) error {
 		KeyRef:     signature.Cosign.KeyRef,
 		IgnoreTlog: signature.Cosign.IgnoreTlog,
 	}
-	return v.DoVerify(ctx, []string{ref})
+	return v.DoVerify(ctx, blob, sig)
 }
@@ -18,11 +18,13 @@ package main
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"github.com/diginfra/diginfractl/cmd"
+	"github.com/diginfra/diginfractl/internal/utils"
 	"github.com/diginfra/diginfractl/pkg/options"
 )
 
@@ -52,6 +54,10 @@ func main() {
 
 	// Execute the command.
 	if err := cmd.Execute(rootCmd, opt); err != nil {
+		var exitErr *utils.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		os.Exit(1)
 	}
 	os.Exit(0)
@@ -26,6 +26,7 @@ import (
 
 	"github.com/diginfra/diginfractl/internal/config"
 	"github.com/diginfra/diginfractl/internal/consts"
+	"github.com/diginfra/diginfractl/internal/lockedfile"
 	indexConf "github.com/diginfra/diginfractl/pkg/index/config"
 	"github.com/diginfra/diginfractl/pkg/index/fetch"
 	"github.com/diginfra/diginfractl/pkg/index/index"
@@ -40,8 +41,12 @@ type Cache struct {
 	indexesDir       string
 	// Track the new indexes that need to be saved locally when writing the cache to file.
 	fetchedIndexes []*index.Index
+	// Track the indexes whose entries have been added or updated, needed when writing the cache to file.
+	updatedIndexes []string
 	// Track the indexes that have been removed, needed when writing the cache to file.
 	removedIndexes []string
+	// Track the errors that prevented stale indexes from being refreshed.
+	refreshErrors []error
 }
 
 // New creates a new cache object. For each entry in the indexes.yaml file it loads the respective index file
@@ -70,7 +75,7 @@ func New(ctx context.Context, indexFile, indexesDir string) (*Cache, error) {
 		if idx, err = c.loadIndex(cfg.Name); err != nil && errors.Is(err, fs.ErrNotExist) {
 			// If the index is not found in the local persistent cache we fetch it from the url.
 			ts := time.Now().Format(consts.TimeFormat)
-			if idx, err = c.fetcher.Fetch(ctx, cfg); err != nil {
+			if idx, _, err = c.fetch(ctx, cfg); err != nil {
 				return nil, fmt.Errorf("unable to fetch index %q with URL %q: %w", cfg.Name, cfg.URL, err)
 			}
 			// If correctly fetched, we need to update the metadata of the config entry.
@@ -80,10 +85,12 @@ func New(ctx context.Context, indexFile, indexesDir string) (*Cache, error) {
 			}
 			c.localIndexes.Upsert(cfg)
 			c.fetchedIndexes = append(c.fetchedIndexes, idx)
+			c.updatedIndexes = append(c.updatedIndexes, cfg.Name)
 		} else if err != nil {
 			return nil, fmt.Errorf("an error occurred while loading cache from disk: %w", err)
 		}
 		// After a successful load/fetch we merge it.
+		idx.Priority = cfg.Priority
 		c.Merge(idx)
 	}
 
@@ -91,12 +98,19 @@ func New(ctx context.Context, indexFile, indexesDir string) (*Cache, error) {
 }
 
 // NewFromConfig creates a new cache object from a set of indexes. The new cache fetches the indexes only if they do not
-// exist in the filesystem. The local indexes info is ignored, it takes into account the indexes passed as arguments.
+// exist in the filesystem, or if their refresh interval elapsed since their last update. Stale indexes are fetched
+// conditionally and the cached copy is used if they cannot be fetched, see RefreshErrors. Apart from the timestamps and
+// the validators, the local indexes info is ignored, it takes into account the indexes passed as arguments.
 func NewFromConfig(ctx context.Context, indexFile, indexesDir string, indexes []config.Index) (*Cache, error) {
 	var err error
 	var idx *index.Index
 	indexConfig := &indexConf.Config{}
 
+	persistedConfig, err := indexConf.New(indexFile)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while loading index file %q from disk: %w", indexFile, err)
+	}
+
 	c := &Cache{
 		fetcher:          fetch.NewFetcher(),
 		localIndexes:     indexConfig,
@@ -105,76 +119,152 @@ func NewFromConfig(ctx context.Context, indexFile, indexesDir string, indexes []
 		MergedIndexes:    index.NewMergedIndexes(),
 	}
 
+	now := time.Now()
+	ts := now.Format(consts.TimeFormat)
+	var refreshedEntries []*indexConf.Entry
+	var refreshedIndexes []*index.Index
 	for i := range indexes {
 		cfg := &indexes[i]
+		entry := indexConf.EntryFromIndex(cfg)
+		entry.AddedTimestamp, entry.UpdatedTimestamp = ts, ts
+		// The timestamps and the validators are only meaningful for the same URL.
+		if persisted := persistedConfig.Get(cfg.Name); persisted != nil && persisted.URL == cfg.URL {
+			entry.AddedTimestamp, entry.UpdatedTimestamp = persisted.AddedTimestamp, persisted.UpdatedTimestamp
+			entry.ETag, entry.LastModified = persisted.ETag, persisted.LastModified
+		}
+
 		// If the index is in the local persistent cache we just load it.
-		ts := time.Now().Format(consts.TimeFormat)
 		if idx, err = c.loadIndex(cfg.Name); err != nil && errors.Is(err, fs.ErrNotExist) {
 			// If the index is not found in the local persistent cache we fetch it from the url.
-			if idx, err = c.fetcher.Fetch(ctx, indexConf.EntryFromIndex(cfg)); err != nil {
+			if idx, _, err = c.fetch(ctx, entry); err != nil {
 				return nil, fmt.Errorf("unable to fetch index %q with URL %q: %w", cfg.Name, cfg.URL, err)
 			}
+			entry.UpdatedTimestamp = ts
 			c.fetchedIndexes = append(c.fetchedIndexes, idx)
 		} else if err != nil {
 			return nil, fmt.Errorf("an error occurred while loading cache from disk: %w", err)
+		} else if entry.Stale(now) {
+			// The cached copy is kept when the index did not change or cannot be fetched, e.g. when offline.
+			if refreshed, modified, err := c.fetch(ctx, entry); err != nil {
+				c.refreshErrors = append(c.refreshErrors, fmt.Errorf("unable to refresh index %q with URL %q: %w", cfg.Name, cfg.URL, err))
+			} else {
+				entry.UpdatedTimestamp = ts
+				refreshedEntries = append(refreshedEntries, entry)
+				if modified {
+					idx = refreshed
+					refreshedIndexes = append(refreshedIndexes, idx)
+				}
+			}
 		}
-		c.localIndexes.Configs = append(c.localIndexes.Configs, &indexConf.Entry{
-			AddedTimestamp:   ts,
-			Name:             cfg.Name,
-			UpdatedTimestamp: ts,
-			URL:              cfg.URL,
-		})
+		c.localIndexes.Configs = append(c.localIndexes.Configs, entry)
 		// After a successful load/fetch we merge it.
+		idx.Priority = entry.Priority
 		c.Merge(idx)
 	}
 
+	if len(refreshedEntries) > 0 {
+		if err := c.writeRefreshed(refreshedEntries, refreshedIndexes); err != nil {
+			c.refreshErrors = append(c.refreshErrors, err)
+		}
+	}
+
 	return c, nil
 }
 
+// RefreshErrors returns the errors that prevented stale indexes from being refreshed. The cached copies
+// of those indexes are used instead.
+func (c *Cache) RefreshErrors() []error {
+	return c.refreshErrors
+}
+
+// writeRefreshed saves the refreshed indexes and updates their entries in the persisted config, so that
+// they are not refreshed again before their refresh interval elapses. The other entries are left untouched.
+func (c *Cache) writeRefreshed(entries []*indexConf.Entry, indexes []*index.Index) error {
+	release, err := lockedfile.Lock(c.localIndexesFile)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = release() }()
+
+	// Read the persisted config again, other processes may have changed it in the meantime.
+	persistedConfig, err := indexConf.New(c.localIndexesFile)
+	if err != nil {
+		return fmt.Errorf("an error occurred while loading index file %q from disk: %w", c.localIndexesFile, err)
+	}
+
+	for _, idx := range indexes {
+		indexPath := filepath.Join(c.indexesDir, fmt.Sprintf("%s%s", idx.Name, ".yaml"))
+		if err := idx.Write(indexPath); err != nil {
+			return fmt.Errorf("an error occurred while writing index %q to file %q: %w", idx.Name, indexPath, err)
+		}
+	}
+
+	for _, entry := range entries {
+		persistedConfig.Upsert(entry)
+	}
+	if err := persistedConfig.Write(c.localIndexesFile); err != nil {
+		return fmt.Errorf("an error occurred while writing indexes file to path %q: %w", c.localIndexesFile, err)
+	}
+
+	return nil
+}
+
+// fetch fetches the index of the given entry. If the index did not change since it was cached, the cached
+// copy is returned and modified is false.
+func (c *Cache) fetch(ctx context.Context, entry *indexConf.Entry) (idx *index.Index, modified bool, err error) {
+	idx, err = c.fetcher.Fetch(ctx, entry)
+	if errors.Is(err, indexConf.ErrNotModified) {
+		if idx, err = c.loadIndex(entry.Name); err == nil {
+			return idx, false, nil
+		}
+		// The cached copy is gone, the validators are useless.
+		entry.ETag, entry.LastModified = "", ""
+		idx, err = c.fetcher.Fetch(ctx, entry)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return idx, true, nil
+}
+
 // Add adds a new index file to the cache. If the index file already exists in the cache it
-// does nothing. On the other hand, it fetches the index file using the provided URL and adds
-// it to the in memory cache. It does not write it to the filesystem. It is idempotent.
-func (c *Cache) Add(ctx context.Context, name, backend, url string) error {
+// does nothing. On the other hand, it fetches the index file using the provided URL and settings,
+// if any, and adds it to the in memory cache. It does not write it to the filesystem. It is idempotent.
+func (c *Cache) Add(ctx context.Context, idx *config.Index) error {
 	var remoteIndex *index.Index
 	var err error
 
-	entry := c.localIndexes.Get(name)
+	entry := c.localIndexes.Get(idx.Name)
 
 	// If it exists already, return.
 	if entry != nil {
 		return nil
 	}
 
-	entry = &indexConf.Entry{
-		Name:    name,
-		URL:     url,
-		Backend: backend,
-	}
-
 	// If the index is not locally cached we fetch it using the provided url.
+	entry = indexConf.EntryFromIndex(idx)
 	if remoteIndex, err = c.fetcher.Fetch(ctx, entry); err != nil {
-		return fmt.Errorf("unable to fetch index %q with URL %q: %w", name, url, err)
+		return fmt.Errorf("unable to fetch index %q with URL %q: %w", idx.Name, idx.URL, err)
 	}
 
 	// Keep track of the newly created index file.
 	ts := time.Now().Format(consts.TimeFormat)
-	entry = &indexConf.Entry{
-		Name:             remoteIndex.Name,
-		AddedTimestamp:   ts,
-		UpdatedTimestamp: ts,
-		URL:              url,
-		Backend:          backend,
-	}
+	entry.Name = remoteIndex.Name
+	entry.AddedTimestamp = ts
+	entry.UpdatedTimestamp = ts
 	c.localIndexes.Add(entry)
 
 	// Save it for later write operation.
 	c.fetchedIndexes = append(c.fetchedIndexes, remoteIndex)
+	c.updatedIndexes = append(c.updatedIndexes, entry.Name)
 
+	remoteIndex.Priority = entry.Priority
 	c.Merge(remoteIndex)
 
 	// If the index has been removed before we make sure to delete it from the removedIndexes array.
 	for i, idxName := range c.removedIndexes {
-		if idxName == name {
+		if idxName == idx.Name {
 			c.removedIndexes = append(c.removedIndexes[:i], c.removedIndexes[i+1:]...)
 		}
 	}
@@ -204,6 +294,7 @@ func (c *Cache) Remove(name string) error {
 			} else if err != nil {
 				return err
 			}
+			idx.Priority = cfg.Priority
 			newMergedIndex.Merge(idx)
 		}
 	}
@@ -239,7 +330,7 @@ func (c *Cache) Update(ctx context.Context, name string) error {
 
 	ts := time.Now().Format(consts.TimeFormat)
 	// Fetch the index from the remote url.
-	updatedIndex, err := c.fetcher.Fetch(ctx, entry)
+	updatedIndex, modified, err := c.fetch(ctx, entry)
 	if err != nil {
 		return fmt.Errorf("unable to fetch index %q with URL %q: %w", name, entry.URL, err)
 	}
@@ -247,9 +338,12 @@ func (c *Cache) Update(ctx context.Context, name string) error {
 	// Update the existing index entry by setting the new timestamp.
 	entry.UpdatedTimestamp = ts
 	c.localIndexes.Upsert(entry)
+	c.updatedIndexes = append(c.updatedIndexes, name)
 
 	// Track the new fetched index for writing purposes.
-	c.fetchedIndexes = append(c.fetchedIndexes, updatedIndex)
+	if modified {
+		c.fetchedIndexes = append(c.fetchedIndexes, updatedIndex)
+	}
 
 	// Create a new merged indexes without the one we are removing.
 	for _, cfg := range c.localIndexes.Configs {
@@ -262,8 +356,10 @@ func (c *Cache) Update(ctx context.Context, name string) error {
 			} else if err != nil {
 				return err
 			}
+			idx.Priority = cfg.Priority
 			newMergedIndex.Merge(idx)
 		} else {
+			updatedIndex.Priority = entry.Priority
 			newMergedIndex.Merge(updatedIndex)
 		}
 	}
@@ -277,8 +373,21 @@ func (c *Cache) Update(ctx context.Context, name string) error {
 // Remove: the removed entry is wiped out from the config.IndexesFile and the related index file is deleted.
 // Update: the entry in the config.IndexesFile for the updated index is updated. The related index file is
 // replaced by the new content fetched by the update operation.
+// The config.IndexesFile is locked while written and only the entries changed by the cache operations are
+// written, so that the changes made by other processes since the cache was created are not lost.
 // Returns the indexConf.Config written to the config.IndexesFile.
 func (c *Cache) Write() (*indexConf.Config, error) {
+	release, err := lockedfile.Lock(c.localIndexesFile)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = release() }()
+
+	persistedConfig, err := indexConf.New(c.localIndexesFile)
+	if err != nil {
+		return nil, fmt.Errorf("an error occurred while loading index file %q from disk: %w", c.localIndexesFile, err)
+	}
+
 	for _, idx := range c.fetchedIndexes {
 		indexFileName := fmt.Sprintf("%s%s", idx.Name, ".yaml")
 		indexPath := filepath.Join(c.indexesDir, indexFileName)
@@ -292,15 +401,24 @@ func (c *Cache) Write() (*indexConf.Config, error) {
 	for _, name := range c.removedIndexes {
 		indexFileName := fmt.Sprintf("%s%s", name, ".yaml")
 		indexPath := filepath.Join(c.indexesDir, indexFileName)
-		if err := os.Remove(indexPath); err != nil {
+		// Another process may have removed it already.
+		if err := os.Remove(indexPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
 			return nil, fmt.Errorf("an error occurred while removeing index %q from %q: %w", name, indexPath, err)
 		}
 		c.localIndexes.Remove(name)
+		persistedConfig.Remove(name)
+	}
+
+	for _, name := range c.updatedIndexes {
+		if entry := c.localIndexes.Get(name); entry != nil {
+			persistedConfig.Upsert(entry)
+		}
 	}
 
-	if err := c.localIndexes.Write(c.localIndexesFile); err != nil {
+	if err := persistedConfig.Write(c.localIndexesFile); err != nil {
 		return nil, fmt.Errorf("an error occurred while writing indexes file to path %q: %w", c.localIndexesFile, err)
 	}
+	c.localIndexes = persistedConfig
 
 	return c.localIndexes, nil
 }
@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diginfra/diginfractl/internal/config"
+	"github.com/diginfra/diginfractl/internal/consts"
+	indexConf "github.com/diginfra/diginfractl/pkg/index/config"
+)
+
+func TestNewFromConfigRefresh(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	indexFile, indexesDir := filepath.Join(dir, "indexes.yaml"), filepath.Join(dir, "indexes")
+
+	var requests atomic.Int32
+	content, etag := "- name: v1\n", `"v1"`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	indexes := []config.Index{{Name: "test", URL: server.URL, RefreshInterval: time.Hour}}
+
+	// Add the index, as done by "index add".
+	c, err := New(ctx, indexFile, indexesDir)
+	require.NoError(t, err)
+	require.NoError(t, c.Add(ctx, &indexes[0]))
+	_, err = c.Write()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, requests.Load())
+
+	// makeStale moves the last update of the index before its refresh interval.
+	makeStale := func() {
+		persisted, err := indexConf.New(indexFile)
+		require.NoError(t, err)
+		persisted.Get("test").UpdatedTimestamp = time.Now().Add(-2 * time.Hour).Format(consts.TimeFormat)
+		require.NoError(t, persisted.Write(indexFile))
+	}
+
+	// The index is fresh, it is not fetched.
+	c, err = NewFromConfig(ctx, indexFile, indexesDir, indexes)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, requests.Load())
+	_, ok := c.EntryByName("v1")
+	assert.True(t, ok)
+
+	// The index is stale but did not change.
+	makeStale()
+	c, err = NewFromConfig(ctx, indexFile, indexesDir, indexes)
+	require.NoError(t, err)
+	assert.Empty(t, c.RefreshErrors())
+	assert.EqualValues(t, 2, requests.Load())
+	_, ok = c.EntryByName("v1")
+	assert.True(t, ok)
+
+	// The refresh has been recorded.
+	_, err = NewFromConfig(ctx, indexFile, indexesDir, indexes)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, requests.Load())
+
+	// The index is stale and changed.
+	content, etag = "- name: v2\n", `"v2"`
+	makeStale()
+	c, err = NewFromConfig(ctx, indexFile, indexesDir, indexes)
+	require.NoError(t, err)
+	assert.Empty(t, c.RefreshErrors())
+	_, ok = c.EntryByName("v2")
+	assert.True(t, ok)
+	persisted, err := indexConf.New(indexFile)
+	require.NoError(t, err)
+	assert.Equal(t, `"v2"`, persisted.Get("test").ETag)
+
+	// The index is stale and cannot be fetched: the cached copy is used.
+	server.Close()
+	makeStale()
+	c, err = NewFromConfig(ctx, indexFile, indexesDir, indexes)
+	require.NoError(t, err)
+	assert.Len(t, c.RefreshErrors(), 1)
+	_, ok = c.EntryByName("v2")
+	assert.True(t, ok)
+}
+
+func TestWriteKeepsConcurrentChanges(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	indexFile, indexesDir := filepath.Join(dir, "indexes.yaml"), filepath.Join(dir, "indexes")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("- name: entry" + r.URL.Path[1:] + "\n"))
+	}))
+	defer server.Close()
+
+	// Two processes add an index at the same time.
+	first, err := New(ctx, indexFile, indexesDir)
+	require.NoError(t, err)
+	second, err := New(ctx, indexFile, indexesDir)
+	require.NoError(t, err)
+	require.NoError(t, first.Add(ctx, &config.Index{Name: "a", URL: server.URL + "/a"}))
+	require.NoError(t, second.Add(ctx, &config.Index{Name: "b", URL: server.URL + "/b"}))
+	_, err = first.Write()
+	require.NoError(t, err)
+	written, err := second.Write()
+	require.NoError(t, err)
+	assert.NotNil(t, written.Get("a"))
+	assert.NotNil(t, written.Get("b"))
+
+	// One process removes an index while another one updates a different one.
+	first, err = New(ctx, indexFile, indexesDir)
+	require.NoError(t, err)
+	second, err = New(ctx, indexFile, indexesDir)
+	require.NoError(t, err)
+	require.NoError(t, first.Remove("a"))
+	require.NoError(t, second.Update(ctx, "b"))
+	_, err = first.Write()
+	require.NoError(t, err)
+	_, err = second.Write()
+	require.NoError(t, err)
+
+	persisted, err := indexConf.New(indexFile)
+	require.NoError(t, err)
+	assert.Nil(t, persisted.Get("a"))
+	assert.NotNil(t, persisted.Get("b"))
+}
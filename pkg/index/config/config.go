@@ -20,12 +20,19 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/diginfra/diginfractl/internal/config"
+	"github.com/diginfra/diginfractl/internal/consts"
+	"github.com/diginfra/diginfractl/internal/lockedfile"
 )
 
+// ErrNotModified is returned by the fetchers when the index did not change since the
+// validators stored in the entry were returned.
+var ErrNotModified = errors.New("index not modified")
+
 // Entry contains information about one of the index that were cached locally.
 type Entry struct {
 	AddedTimestamp   string `yaml:"added_timestamp"`
@@ -33,20 +40,36 @@ type Entry struct {
 	UpdatedTimestamp string `yaml:"updated_timestamp"`
 	URL              string `yaml:"url"`
 	Backend          string `yaml:"backend"`
-	// TODO: add support for HTTP and other backend configs.
-	// HTTP             http.BackendConfig `yaml:"http"`
+	// Auth is used to fetch the index from HTTP/S backends, if set.
+	Auth *config.IndexAuth `yaml:"auth,omitempty"`
+	// S3 is used to fetch the index from S3 backends, if set.
+	S3 *config.IndexS3 `yaml:"s3,omitempty"`
+	// Signature is verified against the index artifact pulled from OCI backends, and against the
+	// detached signature found at SignatureURL otherwise, if set.
+	Signature    *config.Signature `yaml:"signature,omitempty"`
+	SignatureURL string            `yaml:"signature_url,omitempty"`
+	// ETag and LastModified are the validators returned with the last fetched content of the index,
+	// sent back by the fetchers to only download the index if it changed.
+	ETag         string `yaml:"etag,omitempty"`
+	LastModified string `yaml:"last_modified,omitempty"`
+	// RefreshInterval is the age after which the index is refreshed when used, if set.
+	RefreshInterval time.Duration `yaml:"refresh_interval,omitempty"`
+	// Priority decides which index provides an entry when several indexes have entries with the same name.
+	Priority int `yaml:"priority,omitempty"`
 }
 
-// TODO: add support for HTTP backend config fields.
-// type BackendConfig struct {
-//    CaFile                string `yaml:"caFile"`
-//    CertFile              string `yaml:"certFile"`
-//    InsecureSkipTLSVerify string `yaml:"insecure_skip_tls_verify"`
-//    KeyFile               string `yaml:"keyFile"`
-//    PassCredentialsAll    string `yaml:"pass_credentials_all"`
-//    Password              string `yaml:"password"`
-//    Username              string `yaml:"username"`
-// }
+// Stale returns true if the refresh interval of the entry elapsed since its last update.
+func (e *Entry) Stale(now time.Time) bool {
+	if e.RefreshInterval <= 0 {
+		return false
+	}
+	updated, err := time.ParseInLocation(consts.TimeFormat, e.UpdatedTimestamp, time.Local)
+	if err != nil {
+		// Without a valid timestamp we cannot tell how old the index is.
+		return true
+	}
+	return now.Sub(updated) >= e.RefreshInterval
+}
 
 // Config aggregates the info about ConfigEntries.
 type Config struct {
@@ -74,9 +97,15 @@ func New(path string) (*Config, error) {
 // EntryFromIndex creates a Entry from a config.Index.
 func EntryFromIndex(idx *config.Index) *Entry {
 	return &Entry{
-		Name:    idx.Name,
-		URL:     idx.URL,
-		Backend: idx.Backend,
+		Name:            idx.Name,
+		URL:             idx.URL,
+		Backend:         idx.Backend,
+		Auth:            idx.Auth,
+		S3:              idx.S3,
+		Signature:       idx.Signature,
+		SignatureURL:    idx.SignatureURL,
+		RefreshInterval: idx.RefreshInterval,
+		Priority:        idx.Priority,
 	}
 }
 
@@ -134,10 +163,24 @@ func (c *Config) Write(path string) error {
 		return err
 	}
 
-	err = os.WriteFile(path, data, DefaultFilePermissions)
+	// Do not let other users read the secrets stored inline.
+	perm := os.FileMode(DefaultFilePermissions)
+	for _, entry := range c.Configs {
+		if entry.Auth.HasInlineSecrets() || entry.S3.HasInlineSecrets() {
+			perm = SecretFilePermissions
+			break
+		}
+	}
+
+	// The file is replaced atomically, so that concurrent readers never see it partially written.
+	err = lockedfile.WriteFile(path, data, perm)
 	if err != nil {
 		return err
 	}
+	// WriteFile keeps the permissions of existing files.
+	if perm == SecretFilePermissions {
+		return os.Chmod(path, perm)
+	}
 
 	return nil
 }
@@ -18,6 +18,8 @@ package config
 const (
 	// DefaultFilePermissions are the default permissions used for files.
 	DefaultFilePermissions = 0o644
+	// SecretFilePermissions are the permissions used for files storing secrets.
+	SecretFilePermissions = 0o600
 	// DefaultDirPermissions are the default permissions used for directories.
 	DefaultDirPermissions = 0o755
 )
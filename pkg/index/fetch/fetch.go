@@ -21,13 +21,20 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/diginfra/diginfractl/internal/signature"
 	"github.com/diginfra/diginfractl/pkg/index/config"
 	"github.com/diginfra/diginfractl/pkg/index/fetch/file"
 	"github.com/diginfra/diginfractl/pkg/index/fetch/gcs"
 	"github.com/diginfra/diginfractl/pkg/index/fetch/http"
+	"github.com/diginfra/diginfractl/pkg/index/fetch/oci"
+	"github.com/diginfra/diginfractl/pkg/index/fetch/s3"
 	"github.com/diginfra/diginfractl/pkg/index/index"
 )
 
+// SignatureSuffix is appended to the index URL to locate its detached signature when no
+// signature URL is configured.
+const SignatureSuffix = ".sig"
+
 // Func is a prototype for fetching indices for a specific index backend.
 type Func func(context.Context, *config.Entry) ([]byte, error)
 
@@ -48,12 +55,18 @@ func NewFetcher() *Fetcher {
 			"https": http.Fetch,
 			"gcs":   gcs.Fetch,
 			"file":  file.Fetch,
+			"oci":   oci.Fetch,
+			"s3":    s3.Fetch,
 		},
 		schemeDefaultBackends: map[string]string{
 			"http":  "http",
 			"https": "https",
 			"gs":    "gcs",
 			"file":  "file",
+			"s3":    "s3",
+			// plain http registries are told apart by the oci backend itself
+			oci.Scheme:          "oci",
+			oci.PlainHTTPScheme: "oci",
 		},
 	}
 }
@@ -68,6 +81,23 @@ func (f *Fetcher) get(backend string) (Func, error) {
 
 // Fetch retrieves a remote index.
 func (f *Fetcher) Fetch(ctx context.Context, conf *config.Entry) (*index.Index, error) {
+	bytes, err := f.FetchBytes(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	i := index.New(conf.Name)
+	err = i.ReadBytes(bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return i, nil
+}
+
+// FetchBytes retrieves the content of a remote index, verifying its signature if configured,
+// without parsing it.
+func (f *Fetcher) FetchBytes(ctx context.Context, conf *config.Entry) ([]byte, error) {
 	// if we don't have an explicit backend
 	// we try to guess based on the URI scheme
 	if conf.Backend == "" {
@@ -90,11 +120,39 @@ func (f *Fetcher) Fetch(ctx context.Context, conf *config.Entry) (*index.Index,
 		return nil, fmt.Errorf("unable to fetch index: %w", err)
 	}
 
-	i := index.New(conf.Name)
-	err = i.ReadBytes(bytes)
+	if err := f.verify(ctx, fetcher, conf, bytes); err != nil {
+		return nil, fmt.Errorf("unable to verify index %q: %w", conf.Name, err)
+	}
+
+	return bytes, nil
+}
+
+// verify checks the detached signature of the index content, if a signature is configured. Indexes
+// pulled from OCI backends are verified by the backend against the signature of the artifact itself.
+func (f *Fetcher) verify(ctx context.Context, fetcher Func, conf *config.Entry, content []byte) error {
+	sig := index.SignatureFromConfig(conf.Signature)
+	if sig == nil || strings.EqualFold(conf.Backend, "oci") {
+		return nil
+	}
+
+	// The signature is fetched with the same backend and credentials of the index, but without
+	// the validators of the index content.
+	sigConf := *conf
+	sigConf.URL = conf.SignatureURL
+	if sigConf.URL == "" {
+		sigConf.URL = conf.URL + SignatureSuffix
+	}
+	sigConf.ETag = ""
+	sigConf.LastModified = ""
+
+	sigBytes, err := fetcher(ctx, &sigConf)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("unable to fetch signature %q: %w", sigConf.URL, err)
 	}
 
-	return i, nil
+	if err := signature.VerifyBlob(ctx, content, sigBytes, sig); err != nil {
+		return err
+	}
+
+	return nil
 }
@@ -17,11 +17,20 @@ package fetch
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"testing"
 
+	internalconfig "github.com/diginfra/diginfractl/internal/config"
 	"github.com/diginfra/diginfractl/pkg/index/config"
 )
 
@@ -54,3 +63,74 @@ func TestFetch(t *testing.T) {
 		t.Errorf("cannot fetch index")
 	}
 }
+
+func TestFetchSigned(t *testing.T) {
+	content, err := os.ReadFile("../testdata/index.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "cosign.pub")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256(content)
+	rawSig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := base64.StdEncoding.EncodeToString(rawSig)
+
+	served := content
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []byte
+		switch r.URL.Path {
+		case "/index.yaml":
+			body = served
+		case "/index.yaml.sig":
+			body = []byte(sig)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if _, err := w.Write(body); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer ts.Close()
+
+	newEntry := func() *config.Entry {
+		return &config.Entry{
+			Name:    "diginfra",
+			Backend: "http",
+			URL:     ts.URL + "/index.yaml",
+			Signature: &internalconfig.Signature{
+				Cosign: &internalconfig.CosignSignature{KeyRef: keyPath, IgnoreTlog: true},
+			},
+		}
+	}
+
+	if _, err := NewFetcher().Fetch(context.Background(), newEntry()); err != nil {
+		t.Fatalf("cannot fetch signed index: %v", err)
+	}
+
+	// The configured signature URL is used instead of the default one.
+	entry := newEntry()
+	entry.SignatureURL = ts.URL + "/missing.sig"
+	if _, err := NewFetcher().Fetch(context.Background(), entry); err == nil {
+		t.Errorf("expected an error fetching a missing signature")
+	}
+
+	served = append(append([]byte{}, content...), []byte("\n# tampered\n")...)
+	if _, err := NewFetcher().Fetch(context.Background(), newEntry()); err == nil {
+		t.Errorf("expected an error fetching a tampered index")
+	}
+}
@@ -17,10 +17,14 @@ package gcs
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
 
 	"cloud.google.com/go/storage"
+	"github.com/googleapis/gax-go/v2/callctx"
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/option"
 
 	"github.com/diginfra/diginfractl/pkg/index/config"
@@ -28,7 +32,10 @@ import (
 
 const gcsReadOnlyScope = "https://www.googleapis.com/auth/devstorage.read_only"
 
-// Fetch fetches the raw index file from a GCS object.
+// Fetch fetches the raw index file from a GCS object. The read is conditional when the entry has a
+// Last-Modified validator: config.ErrNotModified is returned if the object did not change, otherwise
+// the validator of the entry is updated with the one of the object. The client does not expose the
+// ETag of the objects, so only Last-Modified is used.
 func Fetch(ctx context.Context, conf *config.Entry) ([]byte, error) {
 	o, err := gcsObjectFromURI(conf.URL)
 	if err != nil {
@@ -41,8 +48,15 @@ func Fetch(ctx context.Context, conf *config.Entry) ([]byte, error) {
 		return nil, fmt.Errorf("unable to create GCS client: %w", err)
 	}
 
+	if conf.LastModified != "" {
+		ctx = callctx.SetHeaders(ctx, "If-Modified-Since", conf.LastModified)
+	}
+
 	reader, err := c.Bucket(o.Bucket).Object(o.Object).NewReader(ctx)
-	if err != nil {
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) && apiErr.Code == http.StatusNotModified {
+		return nil, config.ErrNotModified
+	} else if err != nil {
 		return nil, fmt.Errorf("unable to create GCS object reader: %w", err)
 	}
 
@@ -59,5 +73,10 @@ func Fetch(ctx context.Context, conf *config.Entry) ([]byte, error) {
 		return nil, fmt.Errorf("error reading GCS object: %w", err)
 	}
 
+	conf.ETag, conf.LastModified = "", ""
+	if !reader.Attrs.LastModified.IsZero() {
+		conf.LastModified = reader.Attrs.LastModified.UTC().Format(http.TimeFormat)
+	}
+
 	return res, nil
 }
@@ -200,3 +200,34 @@ func TestGCSFetchWithUnauthorized(t *testing.T) {
 	assert.ErrorContains(t, err, "Permission 'storage.objects.get' denied on resource", "fetch should have errored with permission denied")
 	assert.Nil(t, b, "returned index should be nil")
 }
+
+func TestGCSFetchConditional(t *testing.T) {
+	bucket := "bucket"
+	object := "index.yaml"
+
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == "Mon, 03 Jun 2024 10:00:00 GMT" {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Last-Modified", "Mon, 03 Jun 2024 10:00:00 GMT")
+		_, _ = w.Write([]byte("- name: test\n"))
+	}))
+	defer server.Close()
+
+	t.Setenv("STORAGE_EMULATOR_HOST", server.URL)
+
+	entry := &config.Entry{
+		Name:    "test",
+		URL:     fmt.Sprintf("gs://%s/%s", bucket, object),
+		Backend: "GCS",
+	}
+	b, err := Fetch(ctx, entry)
+	assert.NoError(t, err)
+	assert.Equal(t, "- name: test\n", string(b))
+	assert.Equal(t, "Mon, 03 Jun 2024 10:00:00 GMT", entry.LastModified)
+
+	_, err = Fetch(ctx, entry)
+	assert.ErrorIs(t, err, config.ErrNotModified)
+}
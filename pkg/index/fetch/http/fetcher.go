@@ -17,27 +17,53 @@ package http
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 
-	"github.com/diginfra/diginfractl/pkg/index/config"
+	"github.com/diginfra/diginfractl/internal/config"
+	indexconfig "github.com/diginfra/diginfractl/pkg/index/config"
 )
 
-// Fetch fetches the raw index file from the given HTTP/S url.
-func Fetch(ctx context.Context, conf *config.Entry) ([]byte, error) {
+// Fetch fetches the raw index file from the given HTTP/S url, using the credentials and the
+// TLS settings of the entry, if any. The request is conditional when the entry has validators:
+// indexconfig.ErrNotModified is returned if the index did not change, otherwise the validators of
+// the entry are updated with the ones of the response.
+func Fetch(ctx context.Context, conf *indexconfig.Entry) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", conf.URL, http.NoBody)
 	if err != nil {
 		return nil, fmt.Errorf("cannot fetch index: %w", err)
 	}
 
-	client := &http.Client{}
+	if err := setAuth(req, conf.Auth); err != nil {
+		return nil, fmt.Errorf("cannot fetch index: %w", err)
+	}
+
+	if conf.ETag != "" {
+		req.Header.Set("If-None-Match", conf.ETag)
+	}
+	if conf.LastModified != "" {
+		req.Header.Set("If-Modified-Since", conf.LastModified)
+	}
+
+	client, err := newClient(conf.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch index: %w", err)
+	}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("cannot fetch index: %w", err)
 	}
 	defer resp.Body.Close() // #nosec G307 closing errors should not happen
 
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, indexconfig.ErrNotModified
+	}
+
 	if resp.StatusCode >= http.StatusBadRequest && resp.StatusCode <= http.StatusNetworkAuthenticationRequired {
 		return nil, fmt.Errorf("cannot fetch index: %s", resp.Status)
 	}
@@ -47,5 +73,80 @@ func Fetch(ctx context.Context, conf *config.Entry) ([]byte, error) {
 		return nil, fmt.Errorf("cannot read bytes from response body: %w", err)
 	}
 
+	conf.ETag = resp.Header.Get("ETag")
+	conf.LastModified = resp.Header.Get("Last-Modified")
+
 	return bytes, nil
 }
+
+// setAuth adds the headers and the credentials to the request.
+func setAuth(req *http.Request, auth *config.IndexAuth) error {
+	if auth == nil {
+		return nil
+	}
+
+	for name, secret := range auth.Headers {
+		val, err := secret.Resolve()
+		if err != nil {
+			return fmt.Errorf("header %q: %w", name, err)
+		}
+		req.Header.Set(name, val)
+	}
+
+	if !auth.BearerToken.IsZero() {
+		token, err := auth.BearerToken.Resolve()
+		if err != nil {
+			return fmt.Errorf("bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if auth.Username != "" {
+		password, err := auth.Password.Resolve()
+		if err != nil {
+			return fmt.Errorf("password: %w", err)
+		}
+		req.SetBasicAuth(auth.Username, password)
+	}
+
+	return nil
+}
+
+// newClient returns an HTTP client trusting the configured certificate authorities and presenting
+// the configured client certificate, if any.
+func newClient(auth *config.IndexAuth) (*http.Client, error) {
+	if auth == nil || (auth.CAFile == "" && auth.CertFile == "" && auth.KeyFile == "") {
+		return &http.Client{}, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if auth.CAFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(auth.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read CA bundle: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %q", auth.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if auth.CertFile != "" || auth.KeyFile != "" {
+		if auth.CertFile == "" || auth.KeyFile == "" {
+			return nil, errors.New("both the client certificate and key are required for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(auth.CertFile, auth.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{Transport: transport}, nil
+}
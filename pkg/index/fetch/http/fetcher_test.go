@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package http
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diginfra/diginfractl/internal/config"
+	indexconfig "github.com/diginfra/diginfractl/pkg/index/config"
+)
+
+const indexContent = "- name: test\n"
+
+func TestFetchWithCredentials(t *testing.T) {
+	ctx := context.Background()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, basic := r.BasicAuth()
+		switch {
+		case r.Header.Get("X-Api-Key") != "key":
+			w.WriteHeader(http.StatusForbidden)
+		case r.URL.Path == "/bearer" && r.Header.Get("Authorization") == "Bearer token":
+			_, _ = w.Write([]byte(indexContent))
+		case r.URL.Path == "/basic" && basic && user == "user" && password == "password":
+			_, _ = w.Write([]byte(indexContent))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("password\n"), 0o600))
+	t.Setenv("INDEX_TOKEN", "token")
+	headers := map[string]config.Secret{"X-Api-Key": {Value: "key"}}
+
+	_, err := Fetch(ctx, &indexconfig.Entry{URL: server.URL + "/bearer"})
+	assert.ErrorContains(t, err, "403")
+
+	b, err := Fetch(ctx, &indexconfig.Entry{URL: server.URL + "/bearer", Auth: &config.IndexAuth{
+		BearerToken: config.Secret{Env: "INDEX_TOKEN"},
+		Headers:     headers,
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, indexContent, string(b))
+
+	b, err = Fetch(ctx, &indexconfig.Entry{URL: server.URL + "/basic", Auth: &config.IndexAuth{
+		Username: "user",
+		Password: config.Secret{File: passwordFile},
+		Headers:  headers,
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, indexContent, string(b))
+
+	_, err = Fetch(ctx, &indexconfig.Entry{URL: server.URL + "/bearer", Auth: &config.IndexAuth{
+		BearerToken: config.Secret{Env: "MISSING_INDEX_TOKEN"},
+	}})
+	assert.ErrorContains(t, err, "MISSING_INDEX_TOKEN")
+}
+
+func TestFetchWithTLS(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	// The client certificate is self-signed, the server trusts it as a CA.
+	clientCert, clientKey := newCertificate(t)
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	writePEM(t, certFile, "CERTIFICATE", clientCert.Raw)
+	keyDER, err := x509.MarshalECPrivateKey(clientKey)
+	require.NoError(t, err)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(indexContent))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MinVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(dir, "ca.crt")
+	writePEM(t, caFile, "CERTIFICATE", server.Certificate().Raw)
+
+	// The server certificate is not trusted.
+	_, err = Fetch(ctx, &indexconfig.Entry{URL: server.URL})
+	assert.Error(t, err)
+
+	// The client certificate is missing.
+	_, err = Fetch(ctx, &indexconfig.Entry{URL: server.URL, Auth: &config.IndexAuth{CAFile: caFile}})
+	assert.Error(t, err)
+
+	_, err = Fetch(ctx, &indexconfig.Entry{URL: server.URL, Auth: &config.IndexAuth{CAFile: caFile, CertFile: certFile}})
+	assert.ErrorContains(t, err, "both the client certificate and key")
+
+	b, err := Fetch(ctx, &indexconfig.Entry{URL: server.URL, Auth: &config.IndexAuth{
+		CAFile:   caFile,
+		CertFile: certFile,
+		KeyFile:  keyFile,
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, indexContent, string(b))
+}
+
+func newCertificate(t *testing.T) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "diginfractl"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, key
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+func TestFetchConditional(t *testing.T) {
+	ctx := context.Background()
+	const etag, lastModified = `"v1"`, "Mon, 03 Jun 2024 10:00:00 GMT"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag && r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", lastModified)
+		_, _ = w.Write([]byte(indexContent))
+	}))
+	defer server.Close()
+
+	entry := &indexconfig.Entry{URL: server.URL}
+	b, err := Fetch(ctx, entry)
+	require.NoError(t, err)
+	assert.Equal(t, indexContent, string(b))
+	assert.Equal(t, etag, entry.ETag)
+	assert.Equal(t, lastModified, entry.LastModified)
+
+	_, err = Fetch(ctx, entry)
+	assert.ErrorIs(t, err, indexconfig.ErrNotModified)
+}
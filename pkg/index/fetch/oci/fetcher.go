@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/diginfra/diginfractl/internal/signature"
+	"github.com/diginfra/diginfractl/pkg/index/config"
+	"github.com/diginfra/diginfractl/pkg/index/index"
+	ocipuller "github.com/diginfra/diginfractl/pkg/oci/puller"
+	ociutils "github.com/diginfra/diginfractl/pkg/oci/utils"
+)
+
+const (
+	// Scheme is the URL scheme of indexes stored in OCI registries.
+	Scheme = "oci"
+	// PlainHTTPScheme is the URL scheme of indexes stored in OCI registries reached via plain http.
+	PlainHTTPScheme = "oci+http"
+)
+
+// Fetch pulls the raw index file from the index artifact referenced by the given url, in the
+// oci://REGISTRY/REPO[:TAG|@DIGEST] format. The registry credentials are the ones used for the
+// artifacts. If the entry has a signature, it is verified against the digest of the pulled artifact.
+func Fetch(ctx context.Context, conf *config.Entry) ([]byte, error) {
+	ref, plainHTTP := parseURL(conf.URL)
+
+	client, err := ociutils.Client(true)
+	if err != nil {
+		return nil, err
+	}
+	puller := ocipuller.NewPuller(client, plainHTTP, nil)
+
+	data, digest, err := puller.Index(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to pull index %q: %w", ref, err)
+	}
+
+	// The signature is verified against the digest we just pulled, so that the content we return
+	// is the signed one even if the tag gets overwritten in the meantime.
+	if sig := index.SignatureFromConfig(conf.Signature); sig != nil {
+		digestRef := fmt.Sprintf("%s@%s", repository(ref), digest)
+		if _, err := signature.VerifySigner(ctx, digestRef, plainHTTP, sig); err != nil {
+			return nil, fmt.Errorf("error while verifying signature for %s: %w", digestRef, err)
+		}
+	}
+
+	return data, nil
+}
+
+// parseURL strips the scheme from the url, returning the reference of the index artifact and
+// whether the registry must be reached via plain http.
+func parseURL(url string) (ref string, plainHTTP bool) {
+	if ref, ok := strings.CutPrefix(url, PlainHTTPScheme+"://"); ok {
+		return ref, true
+	}
+	return strings.TrimPrefix(url, Scheme+"://"), false
+}
+
+// repository strips the tag or the digest from the reference.
+func repository(ref string) string {
+	if i := strings.Index(ref, "@"); i >= 0 {
+		return ref[:i]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i]
+	}
+	return ref
+}
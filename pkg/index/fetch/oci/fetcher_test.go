@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/diginfra/diginfractl/internal/config"
+	indexconfig "github.com/diginfra/diginfractl/pkg/index/config"
+	"github.com/diginfra/diginfractl/pkg/oci/authn"
+	ocipusher "github.com/diginfra/diginfractl/pkg/oci/pusher"
+	testutils "github.com/diginfra/diginfractl/pkg/test"
+)
+
+const indexContent = "- name: test\n"
+
+func TestFetch(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	viper.Set(config.RegistryCredentialConfigKey, filepath.Join(dir, "auth.json"))
+	defer viper.Set(config.RegistryCredentialConfigKey, nil)
+
+	reg := testutils.StartTestRegistry(t, nil)
+
+	indexPath := filepath.Join(dir, "index.yaml")
+	require.NoError(t, os.WriteFile(indexPath, []byte(indexContent), 0o600))
+
+	ref := reg + "/diginfra/index"
+	pusher := ocipusher.NewPusher(authn.NewClient(authn.WithCredentials(&auth.EmptyCredential)), true, nil)
+	res, err := pusher.PushIndex(ctx, ref+":latest", indexPath, "v1")
+	require.NoError(t, err)
+	assert.NotEmpty(t, res.RootDigest)
+
+	for _, url := range []string{
+		PlainHTTPScheme + "://" + ref,
+		PlainHTTPScheme + "://" + ref + ":v1",
+		PlainHTTPScheme + "://" + ref + "@" + res.RootDigest,
+	} {
+		data, err := Fetch(ctx, &indexconfig.Entry{Name: "test", URL: url})
+		require.NoError(t, err, url)
+		assert.Equal(t, indexContent, string(data), url)
+	}
+
+	// Missing references are reported.
+	_, err = Fetch(ctx, &indexconfig.Entry{Name: "test", URL: PlainHTTPScheme + "://" + ref + ":missing"})
+	assert.Error(t, err)
+}
+
+func TestParseURL(t *testing.T) {
+	ref, plainHTTP := parseURL("oci://registry.example.com:5000/diginfra/index:latest")
+	assert.Equal(t, "registry.example.com:5000/diginfra/index:latest", ref)
+	assert.False(t, plainHTTP)
+
+	ref, plainHTTP = parseURL("oci+http://localhost:5000/index")
+	assert.Equal(t, "localhost:5000/index", ref)
+	assert.True(t, plainHTTP)
+
+	assert.Equal(t, "registry.example.com:5000/diginfra/index", repository("registry.example.com:5000/diginfra/index:latest"))
+	assert.Equal(t, "registry.example.com:5000/diginfra/index", repository("registry.example.com:5000/diginfra/index@sha256:abc"))
+	assert.Equal(t, "registry.example.com:5000/diginfra/index", repository("registry.example.com:5000/diginfra/index"))
+}
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/diginfra/diginfractl/internal/config"
+	indexconfig "github.com/diginfra/diginfractl/pkg/index/config"
+)
+
+// defaultRegion is used when no region is configured, since most S3-compatible stores ignore it.
+const defaultRegion = "us-east-1"
+
+// Fetch fetches the raw index file from an S3 object, using the S3 settings of the entry, if any.
+func Fetch(ctx context.Context, conf *indexconfig.Entry) ([]byte, error) {
+	o, err := s3ObjectFromURI(conf.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := newClient(ctx, conf.S3)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create S3 client: %w", err)
+	}
+
+	out, err := c.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(o.Bucket),
+		Key:    aws.String(o.Key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get S3 object: %w", err)
+	}
+	defer out.Body.Close()
+
+	res, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading S3 object: %w", err)
+	}
+
+	return res, nil
+}
+
+// newClient returns an S3 client for the given settings. Unless static credentials are set,
+// the standard AWS credential chain is used: environment variables, shared configuration and
+// credentials files, and the credentials of the web identity, the container or the instance.
+func newClient(ctx context.Context, settings *config.IndexS3) (*s3.Client, error) {
+	var opts []func(*awsconfig.LoadOptions) error
+	if settings != nil && settings.Region != "" {
+		opts = append(opts, awsconfig.WithRegion(settings.Region))
+	}
+
+	if settings.HasStaticCredentials() {
+		accessKeyID, err := settings.AccessKeyID.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("access key ID: %w", err)
+		}
+		secretAccessKey, err := settings.SecretAccessKey.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("secret access key: %w", err)
+		}
+		sessionToken, err := settings.SessionToken.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("session token: %w", err)
+		}
+		opts = append(opts, awsconfig.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, sessionToken)))
+	}
+
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = defaultRegion
+	}
+
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if settings == nil {
+			return
+		}
+		if settings.Endpoint != "" {
+			o.BaseEndpoint = aws.String(settings.Endpoint)
+		}
+		o.UsePathStyle = settings.PathStyle
+	}), nil
+}
@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/diginfra/diginfractl/internal/config"
+	indexconfig "github.com/diginfra/diginfractl/pkg/index/config"
+)
+
+const indexContent = "- name: test\n"
+
+// newStore returns a stand-in for an S3-compatible store serving the index with path-style
+// addressing to the requests signed with the given access key ID.
+func newStore(t *testing.T, accessKeyID string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case !strings.Contains(r.Header.Get("Authorization"), "Credential="+accessKeyID+"/"):
+			w.WriteHeader(http.StatusForbidden)
+		case r.Method == http.MethodGet && r.URL.Path == "/bucket/path/index.yaml":
+			_, _ = w.Write([]byte(indexContent))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// isolate keeps the AWS configuration of the host out of the tests.
+func isolate(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(dir, "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "")
+	t.Setenv("AWS_REGION", "")
+}
+
+func TestFetchWithStaticCredentials(t *testing.T) {
+	isolate(t)
+	server := newStore(t, "static")
+	defer server.Close()
+
+	t.Setenv("TEST_SECRET_ACCESS_KEY", "secret")
+	data, err := Fetch(context.Background(), &indexconfig.Entry{
+		Name: "test",
+		URL:  "s3://bucket/path/index.yaml",
+		S3: &config.IndexS3{
+			Endpoint:        server.URL,
+			PathStyle:       true,
+			AccessKeyID:     config.Secret{Value: "static"},
+			SecretAccessKey: config.Secret{Env: "TEST_SECRET_ACCESS_KEY"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, indexContent, string(data))
+
+	_, err = Fetch(context.Background(), &indexconfig.Entry{
+		Name: "test",
+		URL:  "s3://bucket/path/missing.yaml",
+		S3: &config.IndexS3{
+			Endpoint:        server.URL,
+			PathStyle:       true,
+			AccessKeyID:     config.Secret{Value: "static"},
+			SecretAccessKey: config.Secret{Value: "secret"},
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestFetchWithCredentialChain(t *testing.T) {
+	isolate(t)
+	server := newStore(t, "environment")
+	defer server.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "environment")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	data, err := Fetch(context.Background(), &indexconfig.Entry{
+		Name: "test",
+		URL:  "s3://bucket/path/index.yaml",
+		S3: &config.IndexS3{
+			Endpoint:  server.URL,
+			Region:    "eu-west-1",
+			PathStyle: true,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, indexContent, string(data))
+}
+
+func TestS3ObjectFromURI(t *testing.T) {
+	o, err := s3ObjectFromURI("s3://bucket/path/index.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, &s3Object{Bucket: "bucket", Key: "path/index.yaml"}, o)
+
+	for _, uri := range []string{"gs://bucket/index.yaml", "s3:///index.yaml", "s3://bucket", "s3://bucket/"} {
+		_, err := s3ObjectFromURI(uri)
+		assert.Error(t, err, uri)
+	}
+}
@@ -0,0 +1,143 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/diginfra/diginfractl/pkg/index/index"
+	"github.com/diginfra/diginfractl/pkg/oci"
+	ocipuller "github.com/diginfra/diginfractl/pkg/oci/puller"
+)
+
+// Entry builds the index entry of the artifact stored in the given repository, reading the config layer and
+// the annotations of the artifact tagged as tag. The platform of multi-platform artifacts is irrelevant,
+// so the first one found is used.
+func Entry(ctx context.Context, puller *ocipuller.Puller, registry, repository, tag string) (*index.Entry, error) {
+	ref := fmt.Sprintf("%s/%s:%s", registry, repository, tag)
+
+	platforms, err := puller.Platforms(ctx, ref)
+	if err != nil {
+		return nil, err
+	}
+	var os, arch string
+	if len(platforms) > 0 {
+		os, arch, _ = strings.Cut(platforms[0], "/")
+	}
+
+	manifestBytes, err := puller.RawManifest(ctx, ref, os, arch)
+	if err != nil {
+		return nil, err
+	}
+	var manifest v1.Manifest
+	if err = json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal manifest: %w", err)
+	}
+	if len(manifest.Layers) == 0 {
+		return nil, fmt.Errorf("no layers in manifest of %q", ref)
+	}
+	artifactType := oci.HumanReadableMediaType(manifest.Layers[0].MediaType)
+	if artifactType == "" {
+		return nil, fmt.Errorf("%q is not a diginfra artifact: unknown media type %q", ref, manifest.Layers[0].MediaType)
+	}
+
+	cfg, err := puller.ArtifactConfig(ctx, ref, os, arch)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get config layer of %q: %w", ref, err)
+	}
+
+	annotations, err := puller.Annotations(ctx, ref, os, arch)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get annotations of %q: %w", ref, err)
+	}
+
+	entry := &index.Entry{
+		Name:        cfg.Name,
+		Type:        artifactType,
+		Registry:    registry,
+		Repository:  repository,
+		Description: annotations[v1.AnnotationDescription],
+		Home:        annotations[v1.AnnotationURL],
+		License:     annotations[v1.AnnotationLicenses],
+	}
+	if entry.Name == "" {
+		entry.Name = path.Base(repository)
+	}
+	if source := annotations[v1.AnnotationSource]; source != "" {
+		entry.Sources = []string{source}
+	}
+	for _, keyword := range strings.Split(annotations[oci.AnnotationKeywords], ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			entry.Keywords = append(entry.Keywords, keyword)
+		}
+	}
+
+	return entry, nil
+}
+
+// Merge merges the generated entries into an existing index and returns the generated entries that were not merged.
+// Entries are matched by location: the type of a matched entry is updated, while its other fields are only filled
+// when empty, so that hand-edited values are preserved. A generated entry whose name is already used by an entry at
+// another location clashes with it and is returned instead of being merged. Entries of the index not matching any
+// generated entry are kept.
+func Merge(idx *index.Index, entries []*index.Entry) (clashes []*index.Entry) {
+	for _, entry := range entries {
+		existing := findEntry(idx, entry)
+		if existing == nil {
+			if _, ok := idx.EntryByName(entry.Name); ok {
+				clashes = append(clashes, entry)
+				continue
+			}
+			idx.Upsert(entry)
+			continue
+		}
+
+		existing.Type = entry.Type
+		if existing.Description == "" {
+			existing.Description = entry.Description
+		}
+		if existing.Home == "" {
+			existing.Home = entry.Home
+		}
+		if existing.License == "" {
+			existing.License = entry.License
+		}
+		if len(existing.Keywords) == 0 {
+			existing.Keywords = entry.Keywords
+		}
+		if len(existing.Sources) == 0 {
+			existing.Sources = entry.Sources
+		}
+	}
+
+	return clashes
+}
+
+func findEntry(idx *index.Index, entry *index.Entry) *index.Entry {
+	for _, e := range idx.Entries {
+		if e.Registry == entry.Registry && e.Repository == entry.Repository {
+			return e
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/distribution/distribution/v3/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/diginfra/diginfractl/pkg/index/index"
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/authn"
+	ocipuller "github.com/diginfra/diginfractl/pkg/oci/puller"
+	ocipusher "github.com/diginfra/diginfractl/pkg/oci/pusher"
+	ociregistry "github.com/diginfra/diginfractl/pkg/oci/registry"
+	testutils "github.com/diginfra/diginfractl/pkg/test"
+)
+
+const rulesfiletgz = "../../test/data/rules.tar.gz"
+
+func startRegistry(t *testing.T) string {
+	registryConfig := &configuration.Configuration{}
+	// The catalog API returns no repositories unless the number of entries is set.
+	registryConfig.Catalog.MaxEntries = 100
+	return testutils.StartTestRegistry(t, registryConfig)
+}
+
+func TestEntry(t *testing.T) {
+	ctx := context.Background()
+	reg := startRegistry(t)
+	client := authn.NewClient(authn.WithCredentials(&auth.EmptyCredential))
+	pusher := ocipusher.NewPusher(client, true, nil)
+	puller := ocipuller.NewPuller(client, true, nil)
+
+	_, err := pusher.Push(ctx, oci.Rulesfile, reg+"/myorg/diginfra/rules:latest",
+		ocipusher.WithFilepaths([]string{rulesfiletgz}),
+		ocipusher.WithArtifactConfig(oci.ArtifactConfig{Name: "myorg-rules", Version: "1.0.0"}),
+		ocipusher.WithAnnotationSource("https://github.com/myorg/rules"))
+	require.NoError(t, err)
+
+	_, err = pusher.Push(ctx, oci.Plugin, reg+"/myorg/diginfra/myplugin:latest",
+		ocipusher.WithFilepathsAndPlatforms([]string{rulesfiletgz, rulesfiletgz}, []string{"linux/arm64", "linux/amd64"}),
+		ocipusher.WithArtifactConfig(oci.ArtifactConfig{Version: "0.1.0"}))
+	require.NoError(t, err)
+
+	indexPath := filepath.Join(t.TempDir(), "index.yaml")
+	require.NoError(t, os.WriteFile(indexPath, []byte("[]\n"), 0o600))
+	_, err = pusher.PushIndex(ctx, reg+"/other/index:latest", indexPath)
+	require.NoError(t, err)
+
+	registry, err := ociregistry.NewRegistry(reg, ociregistry.WithClient(client), ociregistry.WithPlainHTTP(true))
+	require.NoError(t, err)
+	repos, err := registry.Repositories(ctx, "myorg/diginfra/")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"myorg/diginfra/myplugin", "myorg/diginfra/rules"}, repos)
+
+	entry, err := Entry(ctx, puller, reg, "myorg/diginfra/rules", oci.DefaultTag)
+	require.NoError(t, err)
+	assert.Equal(t, &index.Entry{
+		Name:       "myorg-rules",
+		Type:       string(oci.Rulesfile),
+		Registry:   reg,
+		Repository: "myorg/diginfra/rules",
+		Sources:    []string{"https://github.com/myorg/rules"},
+	}, entry)
+
+	// The name defaults to the one of the repository.
+	entry, err = Entry(ctx, puller, reg, "myorg/diginfra/myplugin", oci.DefaultTag)
+	require.NoError(t, err)
+	assert.Equal(t, "myplugin", entry.Name)
+	assert.Equal(t, string(oci.Plugin), entry.Type)
+
+	// Artifacts that are not rulesfiles, plugins or assets are refused.
+	_, err = Entry(ctx, puller, reg, "other/index", oci.DefaultTag)
+	assert.Error(t, err)
+}
+
+func TestMerge(t *testing.T) {
+	idx := index.New("test")
+	require.NoError(t, idx.ReadBytes([]byte(`
+- name: rules
+  type: rulesfile
+  registry: ghcr.io
+  repository: old/rules
+  description: hand-written description
+  keywords: [custom]
+  signature:
+    cosign:
+      key: cosign.pub
+- name: renamed
+  type: plugin
+  registry: ghcr.io
+  repository: myorg/plugin
+- name: manual
+  type: plugin
+  registry: docker.io
+  repository: manual/plugin
+`)))
+
+	clashes := Merge(idx, []*index.Entry{
+		{
+			Name: "rules", Type: "rulesfile", Registry: "ghcr.io", Repository: "myorg/rules",
+			Description: "generated description", Keywords: []string{"generated"}, License: "Apache-2.0",
+		},
+		{Name: "plugin", Type: "plugin", Registry: "ghcr.io", Repository: "myorg/plugin", Home: "https://example.com"},
+		{Name: "new", Type: "asset", Registry: "ghcr.io", Repository: "myorg/new"},
+		{Name: "new", Type: "asset", Registry: "ghcr.io", Repository: "otherorg/new"},
+	})
+
+	require.Len(t, clashes, 2)
+	assert.Equal(t, "myorg/rules", clashes[0].Repository)
+	assert.Equal(t, "otherorg/new", clashes[1].Repository)
+	require.Len(t, idx.Entries, 4)
+
+	rules, ok := idx.EntryByName("rules")
+	require.True(t, ok)
+	assert.Equal(t, "old/rules", rules.Repository)
+	assert.Equal(t, "hand-written description", rules.Description)
+	assert.Equal(t, []string{"custom"}, rules.Keywords)
+	assert.Empty(t, rules.License)
+	require.NotNil(t, rules.Signature)
+	assert.Equal(t, "cosign.pub", rules.Signature.Cosign.KeyRef)
+
+	renamed, ok := idx.EntryByName("renamed")
+	require.True(t, ok)
+	assert.Equal(t, "https://example.com", renamed.Home)
+	_, ok = idx.EntryByName("plugin")
+	assert.False(t, ok)
+
+	_, ok = idx.EntryByName("manual")
+	assert.True(t, ok)
+	entry, ok := idx.EntryByName("new")
+	require.True(t, ok)
+	assert.Equal(t, "myorg/new", entry.Repository)
+	assert.NoError(t, idx.Normalize())
+}
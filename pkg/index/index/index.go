@@ -28,6 +28,8 @@ import (
 	"gopkg.in/yaml.v3"
 	"oras.land/oras-go/v2/registry"
 
+	diginfractlconfig "github.com/diginfra/diginfractl/internal/config"
+	"github.com/diginfra/diginfractl/internal/lockedfile"
 	"github.com/diginfra/diginfractl/pkg/index/config"
 	"github.com/diginfra/diginfractl/pkg/oci"
 )
@@ -47,6 +49,12 @@ type Entry struct {
 	License     string     `yaml:"license"`
 	Maintainers Maintainer `yaml:"maintainers"`
 	Sources     []string   `yaml:"sources"`
+	// Lifecycle fields, see lifecycle.go
+	Versions   []Version  `yaml:"versions,omitempty"`
+	Deprecated bool       `yaml:"deprecated,omitempty"`
+	ReplacedBy string     `yaml:"replaced-by,omitempty"`
+	EndOfLife  string     `yaml:"end-of-life,omitempty"`
+	Advisories []Advisory `yaml:"advisories,omitempty"`
 }
 
 // Maintainer represents an index maintainer.
@@ -72,10 +80,22 @@ type Signature struct {
 	Cosign *CosignSignature `yaml:"cosign,omitempty"`
 }
 
+// SignatureFromConfig returns the signature described by the configuration of an artifact, or nil if none.
+func SignatureFromConfig(sig *diginfractlconfig.Signature) *Signature {
+	if sig == nil || sig.Cosign == nil {
+		return nil
+	}
+
+	cosign := CosignSignature(*sig.Cosign)
+	return &Signature{Cosign: &cosign}
+}
+
 // Index represents an index.
 type Index struct {
-	Name        string
-	Entries     []*Entry
+	Name    string
+	Entries []*Entry
+	// Priority decides which index provides an entry when several merged indexes have entries with the same name.
+	Priority    int
 	entryByName map[string]*Entry
 }
 
@@ -83,8 +103,24 @@ type Index struct {
 type MergedIndexes struct {
 	Index
 	indexByEntry map[*Entry]*Index
+	indexByName  map[string]*Index
+	// providersByName tracks, for each entry name, the indexes having an entry with that name.
+	providersByName map[string][]*Index
+}
+
+// Shadowing describes an entry name provided by several indexes.
+type Shadowing struct {
+	// Name is the name of the entry.
+	Name string
+	// Index is the index providing the entry resolved by the bare name.
+	Index string
+	// Shadowed are the other indexes having an entry with the same name.
+	Shadowed []string
 }
 
+// indexQualifier separates a name from the index providing it, as in "k8saudit-rules@index=myorg".
+const indexQualifier = "@index="
+
 // New returns a new empty Index.
 func New(name string) *Index {
 	return &Index{
@@ -162,7 +198,7 @@ func (i *Index) Write(path string) error {
 	// Get dir path.
 	dir, _ := filepath.Split(path)
 	// Create directory if it does not exist.
-	if _, err := os.Stat(dir); errors.Is(err, fs.ErrNotExist) {
+	if _, err := os.Stat(dir); dir != "" && errors.Is(err, fs.ErrNotExist) {
 		err = os.MkdirAll(dir, config.DefaultDirPermissions) // #nosec G301 //we want 755 permissions
 		if err != nil {
 			return err
@@ -177,7 +213,7 @@ func (i *Index) Write(path string) error {
 		return fmt.Errorf("cannot marshal index: %w", err)
 	}
 
-	if err = os.WriteFile(path, indexBytes, config.DefaultFilePermissions); err != nil {
+	if err = lockedfile.WriteFile(path, indexBytes, config.DefaultFilePermissions); err != nil {
 		return fmt.Errorf("cannot write index to file: %w", err)
 	}
 
@@ -217,19 +253,57 @@ func NewMergedIndexes() *MergedIndexes {
 
 	m.entryByName = make(map[string]*Entry)
 	m.indexByEntry = make(map[*Entry]*Index)
+	m.indexByName = make(map[string]*Index)
+	m.providersByName = make(map[string][]*Index)
 
 	return m
 }
 
 // Merge creates a new index by merging all the indexes that are passed.
-// Orders matters. Be sure to pass an ordered list of indexes. For our use case, sort by added time.
+// When several indexes have entries with the same name, the entry of the index with the highest priority is used.
+// Among indexes with the same priority, order matters: the last one wins. For our use case, sort by added time.
+// The other entries can still be resolved with names qualified by their index, see ResolveReference.
 func (m *MergedIndexes) Merge(indexes ...*Index) {
 	for _, index := range indexes {
-		for _, Entry := range index.Entries {
-			m.Upsert(Entry)
-			m.indexByEntry[Entry] = index
+		m.indexByName[index.Name] = index
+		for _, entry := range index.Entries {
+			m.providersByName[entry.Name] = append(m.providersByName[entry.Name], index)
+			if existing, ok := m.EntryByName(entry.Name); ok {
+				if m.indexByEntry[existing].Priority > index.Priority {
+					continue
+				}
+				delete(m.indexByEntry, existing)
+			}
+			m.Upsert(entry)
+			m.indexByEntry[entry] = index
+		}
+	}
+}
+
+// Shadowings returns the entry names provided by several indexes, sorted by name.
+func (m *MergedIndexes) Shadowings() []Shadowing {
+	var shadowings []Shadowing
+	for name, providers := range m.providersByName {
+		if len(providers) < 2 {
+			continue
+		}
+
+		entry, _ := m.EntryByName(name)
+		winner := m.indexByEntry[entry]
+		shadowing := Shadowing{Name: name, Index: winner.Name}
+		for _, idx := range providers {
+			if idx != winner {
+				shadowing.Shadowed = append(shadowing.Shadowed, idx.Name)
+			}
 		}
+		shadowings = append(shadowings, shadowing)
 	}
+
+	sort.Slice(shadowings, func(i, j int) bool {
+		return shadowings[i].Name < shadowings[j].Name
+	})
+
+	return shadowings
 }
 
 // SearchByKeywords search for entries matching the given keywords in MergedIndexes.
@@ -271,21 +345,26 @@ func (m *MergedIndexes) IndexByEntry(entry *Entry) *Index {
 func (m *MergedIndexes) SignatureForIndexRef(name string) *Signature {
 	_, err := registry.ParseReference(name)
 	// If we have a full reference we cannot determine the signature
-	if err == nil {
+	if err == nil && !m.isQualified(name) {
 		return nil
 	}
 
-	entryName, _, _, err := parseIndexRef(name)
+	entry, _, _, err := m.lookup(name)
 	if err != nil {
 		return nil
 	}
 
-	entry, ok := m.EntryByName(entryName)
-	if !ok {
-		return nil
+	return entry.Signature
+}
+
+// ResolveEntry returns the entry of the given name, optionally qualified by its index, without tag or digest.
+func (m *MergedIndexes) ResolveEntry(name string) (*Entry, bool) {
+	entry, tag, digest, err := m.lookup(name)
+	if err != nil || tag != "" || digest != "" {
+		return nil, false
 	}
 
-	return entry.Signature
+	return entry, true
 }
 
 // ResolveReference is a helper function that parse with the following logic:
@@ -302,31 +381,29 @@ func (m *MergedIndexes) SignatureForIndexRef(name string) *Signature {
 //     e.g. "ghcr.io/diginfra/plugins/cloudtrail" -> "ghcr.io/diginfra/plugins/cloudtrail:latest"
 //
 //  3. if name is a complete reference, it will be returned as is.
+//
+// Names can be qualified with the index providing them, to use an entry shadowed by another
+// index, either as INDEX/NAME or as NAME@index=INDEX, followed or preceded by the tag or the digest:
+// e.g. "myorg/k8saudit-rules:0.7.0" or "k8saudit-rules:0.7.0@index=myorg".
 func (m *MergedIndexes) ResolveReference(name string) (string, error) {
+	if m.isQualified(name) {
+		entry, tag, digest, err := m.lookup(name)
+		if err != nil {
+			return "", err
+		}
+		return entryReference(entry, tag, digest), nil
+	}
+
 	parsedRef, err := registry.ParseReference(name)
 	var ref string
 
 	switch {
 	case err != nil:
-		entryName, tag, digest, err := parseIndexRef(name)
+		entry, tag, digest, err := m.lookup(name)
 		if err != nil {
 			return "", err
 		}
-
-		entry, ok := m.EntryByName(entryName)
-		if !ok {
-			return "", fmt.Errorf("cannot find %s among the configured indexes, skipping", name)
-		}
-
-		ref = fmt.Sprintf("%s/%s", entry.Registry, entry.Repository)
-		switch {
-		case tag == "" && digest == "":
-			ref += ":" + oci.DefaultTag
-		case tag != "":
-			ref += ":" + tag
-		case digest != "":
-			ref += "@" + digest
-		}
+		ref = entryReference(entry, tag, digest)
 
 	case parsedRef.Reference == "":
 		parsedRef.Reference = oci.DefaultTag
@@ -339,6 +416,70 @@ func (m *MergedIndexes) ResolveReference(name string) (string, error) {
 	return ref, nil
 }
 
+// isQualified returns true if the name is qualified with the index providing it. Names in the INDEX/NAME form
+// are only qualified if INDEX is one of the merged indexes, since they could be references otherwise.
+func (m *MergedIndexes) isQualified(name string) bool {
+	if strings.Contains(name, indexQualifier) {
+		return true
+	}
+	prefix, _, found := strings.Cut(name, "/")
+	_, known := m.indexByName[prefix]
+	return found && known
+}
+
+// lookup returns the entry of the given name, optionally qualified by its index, with the tag or the digest.
+func (m *MergedIndexes) lookup(name string) (entry *Entry, tag, digest string, err error) {
+	var indexName string
+	fullName := name
+	switch {
+	case strings.Contains(name, indexQualifier):
+		i := strings.LastIndex(name, indexQualifier)
+		name, indexName = name[:i], name[i+len(indexQualifier):]
+	case m.isQualified(name):
+		indexName, name, _ = strings.Cut(name, "/")
+	}
+
+	entryName, tag, digest, err := parseIndexRef(name)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	if indexName == "" {
+		entry, ok := m.EntryByName(entryName)
+		if !ok {
+			return nil, "", "", fmt.Errorf("cannot find %s among the configured indexes, skipping%s",
+				fullName, didYouMean(suggestions(m.Entries, entryName, maxSuggestions)))
+		}
+		return entry, tag, digest, nil
+	}
+
+	idx, ok := m.indexByName[indexName]
+	if !ok {
+		return nil, "", "", fmt.Errorf("cannot find index %q among the configured indexes", indexName)
+	}
+	if entry, ok = idx.EntryByName(entryName); !ok {
+		return nil, "", "", fmt.Errorf("cannot find %s in index %q, skipping%s",
+			entryName, indexName, didYouMean(suggestions(idx.Entries, entryName, maxSuggestions)))
+	}
+
+	return entry, tag, digest, nil
+}
+
+// entryReference returns the reference of the artifact of an entry, with the given tag or digest.
+func entryReference(entry *Entry, tag, digest string) string {
+	ref := fmt.Sprintf("%s/%s", entry.Registry, entry.Repository)
+	switch {
+	case tag == "" && digest == "":
+		ref += ":" + oci.DefaultTag
+	case tag != "":
+		ref += ":" + tag
+	case digest != "":
+		ref += "@" + digest
+	}
+
+	return ref
+}
+
 func parseIndexRef(name string) (entryName, tag, digest string, err error) {
 	switch {
 	case !strings.ContainsAny(name, ":@"):
@@ -22,6 +22,7 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	diginfractlconfig "github.com/diginfra/diginfractl/internal/config"
 	"github.com/diginfra/diginfractl/pkg/index/config"
 )
 
@@ -135,6 +136,91 @@ func TestMerge(t *testing.T) {
 	}
 }
 
+func TestMergePriority(t *testing.T) {
+	community := New("community")
+	community.Upsert(&Entry{Name: "k8saudit-rules", Registry: "ghcr.io", Repository: "diginfra/rules/k8saudit"})
+	community.Upsert(&Entry{Name: "okta", Registry: "ghcr.io", Repository: "diginfra/plugins/okta"})
+	mirror := New("myorg")
+	mirror.Upsert(&Entry{Name: "k8saudit-rules", Registry: "registry.myorg.com", Repository: "mirror/k8saudit"})
+	mirror.Priority = -1
+
+	mergedIndex := NewMergedIndexes()
+	mergedIndex.Merge(community, mirror)
+
+	// The mirror comes last but has a lower priority.
+	entry, ok := mergedIndex.EntryByName("k8saudit-rules")
+	if !ok || mergedIndex.IndexByEntry(entry).Name != "community" {
+		t.Errorf("the entry of the index with the highest priority should be used")
+	}
+	if len(mergedIndex.Entries) != 2 {
+		t.Errorf("shadowed entries should not be listed, got %d entries", len(mergedIndex.Entries))
+	}
+
+	shadowings := mergedIndex.Shadowings()
+	if len(shadowings) != 1 || shadowings[0].Name != "k8saudit-rules" || shadowings[0].Index != "community" ||
+		len(shadowings[0].Shadowed) != 1 || shadowings[0].Shadowed[0] != "myorg" {
+		t.Errorf("unexpected shadowings: %+v", shadowings)
+	}
+
+	// With the same priority the last index wins.
+	mirror.Priority = 0
+	mergedIndex = NewMergedIndexes()
+	mergedIndex.Merge(community, mirror)
+	entry, _ = mergedIndex.EntryByName("k8saudit-rules")
+	if mergedIndex.IndexByEntry(entry).Name != "myorg" {
+		t.Errorf("the entry of the last index should be used")
+	}
+}
+
+func TestResolveQualifiedReference(t *testing.T) {
+	community := New("community")
+	community.Upsert(&Entry{Name: "k8saudit-rules", Registry: "ghcr.io", Repository: "diginfra/rules/k8saudit"})
+	mirror := New("myorg")
+	mirror.Upsert(&Entry{Name: "k8saudit-rules", Registry: "registry.myorg.com", Repository: "mirror/k8saudit"})
+
+	mergedIndex := NewMergedIndexes()
+	mergedIndex.Merge(community, mirror)
+
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{"k8saudit-rules", "registry.myorg.com/mirror/k8saudit:latest"},
+		{"community/k8saudit-rules", "ghcr.io/diginfra/rules/k8saudit:latest"},
+		{"community/k8saudit-rules:0.7.0", "ghcr.io/diginfra/rules/k8saudit:0.7.0"},
+		{"k8saudit-rules@index=community", "ghcr.io/diginfra/rules/k8saudit:latest"},
+		{"k8saudit-rules:0.7.0@index=community", "ghcr.io/diginfra/rules/k8saudit:0.7.0"},
+		{"k8saudit-rules@sha256:abc@index=community", "ghcr.io/diginfra/rules/k8saudit@sha256:abc"},
+		{"myorg/k8saudit-rules", "registry.myorg.com/mirror/k8saudit:latest"},
+		// Names whose prefix is not an index are references.
+		{"ghcr.io/diginfra/plugins/okta", "ghcr.io/diginfra/plugins/okta:latest"},
+	}
+
+	for _, tc := range testCases {
+		ref, err := mergedIndex.ResolveReference(tc.name)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.name, err)
+		} else if ref != tc.expected {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.expected, ref)
+		}
+	}
+
+	for _, name := range []string{"community/okta", "k8saudit-rules@index=missing"} {
+		if _, err := mergedIndex.ResolveReference(name); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	community.Entries[0].Signature = &Signature{Cosign: &CosignSignature{KeyRef: "cosign.pub"}}
+	if sig := mergedIndex.SignatureForIndexRef("community/k8saudit-rules:0.7.0"); sig == nil || sig.Cosign.KeyRef != "cosign.pub" {
+		t.Errorf("cannot retrieve the signature of a qualified name")
+	}
+
+	if entry, ok := mergedIndex.ResolveEntry("community/k8saudit-rules"); !ok || entry.Registry != "ghcr.io" {
+		t.Errorf("cannot resolve the entry of a qualified name")
+	}
+}
+
 func TestSearchByKeywords(t *testing.T) {
 	i := New("name")
 
@@ -241,3 +327,24 @@ func TestConfig(t *testing.T) {
 		t.Error(fmt.Errorf("entry \"test\" not found"))
 	}
 }
+
+func TestSignatureFromConfig(t *testing.T) {
+	if sig := SignatureFromConfig(nil); sig != nil {
+		t.Errorf("expected no signature, got %v", sig)
+	}
+	if sig := SignatureFromConfig(&diginfractlconfig.Signature{}); sig != nil {
+		t.Errorf("expected no signature, got %v", sig)
+	}
+
+	sig := SignatureFromConfig(&diginfractlconfig.Signature{Cosign: &diginfractlconfig.CosignSignature{
+		CertificateIdentity: "https://github.com/diginfra/rules/.github/workflows/release.yaml@refs/heads/main",
+		KeyRef:              "cosign.pub",
+	}})
+	if sig == nil || sig.Cosign == nil {
+		t.Fatal("expected a cosign signature")
+	}
+	if sig.Cosign.CertificateIdentity != "https://github.com/diginfra/rules/.github/workflows/release.yaml@refs/heads/main" ||
+		sig.Cosign.KeyRef != "cosign.pub" {
+		t.Errorf("unexpected signature %+v", sig.Cosign)
+	}
+}
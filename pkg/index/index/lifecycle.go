@@ -0,0 +1,250 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"oras.land/oras-go/v2/registry"
+)
+
+// DateLayout is the layout of the end-of-life dates of the entries.
+const DateLayout = "2006-01-02"
+
+// Version describes a single version of the artifact of an entry.
+type Version struct {
+	Version    string `yaml:"version"`
+	Deprecated bool   `yaml:"deprecated,omitempty"`
+	EndOfLife  string `yaml:"end-of-life,omitempty"`
+}
+
+// Advisory describes a security advisory affecting some versions of the artifact of an entry.
+type Advisory struct {
+	ID       string `yaml:"id"`
+	Severity string `yaml:"severity,omitempty"`
+	Summary  string `yaml:"summary,omitempty"`
+	URL      string `yaml:"url,omitempty"`
+	// Affected are the ranges of the affected versions, such as ">=0.5.0 <0.5.3". A version is
+	// affected if it matches any of them.
+	Affected []string `yaml:"affected"`
+	// Fixed is the first version not affected, if any.
+	Fixed string `yaml:"fixed,omitempty"`
+}
+
+// NoticeKind is the kind of a lifecycle notice.
+type NoticeKind string
+
+const (
+	// NoticeDeprecated is the kind of the notices of deprecated artifacts and versions.
+	NoticeDeprecated NoticeKind = "deprecated"
+	// NoticeEndOfLife is the kind of the notices of artifacts and versions that reached their end of life.
+	NoticeEndOfLife NoticeKind = "end-of-life"
+	// NoticeAdvisory is the kind of the notices of versions affected by a security advisory.
+	NoticeAdvisory NoticeKind = "advisory"
+)
+
+// Notice warns about the use of a version of an artifact.
+type Notice struct {
+	Kind    NoticeKind
+	Message string
+}
+
+// String returns the message of the notice.
+func (n Notice) String() string {
+	return n.Message
+}
+
+// VersionPolicy tells what to do when the selected version of an artifact has lifecycle notices.
+type VersionPolicy string
+
+const (
+	// VersionPolicyWarn logs the notices and goes on.
+	VersionPolicyWarn VersionPolicy = "warn"
+	// VersionPolicyRefuse refuses the versions having notices.
+	VersionPolicyRefuse VersionPolicy = "refuse"
+)
+
+// VersionPolicies are the supported version policies.
+var VersionPolicies = []string{string(VersionPolicyWarn), string(VersionPolicyRefuse)}
+
+// HasLifecycle returns true if the entry has lifecycle metadata.
+func (e *Entry) HasLifecycle() bool {
+	return len(e.Versions) > 0 || e.Deprecated || e.EndOfLife != "" || len(e.Advisories) > 0
+}
+
+// Notices returns the lifecycle notices concerning the given version of the entry at time now.
+// The version can be empty if not known, in which case only the notices of the whole entry are returned.
+// Malformed dates and version ranges are ignored, they are reported by "index lint".
+func (e *Entry) Notices(version string, now time.Time) []Notice {
+	var notices []Notice
+
+	if e.Deprecated {
+		msg := fmt.Sprintf("%s is deprecated", e.Name)
+		if e.ReplacedBy != "" {
+			msg += fmt.Sprintf(", use %s instead", e.ReplacedBy)
+		}
+		notices = append(notices, Notice{Kind: NoticeDeprecated, Message: msg})
+	}
+	if ended(e.EndOfLife, now) {
+		notices = append(notices, Notice{Kind: NoticeEndOfLife,
+			Message: fmt.Sprintf("%s reached its end of life on %s", e.Name, e.EndOfLife)})
+	}
+
+	if version == "" {
+		return notices
+	}
+
+	if v := e.version(version); v != nil {
+		if v.Deprecated {
+			notices = append(notices, Notice{Kind: NoticeDeprecated,
+				Message: fmt.Sprintf("version %s of %s is deprecated", version, e.Name)})
+		}
+		if ended(v.EndOfLife, now) {
+			notices = append(notices, Notice{Kind: NoticeEndOfLife,
+				Message: fmt.Sprintf("version %s of %s reached its end of life on %s", version, e.Name, v.EndOfLife)})
+		}
+	}
+
+	for i := range e.Advisories {
+		if a := &e.Advisories[i]; a.Affects(version) {
+			notices = append(notices, Notice{Kind: NoticeAdvisory,
+				Message: fmt.Sprintf("version %s of %s is affected by %s", version, e.Name, a.describe())})
+		}
+	}
+
+	return notices
+}
+
+// CheckVersion returns the lifecycle notices of the given version of the entry at time now, and an
+// error listing them if there is any and the policy is VersionPolicyRefuse.
+func (e *Entry) CheckVersion(version string, policy VersionPolicy, now time.Time) ([]Notice, error) {
+	notices := e.Notices(version, now)
+	if len(notices) == 0 || policy != VersionPolicyRefuse {
+		return notices, nil
+	}
+
+	messages := make([]string, len(notices))
+	for i := range notices {
+		messages[i] = notices[i].Message
+	}
+	if version == "" {
+		version = "unknown"
+	}
+
+	return notices, fmt.Errorf("refusing version %s of %s: %s", version, e.Name, strings.Join(messages, "; "))
+}
+
+// version returns the description of the given version, or nil if none.
+func (e *Entry) version(version string) *Version {
+	parsed, parseErr := semver.ParseTolerant(version)
+	for i := range e.Versions {
+		v := &e.Versions[i]
+		if v.Version == version {
+			return v
+		}
+		if other, err := semver.ParseTolerant(v.Version); parseErr == nil && err == nil && other.EQ(parsed) {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// Affects returns true if the version is in the affected ranges of the advisory.
+// Versions that are not semantic versions are never affected.
+func (a *Advisory) Affects(version string) bool {
+	v, err := semver.ParseTolerant(version)
+	if err != nil {
+		return false
+	}
+
+	for _, affected := range a.Affected {
+		r, err := semver.ParseRange(affected)
+		if err == nil && r(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// describe returns a one-line description of the advisory.
+func (a *Advisory) describe() string {
+	desc := a.ID
+	if a.Severity != "" {
+		desc += fmt.Sprintf(" (%s)", a.Severity)
+	}
+	if a.Summary != "" {
+		desc += ": " + a.Summary
+	}
+	if a.Fixed != "" {
+		desc += fmt.Sprintf(", fixed in %s", a.Fixed)
+	}
+	if a.URL != "" {
+		desc += fmt.Sprintf(", see %s", a.URL)
+	}
+
+	return desc
+}
+
+// ended returns true if date is a valid date not after now.
+func ended(date string, now time.Time) bool {
+	if date == "" {
+		return false
+	}
+
+	t, err := time.Parse(DateLayout, date)
+	return err == nil && !t.After(now)
+}
+
+// EntryByReference returns the entry whose artifact is referenced by ref, ignoring the tag or the digest.
+// The entries resolved by their bare name are preferred over the shadowed ones.
+func (m *MergedIndexes) EntryByReference(ref string) (*Entry, bool) {
+	parsed, err := registry.ParseReference(ref)
+	if err != nil {
+		return nil, false
+	}
+
+	match := func(entries []*Entry) (*Entry, bool) {
+		for _, entry := range entries {
+			if entry.Registry == parsed.Registry && entry.Repository == parsed.Repository {
+				return entry, true
+			}
+		}
+		return nil, false
+	}
+
+	if entry, ok := match(m.Entries); ok {
+		return entry, true
+	}
+
+	names := make([]string, 0, len(m.indexByName))
+	for name := range m.indexByName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if entry, ok := match(m.indexByName[name].Entries); ok {
+			return entry, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,146 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func lifecycleTestEntry() *Entry {
+	return &Entry{
+		Name:       "k8saudit-rules",
+		Registry:   "ghcr.io",
+		Repository: "diginfra/rules/k8saudit-rules",
+		Versions: []Version{
+			{Version: "0.5.0", Deprecated: true, EndOfLife: "2024-01-31"},
+			{Version: "0.6.0", EndOfLife: "2030-01-31"},
+			{Version: "0.7.0"},
+		},
+		Advisories: []Advisory{{
+			ID:       "GHSA-1234",
+			Severity: "high",
+			Summary:  "rules can be bypassed",
+			Affected: []string{">=0.5.0 <0.6.1", "0.7.0-rc1"},
+			Fixed:    "0.6.1",
+		}},
+	}
+}
+
+func noticeKinds(notices []Notice) string {
+	kinds := make([]string, len(notices))
+	for i := range notices {
+		kinds[i] = string(notices[i].Kind)
+	}
+	return strings.Join(kinds, ",")
+}
+
+func TestNotices(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		deprecated bool
+		endOfLife  string
+		version    string
+		expected   string
+	}{
+		{name: "healthy version", version: "0.7.0", expected: ""},
+		{name: "deprecated, ended and affected version", version: "0.5.0", expected: "deprecated,end-of-life,advisory"},
+		{name: "future end of life", version: "0.6.0", expected: "advisory"},
+		{name: "tolerant versions", version: "v0.6", expected: "advisory"},
+		{name: "single version range", version: "0.7.0-rc1", expected: "advisory"},
+		{name: "unknown version", version: "", expected: ""},
+		{name: "not a semantic version", version: "latest", expected: ""},
+		{name: "deprecated entry", deprecated: true, version: "", expected: "deprecated"},
+		{name: "ended entry", endOfLife: "2025-06-01", version: "0.7.0", expected: "end-of-life"},
+		{name: "malformed end of life", endOfLife: "June 2025", version: "0.7.0", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry := lifecycleTestEntry()
+			entry.Deprecated = tc.deprecated
+			entry.EndOfLife = tc.endOfLife
+			if got := noticeKinds(entry.Notices(tc.version, now)); got != tc.expected {
+				t.Errorf("expected notices %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestNoticeMessages(t *testing.T) {
+	entry := lifecycleTestEntry()
+	entry.Deprecated = true
+	entry.ReplacedBy = "k8saudit-rules-v2"
+
+	notices := entry.Notices("0.6.0", time.Now())
+	if len(notices) != 2 {
+		t.Fatalf("expected 2 notices, got %v", notices)
+	}
+	if expected := "k8saudit-rules is deprecated, use k8saudit-rules-v2 instead"; notices[0].Message != expected {
+		t.Errorf("expected %q, got %q", expected, notices[0].Message)
+	}
+	expected := "version 0.6.0 of k8saudit-rules is affected by GHSA-1234 (high): rules can be bypassed, fixed in 0.6.1"
+	if notices[1].Message != expected {
+		t.Errorf("expected %q, got %q", expected, notices[1].Message)
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	entry := lifecycleTestEntry()
+
+	notices, err := entry.CheckVersion("0.6.0", VersionPolicyWarn, time.Now())
+	if err != nil || len(notices) != 1 {
+		t.Errorf("expected a notice and no error with the warn policy, got %v and %v", notices, err)
+	}
+
+	_, err = entry.CheckVersion("0.6.0", VersionPolicyRefuse, time.Now())
+	if err == nil || !strings.HasPrefix(err.Error(), "refusing version 0.6.0 of k8saudit-rules: ") {
+		t.Errorf("expected the version to be refused, got %v", err)
+	}
+
+	if _, err = entry.CheckVersion("0.7.0", VersionPolicyRefuse, time.Now()); err != nil {
+		t.Errorf("expected a healthy version to be accepted, got %v", err)
+	}
+}
+
+func TestEntryByReference(t *testing.T) {
+	community := New("community")
+	community.Upsert(lifecycleTestEntry())
+	mirror := New("mirror")
+	mirror.Upsert(&Entry{Name: "k8saudit-rules", Registry: "registry.example.com", Repository: "rules/k8saudit-rules"})
+
+	m := NewMergedIndexes()
+	m.Merge(community, mirror)
+
+	for ref, expected := range map[string]string{
+		"ghcr.io/diginfra/rules/k8saudit-rules:0.6.0":                             "ghcr.io",
+		"ghcr.io/diginfra/rules/k8saudit-rules@sha256:" + strings.Repeat("a", 64): "ghcr.io",
+		"registry.example.com/rules/k8saudit-rules:latest":                        "registry.example.com",
+		"ghcr.io/diginfra/rules/other:latest":                                     "",
+		"not a reference":                                                         "",
+	} {
+		entry, ok := m.EntryByReference(ref)
+		switch {
+		case expected == "" && ok:
+			t.Errorf("expected no entry for %q, got %v", ref, entry)
+		case expected != "" && (!ok || entry.Registry != expected):
+			t.Errorf("expected the entry on %q for %q, got %v", expected, ref, entry)
+		}
+	}
+}
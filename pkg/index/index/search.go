@@ -0,0 +1,252 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Weights of the matches of a search term in the fields of an entry. The score of a term is the one of its best match.
+const (
+	nameExactWeight        = 10.0
+	nameWeight             = 6.0
+	keywordExactWeight     = 5.0
+	keywordWeight          = 3.0
+	descriptionExactWeight = 2.0
+	descriptionWeight      = 1.0
+	maintainerWeight       = 1.0
+)
+
+// maxSuggestions is the number of similar names suggested when a name cannot be resolved.
+const maxSuggestions = 3
+
+// SearchFilter restricts the entries returned by a search. Empty fields match every entry.
+type SearchFilter struct {
+	// Type is the type of the artifacts.
+	Type string
+	// License is the license of the artifacts, compared case-insensitively.
+	License string
+	// Maintainer is a part of the name or of the email of a maintainer of the artifacts.
+	Maintainer string
+	// Index is the name of the index providing the artifacts. Entries shadowed by other indexes are
+	// only returned when it is set.
+	Index string
+}
+
+// SearchResult is an entry matching a search, with the index providing it and its relevance.
+type SearchResult struct {
+	Entry *Entry
+	Index *Index
+	Score float64
+}
+
+// Search returns the entries matching the filter and at least one of the terms, sorted by relevance. The terms
+// are matched against the names, the keywords, the descriptions and the maintainers of the entries. Names are also
+// matched approximately: minScore is the minimum similarity, between 0 and 1, to consider that a term matches a name.
+// If no term is given, all the entries matching the filter are returned, sorted by name.
+func (m *MergedIndexes) Search(minScore float64, filter SearchFilter, terms ...string) []SearchResult {
+	var results []SearchResult
+	for _, idx := range m.searchedIndexes(filter.Index) {
+		for _, entry := range idx.Entries {
+			// Unless an index is given, only the entries resolved by their bare names are searched.
+			if filter.Index == "" && m.indexByEntry[entry] != idx {
+				continue
+			}
+			if !filter.matches(entry) {
+				continue
+			}
+
+			var total float64
+			for _, term := range terms {
+				total += termScore(entry, strings.ToLower(term), minScore)
+			}
+			if len(terms) > 0 && total == 0 {
+				continue
+			}
+			results = append(results, SearchResult{Entry: entry, Index: idx, Score: total})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Entry.Name < results[j].Entry.Name
+	})
+
+	return results
+}
+
+// Suggestions returns up to n names of entries similar to the given one, the most similar first.
+func (m *MergedIndexes) Suggestions(name string, n int) []string {
+	return suggestions(m.Entries, name, n)
+}
+
+func suggestions(entries []*Entry, name string, n int) []string {
+	const minSuggestionScore = 0.5
+
+	name = strings.ToLower(name)
+	if name == "" {
+		return nil
+	}
+
+	type candidate struct {
+		name  string
+		score float64
+	}
+	var candidates []candidate
+	for _, entry := range entries {
+		s := nameScore(strings.ToLower(entry.Name), name)
+		if s >= minSuggestionScore {
+			candidates = append(candidates, candidate{name: entry.Name, score: s})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		return candidates[i].name < candidates[j].name
+	})
+
+	var names []string
+	for i := 0; i < len(candidates) && i < n; i++ {
+		names = append(names, candidates[i].name)
+	}
+	return names
+}
+
+// searchedIndexes returns the merged indexes to search, in merge order.
+func (m *MergedIndexes) searchedIndexes(name string) []*Index {
+	var indexes []*Index
+	seen := make(map[*Index]struct{})
+	for _, entry := range m.Entries {
+		idx := m.indexByEntry[entry]
+		if _, ok := seen[idx]; !ok {
+			seen[idx] = struct{}{}
+			indexes = append(indexes, idx)
+		}
+	}
+
+	if name == "" {
+		return indexes
+	}
+	if idx, ok := m.indexByName[name]; ok {
+		return []*Index{idx}
+	}
+	return nil
+}
+
+func (f *SearchFilter) matches(entry *Entry) bool {
+	if f.Type != "" && f.Type != entry.Type {
+		return false
+	}
+	if f.License != "" && !strings.EqualFold(f.License, entry.License) {
+		return false
+	}
+	if f.Maintainer != "" {
+		maintainer := strings.ToLower(f.Maintainer)
+		for _, m := range entry.Maintainers {
+			if strings.Contains(strings.ToLower(m.Name), maintainer) || strings.Contains(strings.ToLower(m.Email), maintainer) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// termScore returns the score of the best match of a lowercase term in the fields of an entry, or 0.
+func termScore(entry *Entry, term string, minScore float64) float64 {
+	if term == "" {
+		return 0
+	}
+
+	name := strings.ToLower(entry.Name)
+	if name == term {
+		return nameExactWeight
+	}
+
+	best := 0.0
+	if s := nameScore(name, term); s >= minScore {
+		best = nameWeight * s
+	}
+
+	for _, keyword := range entry.Keywords {
+		keyword = strings.ToLower(keyword)
+		switch {
+		case keyword == term:
+			best = max(best, keywordExactWeight)
+		case strings.Contains(keyword, term):
+			best = max(best, keywordWeight)
+		}
+	}
+
+	for _, word := range words(entry.Description) {
+		switch {
+		case word == term:
+			best = max(best, descriptionExactWeight)
+		case strings.HasPrefix(word, term):
+			best = max(best, descriptionWeight)
+		}
+	}
+
+	for _, m := range entry.Maintainers {
+		if strings.Contains(strings.ToLower(m.Name), term) || strings.Contains(strings.ToLower(m.Email), term) {
+			best = max(best, maintainerWeight)
+		}
+	}
+
+	return best
+}
+
+// nameScore returns the similarity between a lowercase name and a term: 1 if the name contains the term,
+// otherwise the best similarity between the term and the whole name or one of its dash or underscore
+// separated parts.
+func nameScore(name, term string) float64 {
+	if strings.Contains(name, term) {
+		return 1
+	}
+
+	best := score(name, term)
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '-' || r == '_' }) {
+		best = max(best, score(part, term))
+	}
+	return best
+}
+
+// words splits a text in lowercase words.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// didYouMean formats the suggested names to be appended to an error message.
+func didYouMean(names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return fmt.Sprintf(" (did you mean %s?)", strings.Join(quoted, ", "))
+}
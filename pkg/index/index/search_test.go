@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package index
+
+import (
+	"strings"
+	"testing"
+)
+
+func searchTestIndexes() *MergedIndexes {
+	community := New("community")
+	community.Upsert(&Entry{
+		Name: "k8saudit-rules", Type: "rulesfile", License: "Apache-2.0",
+		Description: "Rules for the Kubernetes audit logs",
+		Keywords:    []string{"audit", "kubernetes"},
+	})
+	community.Upsert(&Entry{
+		Name: "k8saudit", Type: "plugin", License: "Apache-2.0",
+		Description: "Read Kubernetes Audit Events and monitor Kubernetes Clusters",
+		Keywords:    []string{"audit", "k8s"},
+		Maintainers: Maintainer{{Name: "The Diginfra Authors", Email: "cncf-diginfra-dev@lists.cncf.io"}},
+	})
+	community.Upsert(&Entry{
+		Name: "cloudtrail", Type: "plugin", License: "Apache-2.0",
+		Description: "Reads Cloudtrail JSON logs from files/S3 and injects as events",
+		Keywords:    []string{"aws", "audit"},
+	})
+	myorg := New("myorg")
+	myorg.Upsert(&Entry{
+		Name: "k8saudit-rules", Type: "rulesfile", License: "proprietary",
+		Description: "Mirror of the Kubernetes audit rules",
+	})
+	myorg.Priority = -1
+
+	m := NewMergedIndexes()
+	m.Merge(community, myorg)
+	return m
+}
+
+func resultNames(results []SearchResult) []string {
+	names := make([]string, 0, len(results))
+	for _, r := range results {
+		names = append(names, r.Index.Name+"/"+r.Entry.Name)
+	}
+	return names
+}
+
+func TestSearch(t *testing.T) {
+	m := searchTestIndexes()
+
+	testCases := []struct {
+		name     string
+		filter   SearchFilter
+		terms    []string
+		expected string
+	}{
+		{
+			name:     "exact name first",
+			terms:    []string{"k8saudit"},
+			expected: "community/k8saudit,community/k8saudit-rules",
+		},
+		{
+			name:     "description words",
+			terms:    []string{"json"},
+			expected: "community/cloudtrail",
+		},
+		{
+			name:     "keywords before descriptions",
+			terms:    []string{"aws", "kubernetes"},
+			expected: "community/cloudtrail,community/k8saudit-rules,community/k8saudit",
+		},
+		{
+			name:     "approximate name",
+			terms:    []string{"cloudtrial"},
+			expected: "community/cloudtrail",
+		},
+		{
+			name:     "maintainers",
+			terms:    []string{"diginfra authors"},
+			expected: "community/k8saudit",
+		},
+		{
+			name:     "type filter",
+			filter:   SearchFilter{Type: "plugin"},
+			terms:    []string{"audit"},
+			expected: "community/k8saudit,community/cloudtrail",
+		},
+		{
+			name:     "maintainer filter without terms",
+			filter:   SearchFilter{Maintainer: "cncf.io"},
+			expected: "community/k8saudit",
+		},
+		{
+			name:     "shadowed entries with index filter",
+			filter:   SearchFilter{Index: "myorg"},
+			terms:    []string{"audit"},
+			expected: "myorg/k8saudit-rules",
+		},
+		{
+			name:     "license filter",
+			filter:   SearchFilter{License: "PROPRIETARY"},
+			terms:    []string{"audit"},
+			expected: "",
+		},
+		{
+			name:     "unknown index",
+			filter:   SearchFilter{Index: "missing"},
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := strings.Join(resultNames(m.Search(0.65, tc.filter, tc.terms...)), ",")
+			if got != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	m := searchTestIndexes()
+
+	suggestions := m.Suggestions("k8saudit-rule", 3)
+	if len(suggestions) == 0 || suggestions[0] != "k8saudit-rules" {
+		t.Errorf("unexpected suggestions: %v", suggestions)
+	}
+
+	if suggestions := m.Suggestions("okta", 3); len(suggestions) != 0 {
+		t.Errorf("unexpected suggestions: %v", suggestions)
+	}
+
+	_, err := m.ResolveReference("cloudtrial:0.1.0")
+	if err == nil || !strings.Contains(err.Error(), `did you mean "cloudtrail"?`) {
+		t.Errorf("expected a suggestion, got %v", err)
+	}
+}
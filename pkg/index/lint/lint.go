@@ -0,0 +1,245 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+	"oras.land/oras-go/v2/registry"
+
+	"github.com/diginfra/diginfractl/pkg/index/index"
+	"github.com/diginfra/diginfractl/pkg/oci/repository"
+)
+
+// Schema is the JSON schema of index files.
+//
+//go:embed index.schema.json
+var Schema []byte
+
+// Issue is a problem found in an index file.
+type Issue struct {
+	// Entry is the name of the entry the issue refers to, or its position if it has no name.
+	// It is empty for issues concerning the whole file.
+	Entry   string
+	Message string
+}
+
+// String returns a string representation of the issue.
+func (i Issue) String() string {
+	if i.Entry == "" {
+		return i.Message
+	}
+	return fmt.Sprintf("%s: %s", i.Entry, i.Message)
+}
+
+// Lint validates the content of an index file against the schema, and checks that the entries
+// can be resolved and verified: their names are unique, their registry and repository form a
+// valid reference, their signatures are consistent and their lifecycle metadata can be parsed.
+func Lint(content []byte) ([]Issue, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return []Issue{{Message: fmt.Sprintf("invalid YAML: %s", err)}}, nil
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(Schema), gojsonschema.NewGoLoader(doc))
+	if err != nil {
+		return nil, fmt.Errorf("unable to validate index against the schema: %w", err)
+	}
+
+	var entries []*index.Entry
+	if err := yaml.Unmarshal(content, &entries); err != nil {
+		// The schema errors explain why the entries cannot be decoded.
+		entries = nil
+	}
+
+	var issues []Issue
+	for _, e := range result.Errors() {
+		entry, field := splitField(e.Field(), entries)
+		msg := strings.TrimPrefix(e.Description(), e.Field()+" ")
+		if field != "" {
+			msg = field + ": " + msg
+		}
+		issues = append(issues, Issue{Entry: entry, Message: msg})
+	}
+
+	seen := make(map[string]struct{}, len(entries))
+	for i, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		name := entryName(i, entries)
+
+		if entry.Name != "" {
+			if _, ok := seen[entry.Name]; ok {
+				issues = append(issues, Issue{Entry: name, Message: "duplicate entry name"})
+			}
+			seen[entry.Name] = struct{}{}
+		}
+
+		if entry.Registry != "" && entry.Repository != "" {
+			if _, err := registry.ParseReference(entry.Registry + "/" + entry.Repository); err != nil {
+				issues = append(issues, Issue{Entry: name, Message: fmt.Sprintf("invalid registry and repository: %s", err)})
+			}
+		}
+
+		for _, msg := range checkSignature(entry.Signature) {
+			issues = append(issues, Issue{Entry: name, Message: msg})
+		}
+
+		for _, msg := range checkLifecycle(entry) {
+			issues = append(issues, Issue{Entry: name, Message: msg})
+		}
+	}
+
+	return issues, nil
+}
+
+// CheckRepositories checks that the repositories referenced by the entries of an index file exist and
+// hold at least one tag. Entries without a valid reference are skipped, since Lint already reports them.
+func CheckRepositories(ctx context.Context, content []byte, options ...func(*repository.Repository)) []Issue {
+	var entries []*index.Entry
+	if err := yaml.Unmarshal(content, &entries); err != nil {
+		return nil
+	}
+
+	var issues []Issue
+	for i, entry := range entries {
+		if entry == nil || entry.Registry == "" || entry.Repository == "" {
+			continue
+		}
+		ref := entry.Registry + "/" + entry.Repository
+		if _, err := registry.ParseReference(ref); err != nil {
+			continue
+		}
+
+		repo, err := repository.NewRepository(ref, options...)
+		if err != nil {
+			issues = append(issues, Issue{Entry: entryName(i, entries), Message: err.Error()})
+			continue
+		}
+
+		tags, err := repo.Tags(ctx)
+		switch {
+		case err != nil:
+			issues = append(issues, Issue{Entry: entryName(i, entries), Message: fmt.Sprintf("unable to list the tags of %q: %s", ref, err)})
+		case len(tags) == 0:
+			issues = append(issues, Issue{Entry: entryName(i, entries), Message: fmt.Sprintf("repository %q has no tags", ref)})
+		}
+	}
+
+	return issues
+}
+
+// checkSignature returns the inconsistencies found in a signature, which would make its verification fail.
+func checkSignature(sig *index.Signature) []string {
+	if sig == nil || sig.Cosign == nil {
+		return nil
+	}
+
+	var msgs []string
+	c := sig.Cosign
+	if c.CertificateIdentity != "" && c.CertificateIdentityRegexp != "" {
+		msgs = append(msgs, "only one of certificate-identity and certificate-identity-regexp can be set")
+	}
+	if c.CertificateOidcIssuer != "" && c.CertificateOidcIssuerRegexp != "" {
+		msgs = append(msgs, "only one of certificate-oidc-issuer and certificate-oidc-issuer-regexp can be set")
+	}
+	if _, err := regexp.Compile(c.CertificateIdentityRegexp); err != nil {
+		msgs = append(msgs, fmt.Sprintf("invalid certificate-identity-regexp: %s", err))
+	}
+	if _, err := regexp.Compile(c.CertificateOidcIssuerRegexp); err != nil {
+		msgs = append(msgs, fmt.Sprintf("invalid certificate-oidc-issuer-regexp: %s", err))
+	}
+
+	keyless := c.CertificateIdentity != "" || c.CertificateIdentityRegexp != "" ||
+		c.CertificateOidcIssuer != "" || c.CertificateOidcIssuerRegexp != "" || c.CertificateGithubWorkflow != ""
+	switch {
+	case c.KeyRef != "" && keyless:
+		msgs = append(msgs, "key and certificate fields cannot be used together")
+	case c.KeyRef == "" && !keyless:
+		msgs = append(msgs, "cosign signature without a key or a certificate identity")
+	case keyless && (c.CertificateIdentity == "" && c.CertificateIdentityRegexp == "" ||
+		c.CertificateOidcIssuer == "" && c.CertificateOidcIssuerRegexp == ""):
+		msgs = append(msgs, "keyless signatures require both a certificate identity and a certificate OIDC issuer")
+	}
+
+	return msgs
+}
+
+// checkLifecycle returns the problems found in the lifecycle metadata of an entry, which would be ignored
+// when checking the installed versions.
+func checkLifecycle(entry *index.Entry) []string {
+	var msgs []string
+	checkDate := func(field, date string) {
+		if _, err := time.Parse(index.DateLayout, date); date != "" && err != nil {
+			msgs = append(msgs, fmt.Sprintf("%s: %q is not a date in the YYYY-MM-DD format", field, date))
+		}
+	}
+
+	if entry.ReplacedBy != "" && !entry.Deprecated {
+		msgs = append(msgs, "replaced-by is set but the entry is not deprecated")
+	}
+	checkDate("end-of-life", entry.EndOfLife)
+
+	seen := make(map[string]struct{}, len(entry.Versions))
+	for _, v := range entry.Versions {
+		if _, ok := seen[v.Version]; ok {
+			msgs = append(msgs, fmt.Sprintf("duplicate version %q", v.Version))
+		}
+		seen[v.Version] = struct{}{}
+		checkDate(fmt.Sprintf("version %s: end-of-life", v.Version), v.EndOfLife)
+	}
+
+	for _, a := range entry.Advisories {
+		for _, affected := range a.Affected {
+			if _, err := semver.ParseRange(affected); err != nil {
+				msgs = append(msgs, fmt.Sprintf("advisory %s: invalid affected range %q: %s", a.ID, affected, err))
+			}
+		}
+		if _, err := semver.ParseTolerant(a.Fixed); a.Fixed != "" && err != nil {
+			msgs = append(msgs, fmt.Sprintf("advisory %s: invalid fixed version %q: %s", a.ID, a.Fixed, err))
+		}
+	}
+
+	return msgs
+}
+
+// splitField splits the field of a schema error, in the form "N.property...", into the entry and the property path.
+func splitField(field string, entries []*index.Entry) (entry, property string) {
+	pos, property, _ := strings.Cut(field, ".")
+	i, err := strconv.Atoi(pos)
+	if err != nil {
+		// The error concerns the whole file.
+		return "", ""
+	}
+	return entryName(i, entries), property
+}
+
+func entryName(i int, entries []*index.Entry) string {
+	if i < len(entries) && entries[i] != nil && entries[i].Name != "" {
+		return entries[i].Name
+	}
+	return fmt.Sprintf("#%d", i+1)
+}
@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/diginfra/diginfractl/pkg/index/index"
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/authn"
+	ocipusher "github.com/diginfra/diginfractl/pkg/oci/pusher"
+	"github.com/diginfra/diginfractl/pkg/oci/repository"
+	testutils "github.com/diginfra/diginfractl/pkg/test"
+)
+
+func TestLintValid(t *testing.T) {
+	content, err := os.ReadFile("../testdata/index.yaml")
+	require.NoError(t, err)
+
+	issues, err := Lint(content)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestLint(t *testing.T) {
+	issues, err := Lint([]byte(`
+- name: rules
+  type: rule
+  registry: ghcr.io
+  repository: Diginfra/Rules
+  descripton: typo
+- registry: ghcr.io
+  repository: diginfra/plugin
+- name: rules
+  type: plugin
+  registry: ghcr.io
+  repository: diginfra/plugin
+`))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Issue{
+		{Entry: "rules", Message: `type: must be one of the following: "rulesfile", "plugin", "asset"`},
+		{Entry: "rules", Message: "Additional property descripton is not allowed"},
+		{Entry: "rules", Message: `invalid registry and repository: invalid reference: invalid repository "Diginfra/Rules"`},
+		{Entry: "#2", Message: "name is required"},
+		{Entry: "#2", Message: "type is required"},
+		{Entry: "rules", Message: "duplicate entry name"},
+	}, issues)
+
+	issues, err = Lint([]byte("name: rules\n"))
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Empty(t, issues[0].Entry)
+
+	issues, err = Lint([]byte("- name: [rules\n"))
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Contains(t, issues[0].Message, "invalid YAML")
+}
+
+func TestLintLifecycle(t *testing.T) {
+	issues, err := Lint([]byte(`
+- name: rules
+  type: rulesfile
+  registry: ghcr.io
+  repository: diginfra/rules
+  deprecated: true
+  replaced-by: rules-v2
+  end-of-life: 2025-01-31
+  versions:
+    - version: 1.0.0
+      end-of-life: 2024-12-31
+    - version: 1.1.0
+      deprecated: true
+  advisories:
+    - id: GHSA-1234
+      severity: high
+      affected: [">=1.0.0 <1.1.1"]
+      fixed: 1.1.1
+- name: broken
+  type: rulesfile
+  registry: ghcr.io
+  repository: diginfra/broken
+  replaced-by: rules
+  end-of-life: January 2025
+  versions:
+    - version: 1.0.0
+    - version: 1.0.0
+      end-of-life: "2025-02-30"
+  advisories:
+    - id: GHSA-5678
+      affected: ["latest"]
+    - affected: []
+`))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []Issue{
+		{Entry: "broken", Message: "advisories.1: id is required"},
+		{Entry: "broken", Message: "advisories.1.affected: Array must have at least 1 items"},
+		{Entry: "broken", Message: "replaced-by is set but the entry is not deprecated"},
+		{Entry: "broken", Message: `end-of-life: "January 2025" is not a date in the YYYY-MM-DD format`},
+		{Entry: "broken", Message: `duplicate version "1.0.0"`},
+		{Entry: "broken", Message: `version 1.0.0: end-of-life: "2025-02-30" is not a date in the YYYY-MM-DD format`},
+		{Entry: "broken", Message: `advisory GHSA-5678: invalid affected range "latest": Could not get version from string: "latest"`},
+	}, issues)
+}
+
+func TestCheckSignature(t *testing.T) {
+	testCases := []struct {
+		name   string
+		cosign index.CosignSignature
+		msgs   []string
+	}{
+		{
+			name:   "key",
+			cosign: index.CosignSignature{KeyRef: "cosign.pub"},
+		},
+		{
+			name:   "keyless",
+			cosign: index.CosignSignature{CertificateIdentityRegexp: "^https://github.com/diginfra/", CertificateOidcIssuer: "https://issuer"},
+		},
+		{
+			name:   "empty",
+			cosign: index.CosignSignature{},
+			msgs:   []string{"cosign signature without a key or a certificate identity"},
+		},
+		{
+			name:   "key and identity",
+			cosign: index.CosignSignature{KeyRef: "cosign.pub", CertificateIdentity: "id", CertificateOidcIssuer: "https://issuer"},
+			msgs:   []string{"key and certificate fields cannot be used together"},
+		},
+		{
+			name: "identity and regexp",
+			cosign: index.CosignSignature{CertificateIdentity: "id", CertificateIdentityRegexp: "id",
+				CertificateOidcIssuer: "https://issuer", CertificateOidcIssuerRegexp: "("},
+			msgs: []string{
+				"only one of certificate-identity and certificate-identity-regexp can be set",
+				"only one of certificate-oidc-issuer and certificate-oidc-issuer-regexp can be set",
+				"invalid certificate-oidc-issuer-regexp: error parsing regexp: missing closing ): `(`",
+			},
+		},
+		{
+			name:   "missing issuer",
+			cosign: index.CosignSignature{CertificateIdentity: "id"},
+			msgs:   []string{"keyless signatures require both a certificate identity and a certificate OIDC issuer"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cosign := tc.cosign
+			assert.Equal(t, tc.msgs, checkSignature(&index.Signature{Cosign: &cosign}))
+		})
+	}
+}
+
+func TestCheckRepositories(t *testing.T) {
+	ctx := context.Background()
+	reg := testutils.StartTestRegistry(t, nil)
+	client := authn.NewClient(authn.WithCredentials(&auth.EmptyCredential))
+	_, err := ocipusher.NewPusher(client, true, nil).Push(ctx, oci.Rulesfile, reg+"/diginfra/rules:latest",
+		ocipusher.WithFilepaths([]string{"../../test/data/rules.tar.gz"}))
+	require.NoError(t, err)
+
+	issues := CheckRepositories(ctx, []byte(fmt.Sprintf(`
+- name: rules
+  type: rulesfile
+  registry: %[1]s
+  repository: diginfra/rules
+- name: missing
+  type: rulesfile
+  registry: %[1]s
+  repository: diginfra/missing
+- name: invalid
+  type: rulesfile
+  registry: %[1]s
+  repository: Invalid
+`, reg)), repository.WithClient(client), repository.WithPlainHTTP(true))
+	require.Len(t, issues, 1)
+	assert.Equal(t, "missing", issues[0].Entry)
+}
@@ -35,6 +35,16 @@ const (
 	// DiginfraAssetLayerMediaType is the MediaType for assets.
 	DiginfraAssetLayerMediaType = "application/vnd.cncf.diginfra.asset.layer.v1+tar.gz"
 
+	// DiginfraIndexConfigMediaType is the MediaType for index's config layer.
+	DiginfraIndexConfigMediaType = "application/vnd.cncf.diginfra.index.config.v1+json"
+
+	// DiginfraIndexLayerMediaType is the MediaType for index files.
+	DiginfraIndexLayerMediaType = "application/vnd.cncf.diginfra.index.layer.v1+yaml"
+
+	// AnnotationKeywords is the annotation holding the comma separated keywords of an artifact,
+	// used when generating indexes.
+	AnnotationKeywords = "org.diginfra.artifact.keywords"
+
 	// DefaultTag is the default tag reference to be used when none is provided.
 	DefaultTag = "latest"
 )
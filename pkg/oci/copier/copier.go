@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote"
+
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/repository"
+	"github.com/diginfra/diginfractl/pkg/output"
+)
+
+// attachmentTagRegexp matches the tags used by cosign for the signatures, attestations and SBOMs of a
+// manifest, and the tags of the referrers indexes used by registries not supporting the referrers API.
+var attachmentTagRegexp = regexp.MustCompile(`^sha256-[a-f0-9]{64}(\.(sig|att|sbom))?$`)
+
+// Copier implements copy operations between remote registries.
+type Copier struct {
+	Client    remote.Client
+	tracker   output.Tracker
+	plainHTTP bool
+}
+
+// Result describes an artifact copied by the Copier.
+type Result struct {
+	Source      string
+	Destination string
+	Digest      string
+	// Signatures are the digests of the cosign signatures and of the referrers copied along with the artifact.
+	Signatures []string
+}
+
+// NewCopier creates a new copier that can be used for copy operations.
+// The client must be ready to be used on both the source and the destination registries.
+func NewCopier(client remote.Client, plainHTTP bool, tracker output.Tracker) *Copier {
+	return &Copier{
+		Client:    client,
+		tracker:   tracker,
+		plainHTTP: plainHTTP,
+	}
+}
+
+// Tags returns the tags of the repository of ref, except for the ones of the signatures, attestations,
+// SBOMs and referrers of the artifacts. If versions is not nil, only the tags of the full versions in the range
+// are returned.
+func (c *Copier) Tags(ctx context.Context, ref string, versions semver.Range) ([]string, error) {
+	repo, err := c.repository(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := repo.Tags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list tags of %s: %w", ref, err)
+	}
+
+	var result []string
+	for _, tag := range tags {
+		if attachmentTagRegexp.MatchString(tag) {
+			continue
+		}
+		if versions != nil {
+			// Floating tags, such as "0.5" or "latest", are not versions.
+			v, err := semver.ParseTolerant(tag)
+			if err != nil || strings.Count(tag, ".") != 2 || !versions(v) {
+				continue
+			}
+		}
+		result = append(result, tag)
+	}
+
+	return result, nil
+}
+
+// Copy copies the artifact referenced by srcRef to dstRef, including the manifests of all its platforms.
+// When dstRef has no tag or digest, the one of srcRef is used, ":latest" being assumed when srcRef has
+// none either. The digests are preserved. Blobs already present in the destination are skipped, and the
+// ones found in the source repository are mounted when both repositories are on the same registry.
+// If withSignatures is true, the cosign signatures and the OCI referrers of the artifact, and of the
+// manifests of its platforms, are copied as well.
+func (c *Copier) Copy(ctx context.Context, srcRef, dstRef string, withSignatures bool) (*Result, error) {
+	src, err := c.repository(srcRef)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := c.repository(dstRef)
+	if err != nil {
+		return nil, err
+	}
+
+	if src.Reference.Reference == "" {
+		src.Reference.Reference = oci.DefaultTag
+	}
+	if dst.Reference.Reference == "" {
+		dst.Reference.Reference = src.Reference.Reference
+	}
+
+	opts := oras.DefaultCopyOptions
+	target := oras.Target(dst.Repository)
+	if src.Reference.Registry == dst.Reference.Registry {
+		// Mounting needs the destination repository itself, it does not work through the tracker.
+		opts.MountFrom = func(context.Context, ocispec.Descriptor) ([]string, error) {
+			return []string{src.Reference.Repository}, nil
+		}
+	} else if c.tracker != nil {
+		target = c.tracker(dst.Repository)
+	}
+
+	desc, err := oras.Copy(ctx, src.Repository, src.Reference.Reference, target, dst.Reference.Reference, opts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to copy %s to %s: %w", src.Reference, dst.Reference, err)
+	}
+
+	result := &Result{
+		Source:      src.Reference.String(),
+		Destination: dst.Reference.String(),
+		Digest:      desc.Digest.String(),
+	}
+
+	if !withSignatures {
+		return result, nil
+	}
+
+	subjects := []ocispec.Descriptor{desc}
+	if desc.MediaType == ocispec.MediaTypeImageIndex {
+		manifests, err := content.Successors(ctx, src.Repository, desc)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the manifests of %s: %w", src.Reference, err)
+		}
+		subjects = append(subjects, manifests...)
+	}
+
+	for _, subject := range subjects {
+		signatures, err := copySignatures(ctx, src.Repository, target, subject, opts.CopyGraphOptions)
+		if err != nil {
+			return nil, err
+		}
+		result.Signatures = append(result.Signatures, signatures...)
+	}
+
+	return result, nil
+}
+
+// copySignatures copies the signatures of subject, stored either in the cosign tag or as OCI referrers,
+// and returns their digests.
+func copySignatures(ctx context.Context, src *remote.Repository, dst oras.Target, subject ocispec.Descriptor,
+	opts oras.CopyGraphOptions) ([]string, error) {
+	var copied []string
+
+	// Cosign stores the signatures of a manifest in the "sha256-<digest>.sig" tag of the same repository.
+	tag := fmt.Sprintf("%s-%s.sig", subject.Digest.Algorithm(), subject.Digest.Encoded())
+	sig, err := oras.Copy(ctx, src, tag, dst, tag, oras.CopyOptions{CopyGraphOptions: opts})
+	switch {
+	case err == nil:
+		copied = append(copied, sig.Digest.String())
+	case !errors.Is(err, errdef.ErrNotFound):
+		return nil, fmt.Errorf("unable to copy signature %s: %w", tag, err)
+	}
+
+	referrers, err := registry.Referrers(ctx, src, subject, "")
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the referrers of %s: %w", subject.Digest, err)
+	}
+	for _, referrer := range referrers {
+		if err := oras.CopyGraph(ctx, src, dst, referrer, opts); err != nil {
+			return nil, fmt.Errorf("unable to copy referrer %s of %s: %w", referrer.Digest, subject.Digest, err)
+		}
+		copied = append(copied, referrer.Digest.String())
+	}
+
+	return copied, nil
+}
+
+func (c *Copier) repository(ref string) (*repository.Repository, error) {
+	return repository.NewRepository(ref,
+		repository.WithClient(c.Client),
+		repository.WithPlainHTTP(c.plainHTTP))
+}
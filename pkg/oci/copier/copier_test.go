@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package copier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/authn"
+	ocipusher "github.com/diginfra/diginfractl/pkg/oci/pusher"
+	testutils "github.com/diginfra/diginfractl/pkg/test"
+)
+
+const rulesfiletgz = "../../test/data/rules.tar.gz"
+
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	reg := testutils.StartTestRegistry(t, nil)
+	client := authn.NewClient(authn.WithCredentials(&auth.EmptyCredential))
+	pusher := ocipusher.NewPusher(client, true, nil)
+	copier := NewCopier(client, true, nil)
+
+	pushed, err := pusher.Push(ctx, oci.Rulesfile, reg+"/src/rules:1.0.0",
+		ocipusher.WithFilepaths([]string{rulesfiletgz}),
+		ocipusher.WithTags("1.1.0", "1", "2.0.0"),
+		ocipusher.WithArtifactConfig(oci.ArtifactConfig{Name: "rules", Version: "1.0.0"}))
+	require.NoError(t, err)
+
+	// Attach a cosign signature and a referrer to the artifact.
+	src, err := copier.repository(reg + "/src/rules")
+	require.NoError(t, err)
+	root, err := src.Resolve(ctx, "1.0.0")
+	require.NoError(t, err)
+	require.Equal(t, pushed.RootDigest, root.Digest.String())
+	sig, err := oras.PackManifest(ctx, src.Repository, oras.PackManifestVersion1_1, "application/vnd.dev.cosign.simplesigning.v1+json",
+		oras.PackManifestOptions{})
+	require.NoError(t, err)
+	sigTag := fmt.Sprintf("sha256-%s.sig", root.Digest.Encoded())
+	require.NoError(t, src.Tag(ctx, sig, sigTag))
+	referrer, err := oras.PackManifest(ctx, src.Repository, oras.PackManifestVersion1_1, "application/vnd.example.sbom",
+		oras.PackManifestOptions{Subject: &root})
+	require.NoError(t, err)
+
+	// The tags of the signatures are never listed, floating tags are not versions.
+	tags, err := copier.Tags(ctx, reg+"/src/rules", nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1.0.0", "1.1.0", "1", "2.0.0"}, tags)
+	tags, err = copier.Tags(ctx, reg+"/src/rules", semver.MustParseRange(">=1.0.0 <2.0.0"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"1.0.0", "1.1.0"}, tags)
+
+	// The tag of the source is used when the destination has none, and the digest is preserved.
+	res, err := copier.Copy(ctx, reg+"/src/rules:1.0.0", reg+"/dst/rules", true)
+	require.NoError(t, err)
+	assert.Equal(t, reg+"/dst/rules:1.0.0", res.Destination)
+	assert.Equal(t, root.Digest.String(), res.Digest)
+	assert.ElementsMatch(t, []string{sig.Digest.String(), referrer.Digest.String()}, res.Signatures)
+
+	dst, err := copier.repository(reg + "/dst/rules")
+	require.NoError(t, err)
+	copied, err := dst.Resolve(ctx, "1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, root.Digest, copied.Digest)
+	copiedSig, err := dst.Resolve(ctx, sigTag)
+	require.NoError(t, err)
+	assert.Equal(t, sig.Digest, copiedSig.Digest)
+	referrers, err := registry.Referrers(ctx, dst.Repository, copied, "")
+	require.NoError(t, err)
+	require.Len(t, referrers, 1)
+	assert.Equal(t, referrer.Digest, referrers[0].Digest)
+
+	// Signatures are only copied on demand.
+	res, err = copier.Copy(ctx, reg+"/src/rules:1.1.0", reg+"/other/rules:stable", false)
+	require.NoError(t, err)
+	assert.Empty(t, res.Signatures)
+	other, err := copier.repository(reg + "/other/rules")
+	require.NoError(t, err)
+	_, err = other.Resolve(ctx, sigTag)
+	assert.ErrorIs(t, err, errdef.ErrNotFound)
+	referrers, err = registry.Referrers(ctx, other.Repository, ocispec.Descriptor{
+		MediaType: root.MediaType, Digest: root.Digest, Size: root.Size}, "")
+	require.NoError(t, err)
+	assert.Empty(t, referrers)
+}
+
+func TestCopyMultiPlatform(t *testing.T) {
+	ctx := context.Background()
+	srcReg, dstReg := testutils.StartTestRegistry(t, nil), testutils.StartTestRegistry(t, nil)
+	client := authn.NewClient(authn.WithCredentials(&auth.EmptyCredential))
+	pusher := ocipusher.NewPusher(client, true, nil)
+	copier := NewCopier(client, true, nil)
+
+	_, err := pusher.Push(ctx, oci.Plugin, srcReg+"/src/plugin:0.1.0",
+		ocipusher.WithFilepathsAndPlatforms([]string{rulesfiletgz, rulesfiletgz}, []string{"linux/arm64", "linux/amd64"}),
+		ocipusher.WithArtifactConfig(oci.ArtifactConfig{Version: "0.1.0"}))
+	require.NoError(t, err)
+
+	res, err := copier.Copy(ctx, srcReg+"/src/plugin:0.1.0", dstReg+"/mirror/plugin", true)
+	require.NoError(t, err)
+	assert.Empty(t, res.Signatures)
+
+	dst, err := copier.repository(dstReg + "/mirror/plugin")
+	require.NoError(t, err)
+	root, err := dst.Resolve(ctx, "0.1.0")
+	require.NoError(t, err)
+	assert.Equal(t, res.Digest, root.Digest.String())
+	require.Equal(t, ocispec.MediaTypeImageIndex, root.MediaType)
+
+	// The manifests of all the platforms have been copied.
+	_, data, err := dst.FetchReference(ctx, "0.1.0")
+	require.NoError(t, err)
+	defer data.Close()
+	index, err := testutils.ImageIndexFromReader(data)
+	require.NoError(t, err)
+	require.Len(t, index.Manifests, 2)
+	for _, m := range index.Manifests {
+		exists, err := dst.Exists(ctx, m)
+		require.NoError(t, err)
+		assert.True(t, exists)
+	}
+}
+
+func TestTagsPaginated(t *testing.T) {
+	ctx := context.Background()
+	reg := testutils.StartTestRegistry(t, nil, testutils.WithTagsPageSize(2))
+	client := authn.NewClient(authn.WithCredentials(&auth.EmptyCredential))
+	pusher := ocipusher.NewPusher(client, true, nil)
+	copier := NewCopier(client, true, nil)
+
+	_, err := pusher.Push(ctx, oci.Rulesfile, reg+"/src/rules:0.1.0",
+		ocipusher.WithFilepaths([]string{rulesfiletgz}),
+		ocipusher.WithTags("0.2.0", "0.3.0", "1.0.0", "1.1.0", "latest"),
+		ocipusher.WithArtifactConfig(oci.ArtifactConfig{Name: "rules", Version: "0.1.0"}))
+	require.NoError(t, err)
+
+	// The tags span three pages.
+	tags, err := copier.Tags(ctx, reg+"/src/rules", nil)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"0.1.0", "0.2.0", "0.3.0", "1.0.0", "1.1.0", "latest"}, tags)
+	tags, err = copier.Tags(ctx, reg+"/src/rules", semver.MustParseRange("<1.0.0"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"0.1.0", "0.2.0", "0.3.0"}, tags)
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"oras.land/oras-go/v2/registry"
+)
+
+// Endpoint is a registry serving the artifacts of a mirrored prefix.
+type Endpoint struct {
+	// Location is the registry, optionally followed by the repository prefix replacing the mirrored one,
+	// such as "harbor.example.com" or "harbor.example.com/ghcr-proxy".
+	Location string
+	// PlainHTTP is set to true if the endpoint must be reached in plain http.
+	PlainHTTP bool
+}
+
+// Mirror maps an upstream registry, or a repository prefix, to the endpoints mirroring it.
+type Mirror struct {
+	// Prefix is the upstream registry or repository prefix, such as "ghcr.io" or "ghcr.io/diginfra".
+	Prefix string
+	// Endpoints are tried in order.
+	Endpoints []Endpoint
+	// Fallback allows pulling from upstream when none of the endpoints can serve an artifact.
+	Fallback bool
+}
+
+// Mirrors is a list of mirrors. The one with the longest prefix matching a reference is used.
+type Mirrors []Mirror
+
+// Candidate is a location an artifact can be pulled from.
+type Candidate struct {
+	// Ref is the reference of the artifact at this location.
+	Ref string
+	// PlainHTTP is set to true if the location must be reached in plain http.
+	PlainHTTP bool
+	// Mirror is set to true if the location is a mirror.
+	Mirror bool
+}
+
+// Validate checks that the prefixes and the locations of the mirrors can be used in references.
+func (m Mirrors) Validate() error {
+	var errs []error
+	for _, mirror := range m {
+		if err := validate(mirror.Prefix); err != nil {
+			errs = append(errs, fmt.Errorf("invalid mirror prefix %q: %w", mirror.Prefix, err))
+		}
+		if len(mirror.Endpoints) == 0 {
+			errs = append(errs, fmt.Errorf("mirror of %q has no endpoints", mirror.Prefix))
+		}
+		for _, e := range mirror.Endpoints {
+			if err := validate(e.Location); err != nil {
+				errs = append(errs, fmt.Errorf("invalid location %q for the mirror of %q: %w", e.Location, mirror.Prefix, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate checks that location is a registry, optionally followed by a repository prefix.
+func validate(location string) error {
+	location = strings.TrimSuffix(location, "/")
+	if strings.Contains(location, "/") {
+		_, err := registry.ParseReference(location)
+		return err
+	}
+
+	ref := registry.Reference{Registry: location}
+	return ref.ValidateRegistry()
+}
+
+// Candidates returns, in order, the locations the artifact referenced by ref can be pulled from: the
+// endpoints of the mirror with the longest prefix matching ref, followed by upstream if the mirror allows
+// falling back to it. If no mirror matches, the only location is upstream, reached in plain http if
+// plainHTTP is set.
+func (m Mirrors) Candidates(ref string, plainHTTP bool) []Candidate {
+	upstream := Candidate{Ref: ref, PlainHTTP: plainHTTP}
+
+	mirror := m.match(ref)
+	if mirror == nil {
+		return []Candidate{upstream}
+	}
+
+	candidates := make([]Candidate, 0, len(mirror.Endpoints)+1)
+	for _, e := range mirror.Endpoints {
+		candidates = append(candidates, Candidate{
+			Ref:       strings.TrimSuffix(e.Location, "/") + ref[len(mirror.Prefix):],
+			PlainHTTP: e.PlainHTTP,
+			Mirror:    true,
+		})
+	}
+	if mirror.Fallback {
+		candidates = append(candidates, upstream)
+	}
+
+	return candidates
+}
+
+// match returns the mirror with the longest prefix matching ref, or nil if none.
+func (m Mirrors) match(ref string) *Mirror {
+	var best *Mirror
+	for i := range m {
+		prefix := strings.TrimSuffix(m[i].Prefix, "/")
+		if !hasPrefix(ref, prefix) {
+			continue
+		}
+		if best == nil || len(prefix) > len(best.Prefix) {
+			best = &Mirror{Prefix: prefix, Endpoints: m[i].Endpoints, Fallback: m[i].Fallback}
+		}
+	}
+
+	return best
+}
+
+// hasPrefix returns true if prefix is ref itself, its registry or a leading part of its repository.
+func hasPrefix(ref, prefix string) bool {
+	if prefix == "" || !strings.HasPrefix(ref, prefix) {
+		return false
+	}
+	if len(ref) == len(prefix) {
+		return true
+	}
+
+	switch ref[len(prefix)] {
+	case '/', ':', '@':
+		// A colon right after the registry introduces a port, not a tag.
+		return ref[len(prefix)] != ':' || strings.Contains(prefix, "/")
+	default:
+		return false
+	}
+}
@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mirror
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCandidates(t *testing.T) {
+	mirrors := Mirrors{
+		{
+			Prefix:    "ghcr.io",
+			Endpoints: []Endpoint{{Location: "harbor.example.com/ghcr"}},
+			Fallback:  true,
+		},
+		{
+			Prefix: "ghcr.io/diginfra/",
+			Endpoints: []Endpoint{
+				{Location: "harbor.example.com/diginfra"},
+				{Location: "localhost:5000/diginfra", PlainHTTP: true},
+			},
+		},
+		{
+			Prefix:    "localhost:5000",
+			Endpoints: []Endpoint{{Location: "harbor.example.com/local"}},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		ref      string
+		expected []Candidate
+	}{
+		{
+			name: "longest prefix without fallback",
+			ref:  "ghcr.io/diginfra/rules:1",
+			expected: []Candidate{
+				{Ref: "harbor.example.com/diginfra/rules:1", Mirror: true},
+				{Ref: "localhost:5000/diginfra/rules:1", PlainHTTP: true, Mirror: true},
+			},
+		},
+		{
+			name: "registry prefix with fallback",
+			ref:  "ghcr.io/other/rules@sha256:123",
+			expected: []Candidate{
+				{Ref: "harbor.example.com/ghcr/other/rules@sha256:123", Mirror: true},
+				{Ref: "ghcr.io/other/rules@sha256:123", PlainHTTP: true},
+			},
+		},
+		{
+			name: "partial repository names do not match",
+			ref:  "ghcr.io/diginfrasecurity/rules:1",
+			expected: []Candidate{
+				{Ref: "harbor.example.com/ghcr/diginfrasecurity/rules:1", Mirror: true},
+				{Ref: "ghcr.io/diginfrasecurity/rules:1", PlainHTTP: true},
+			},
+		},
+		{
+			name:     "registry with port",
+			ref:      "localhost:5000/rules:1",
+			expected: []Candidate{{Ref: "harbor.example.com/local/rules:1", Mirror: true}},
+		},
+		{
+			name:     "registry prefix is not a port prefix",
+			ref:      "localhost:50000/rules:1",
+			expected: []Candidate{{Ref: "localhost:50000/rules:1", PlainHTTP: true}},
+		},
+		{
+			name:     "no mirror",
+			ref:      "docker.io/diginfra/rules:1",
+			expected: []Candidate{{Ref: "docker.io/diginfra/rules:1", PlainHTTP: true}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, mirrors.Candidates(tc.ref, true))
+		})
+	}
+}
+
+func TestHasPrefix(t *testing.T) {
+	assert.True(t, hasPrefix("ghcr.io/diginfra/rules:1", "ghcr.io/diginfra/rules"))
+	assert.True(t, hasPrefix("ghcr.io/diginfra/rules", "ghcr.io/diginfra/rules"))
+	assert.False(t, hasPrefix("ghcr.io:443/diginfra/rules", "ghcr.io"))
+	assert.False(t, hasPrefix("ghcr.io/diginfra/rules", ""))
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Mirrors{{Prefix: "ghcr.io/diginfra", Endpoints: []Endpoint{{Location: "localhost:5000"}}}}.Validate())
+
+	err := Mirrors{
+		{Prefix: "ghcr.io/Diginfra", Endpoints: []Endpoint{{Location: "harbor example"}}},
+		{Prefix: "ghcr.io"},
+	}.Validate()
+	assert.ErrorContains(t, err, `invalid mirror prefix "ghcr.io/Diginfra"`)
+	assert.ErrorContains(t, err, `invalid location "harbor example" for the mirror of "ghcr.io/Diginfra"`)
+	assert.ErrorContains(t, err, `mirror of "ghcr.io" has no endpoints`)
+}
@@ -0,0 +1,119 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package puller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+
+	"github.com/diginfra/diginfractl/pkg/oci/mirror"
+	"github.com/diginfra/diginfractl/pkg/oci/repository"
+)
+
+// Annotations retrieves the annotations of an artifact from a given ref.
+// If the artifact has a v1.MediaTypeImageIndex descriptor then the annotations of the index are merged
+// with the ones of the manifest for the specified platform, which take precedence.
+func (p *Puller) Annotations(ctx context.Context, ref, os, arch string) (map[string]string, error) {
+	var annotations map[string]string
+	err := p.try(ctx, ref, func(c mirror.Candidate, repo *repository.Repository) (err error) {
+		annotations, err = p.annotations(ctx, repo, c.Ref, os, arch)
+		return err
+	})
+
+	return annotations, err
+}
+
+func (p *Puller) annotations(ctx context.Context, repo *repository.Repository, ref, os, arch string) (map[string]string, error) {
+	desc, rc, err := repo.FetchReference(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch reference %q: %w", ref, err)
+	}
+	defer rc.Close()
+
+	descBytes, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read bytes from descriptor: %w", err)
+	}
+
+	// Both image indexes and manifests carry their annotations in the same field.
+	var root struct {
+		Annotations map[string]string `json:"annotations,omitempty"`
+	}
+	if err = json.Unmarshal(descBytes, &root); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal descriptor: %w", err)
+	}
+
+	annotations := make(map[string]string, len(root.Annotations))
+	for k, v := range root.Annotations {
+		annotations[k] = v
+	}
+
+	if desc.MediaType == v1.MediaTypeImageIndex {
+		manifest, err := p.manifest(ctx, repo, ref, os, arch)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range manifest.Annotations {
+			annotations[k] = v
+		}
+	}
+
+	return annotations, nil
+}
+
+// Platforms retrieves the platforms, in the OS/ARCH format, of the manifests of an artifact from a given ref.
+// It returns an empty list if the artifact does not have a v1.MediaTypeImageIndex descriptor.
+func (p *Puller) Platforms(ctx context.Context, ref string) ([]string, error) {
+	var platforms []string
+	err := p.try(ctx, ref, func(c mirror.Candidate, repo *repository.Repository) (err error) {
+		platforms, err = platformsOf(ctx, repo, c.Ref)
+		return err
+	})
+
+	return platforms, err
+}
+
+func platformsOf(ctx context.Context, repo *repository.Repository, ref string) ([]string, error) {
+	desc, rc, err := repo.FetchReference(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("unable to fetch reference %q: %w", ref, err)
+	}
+	defer rc.Close()
+
+	if desc.MediaType != v1.MediaTypeImageIndex {
+		return nil, nil
+	}
+
+	var index v1.Index
+	if err = json.NewDecoder(rc).Decode(&index); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal index: %w", err)
+	}
+
+	platforms := make([]string, 0, len(index.Manifests))
+	for _, m := range index.Manifests {
+		if m.Platform != nil {
+			platforms = append(platforms, m.Platform.OS+"/"+m.Platform.Architecture)
+		}
+	}
+	sort.Strings(platforms)
+
+	return platforms, nil
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package puller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/content"
+
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/repository"
+)
+
+// Index pulls the index file stored in an index artifact.
+// Ref format follows: REGISTRY/REPO[:TAG|@DIGEST]. Ex. localhost:5000/index:latest.
+// It returns the content of the index file and the digest of the artifact manifest.
+func (p *Puller) Index(ctx context.Context, ref string) ([]byte, string, error) {
+	repo, err := repository.NewRepository(ref,
+		repository.WithClient(p.Client),
+		repository.WithPlainHTTP(p.plainHTTP))
+	if err != nil {
+		return nil, "", err
+	}
+
+	// if no tag was specified, "latest" is used
+	if repo.Reference.Reference == "" {
+		ref += ":" + oci.DefaultTag
+		repo.Reference.Reference = oci.DefaultTag
+	}
+
+	desc, manifestReader, err := repo.FetchReference(ctx, ref)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to fetch reference %q: %w", ref, err)
+	}
+	defer manifestReader.Close()
+
+	manifestBytes, err := content.ReadAll(manifestReader, desc)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to read manifest for ref %q: %w", ref, err)
+	}
+
+	var manifest v1.Manifest
+	if err = json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, "", fmt.Errorf("unable to unmarshal manifest: %w", err)
+	}
+
+	if len(manifest.Layers) != 1 || manifest.Layers[0].MediaType != oci.DiginfraIndexLayerMediaType {
+		return nil, "", fmt.Errorf("%q is not an index artifact", ref)
+	}
+
+	layer := manifest.Layers[0]
+	layerReader, err := repo.Blobs().Fetch(ctx, layer)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to fetch index layer with digest %q: %w", layer.Digest, err)
+	}
+	defer layerReader.Close()
+
+	indexBytes, err := content.ReadAll(layerReader, layer)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to read index layer with digest %q: %w", layer.Digest, err)
+	}
+
+	return indexBytes, string(desc.Digest), nil
+}
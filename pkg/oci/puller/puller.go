@@ -18,22 +18,27 @@ package puller
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 	"oras.land/oras-go/v2"
 	"oras.land/oras-go/v2/content/file"
+	"oras.land/oras-go/v2/registry"
 	"oras.land/oras-go/v2/registry/remote"
 
 	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/mirror"
 	"github.com/diginfra/diginfractl/pkg/oci/repository"
 	"github.com/diginfra/diginfractl/pkg/output"
 )
 
 // Puller implements pull operations.
 type Puller struct {
-	Client    remote.Client
+	Client remote.Client
+	// Mirrors are tried, in order, before the upstream registries of the artifacts.
+	Mirrors   mirror.Mirrors
 	tracker   output.Tracker
 	plainHTTP bool
 }
@@ -51,24 +56,30 @@ func NewPuller(client remote.Client, plainHTTP bool, tracker output.Tracker) *Pu
 // Pull an artifact from a remote registry.
 // Ref format follows: REGISTRY/REPO[:TAG|@DIGEST]. Ex. localhost:5000/hello:latest.
 func (p *Puller) Pull(ctx context.Context, ref, destDir, os, arch string) (*oci.RegistryResult, error) {
-	fileStore, err := file.New(destDir)
-	if err != nil {
-		return nil, err
+	// if no tag was specified, "latest" is used
+	if parsed, err := registry.ParseReference(ref); err == nil && parsed.Reference == "" {
+		ref += ":" + oci.DefaultTag
 	}
 
-	repo, err := repository.NewRepository(ref,
-		repository.WithClient(p.Client),
-		repository.WithPlainHTTP(p.plainHTTP))
+	var result *oci.RegistryResult
+	err := p.try(ctx, ref, func(c mirror.Candidate, repo *repository.Repository) (err error) {
+		result, err = p.pull(ctx, repo, c.Ref, destDir, os, arch)
+		if err == nil {
+			result.Source = c.Ref
+			result.PlainHTTP = c.PlainHTTP
+		}
+		return err
+	})
+
+	return result, err
+}
+
+func (p *Puller) pull(ctx context.Context, repo *repository.Repository, ref, destDir, os, arch string) (*oci.RegistryResult, error) {
+	fileStore, err := file.New(destDir)
 	if err != nil {
 		return nil, err
 	}
 
-	// if no tag was specified, "latest" is used
-	if repo.Reference.Reference == "" {
-		ref += ":" + oci.DefaultTag
-		repo.Reference.Reference = oci.DefaultTag
-	}
-
 	refDesc, _, err := repo.FetchReference(ctx, ref)
 	if err != nil {
 		return nil, err
@@ -125,16 +136,38 @@ func (p *Puller) Pull(ctx context.Context, ref, destDir, os, arch string) (*oci.
 
 // Descriptor retrieves the descriptor of an artifact from a remote repository.
 func (p *Puller) Descriptor(ctx context.Context, ref string) (*v1.Descriptor, error) {
-	repo, err := repository.NewRepository(ref, repository.WithClient(p.Client), repository.WithPlainHTTP(p.plainHTTP))
+	var desc v1.Descriptor
+	err := p.try(ctx, ref, func(c mirror.Candidate, repo *repository.Repository) (err error) {
+		desc, _, err = repo.FetchReference(ctx, c.Ref)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
+	return &desc, nil
+}
 
-	desc, _, err := repo.FetchReference(ctx, ref)
-	if err != nil {
-		return nil, err
+// try calls fn with the repository of each location the artifact referenced by ref can be pulled from,
+// mirrors first, until fn succeeds. Without mirrors, the only location is ref itself.
+func (p *Puller) try(ctx context.Context, ref string, fn func(c mirror.Candidate, repo *repository.Repository) error) error {
+	candidates := p.Mirrors.Candidates(ref, p.plainHTTP)
+
+	var errs []error
+	for _, c := range candidates {
+		repo, err := repository.NewRepository(c.Ref, repository.WithClient(p.Client), repository.WithPlainHTTP(c.PlainHTTP))
+		if err == nil {
+			err = fn(c, repo)
+		}
+		if err == nil {
+			return nil
+		}
+		if len(candidates) == 1 || ctx.Err() != nil {
+			return err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", c.Ref, err))
 	}
-	return &desc, nil
+
+	return fmt.Errorf("unable to pull %s from any of its locations: %w", ref, errors.Join(errs...))
 }
 
 func manifestFromDesc(ctx context.Context, target oras.Target, desc *v1.Descriptor) (*v1.Manifest, error) {
@@ -165,10 +198,10 @@ func manifestFromDesc(ctx context.Context, target oras.Target, desc *v1.Descript
 // manifest retieves the manifest of an artifact, also taking care of resolving to it walking through indexes.
 // If the artifact has a v1.MediaTypeImageIndex descriptor then it fetches the manifest for the
 // specified platform.
-func (p *Puller) manifest(ctx context.Context, ref, os, arch string) (*v1.Manifest, error) {
+func (p *Puller) manifest(ctx context.Context, repo *repository.Repository, ref, os, arch string) (*v1.Manifest, error) {
 	var manifest v1.Manifest
 
-	manifestBytes, err := p.RawManifest(ctx, ref, os, arch)
+	manifestBytes, err := rawManifest(ctx, repo, ref, os, arch)
 	if err != nil {
 		return nil, fmt.Errorf("unable to get manifest: %w", err)
 	}
@@ -184,11 +217,16 @@ func (p *Puller) manifest(ctx context.Context, ref, os, arch string) (*v1.Manife
 // If the artifact has a v1.MediaTypeImageIndex descriptor then it fetches the manifest for the
 // specified platform.
 func (p *Puller) RawManifest(ctx context.Context, ref, os, arch string) ([]byte, error) {
-	repo, err := repository.NewRepository(ref, repository.WithClient(p.Client), repository.WithPlainHTTP(p.plainHTTP))
-	if err != nil {
-		return nil, err
-	}
+	var manifestBytes []byte
+	err := p.try(ctx, ref, func(c mirror.Candidate, repo *repository.Repository) (err error) {
+		manifestBytes, err = rawManifest(ctx, repo, c.Ref, os, arch)
+		return err
+	})
+
+	return manifestBytes, err
+}
 
+func rawManifest(ctx context.Context, repo *repository.Repository, ref, os, arch string) ([]byte, error) {
 	desc, manifestReader, err := repo.FetchReference(ctx, ref)
 	if err != nil {
 		return nil, fmt.Errorf("unable to fetch reference %q: %w", ref, err)
@@ -258,12 +296,17 @@ func (p *Puller) ArtifactConfig(ctx context.Context, ref, os, arch string) (*oci
 // If the artifact has a v1.MediaTypeImageIndex descriptor then it fetches the config layer for the
 // specified platform.
 func (p *Puller) RawConfigLayer(ctx context.Context, ref, os, arch string) ([]byte, error) {
-	repo, err := repository.NewRepository(ref, repository.WithClient(p.Client), repository.WithPlainHTTP(p.plainHTTP))
-	if err != nil {
-		return nil, err
-	}
+	var configBytes []byte
+	err := p.try(ctx, ref, func(c mirror.Candidate, repo *repository.Repository) (err error) {
+		configBytes, err = p.rawConfigLayer(ctx, repo, c.Ref, os, arch)
+		return err
+	})
 
-	manifest, err := p.manifest(ctx, ref, os, arch)
+	return configBytes, err
+}
+
+func (p *Puller) rawConfigLayer(ctx context.Context, repo *repository.Repository, ref, os, arch string) ([]byte, error) {
+	manifest, err := p.manifest(ctx, repo, ref, os, arch)
 	if err != nil {
 		return nil, err
 	}
@@ -300,7 +343,11 @@ func (p *Puller) CheckAllowedType(ctx context.Context, ref, os, arch string, all
 		return nil
 	}
 
-	manifest, err := p.manifest(ctx, ref, os, arch)
+	var manifest *v1.Manifest
+	err := p.try(ctx, ref, func(c mirror.Candidate, repo *repository.Repository) (err error) {
+		manifest, err = p.manifest(ctx, repo, c.Ref, os, arch)
+		return err
+	})
 	if err != nil {
 		return err
 	}
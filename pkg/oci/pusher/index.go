@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pusher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	v1 "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content"
+	"oras.land/oras-go/v2/content/memory"
+
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/repository"
+)
+
+// indexConfig is the content of the config layer of index artifacts.
+var indexConfig = []byte("{}")
+
+// PushIndex pushes an index file to a remote registry as an index artifact.
+//
+// indexPath path of the index file on the disk.
+// ref format follows: REGISTRY/REPO[:TAG|@DIGEST]. Ex. localhost:5000/index:latest.
+func (p *Pusher) PushIndex(ctx context.Context, ref, indexPath string, tags ...string) (*oci.RegistryResult, error) {
+	data, err := os.ReadFile(filepath.Clean(indexPath))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read index file %s: %w", indexPath, err)
+	}
+
+	repo, err := repository.NewRepository(ref,
+		repository.WithClient(p.Client),
+		repository.WithPlainHTTP(p.plainHTTP))
+	if err != nil {
+		return nil, err
+	}
+
+	// Using ":latest" by default if no tag was provided.
+	if repo.Reference.Reference == "" {
+		if len(tags) > 0 {
+			repo.Reference.Reference, tags = tags[0], tags[1:]
+		} else {
+			repo.Reference.Reference = oci.DefaultTag
+		}
+	}
+
+	remoteTarget := oras.Target(repo)
+	if p.tracker != nil {
+		remoteTarget = p.tracker(repo)
+	}
+
+	// The index file is small, so everything is kept in memory.
+	store := memory.New()
+
+	layerDesc := content.NewDescriptorFromBytes(oci.DiginfraIndexLayerMediaType, data)
+	layerDesc.Annotations = map[string]string{v1.AnnotationTitle: filepath.Base(indexPath)}
+	if err := store.Push(ctx, layerDesc, bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("unable to store index file %s: %w", indexPath, err)
+	}
+
+	configDesc := content.NewDescriptorFromBytes(oci.DiginfraIndexConfigMediaType, indexConfig)
+	if err := store.Push(ctx, configDesc, bytes.NewReader(indexConfig)); err != nil {
+		return nil, fmt.Errorf("unable to store index config: %w", err)
+	}
+
+	manifestDesc, err := oras.Pack(ctx, store, "", []v1.Descriptor{layerDesc},
+		oras.PackOptions{ConfigDescriptor: &configDesc, PackImageManifest: true})
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate manifest for index file %s: %w", indexPath, err)
+	}
+
+	copyOptions := oras.DefaultCopyGraphOptions
+	copyOptions.Concurrency = 1
+	if err = oras.CopyGraph(ctx, store, remoteTarget, manifestDesc, copyOptions); err != nil {
+		return nil, err
+	}
+
+	if err = repo.Tag(ctx, manifestDesc, repo.Reference.Reference); err != nil {
+		return nil, err
+	}
+
+	if len(tags) > 0 {
+		tagNOptions := oras.DefaultTagNOptions
+		tagNOptions.Concurrency = 1
+		if _, err = oras.TagN(ctx, remoteTarget, repo.Reference.Reference, tags, tagNOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	return &oci.RegistryResult{
+		RootDigest: string(manifestDesc.Digest),
+		Filename:   filepath.Base(indexPath),
+	}, nil
+}
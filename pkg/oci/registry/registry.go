@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"strings"
 
 	"oras.land/oras-go/v2/registry/remote"
 	"oras.land/oras-go/v2/registry/remote/auth"
@@ -63,6 +64,24 @@ func WithPlainHTTP(plainHTTP bool) func(r *Registry) {
 	}
 }
 
+// Repositories returns the repositories of the registry whose name starts with prefix, as listed by the catalog API.
+func (r *Registry) Repositories(ctx context.Context, prefix string) ([]string, error) {
+	var result []string
+	err := r.Registry.Repositories(ctx, "", func(repos []string) error {
+		for _, repo := range repos {
+			if strings.HasPrefix(repo, prefix) {
+				result = append(result, repo)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
 // CheckConnection checks whether the underlying HTTP client can correctly interact with the remote registry.
 func (r *Registry) CheckConnection(ctx context.Context) error {
 	if authClient, ok := r.Client.(*auth.Client); ok {
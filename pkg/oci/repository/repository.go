@@ -17,9 +17,26 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"slices"
 
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/errdef"
 	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/errcode"
+)
+
+var (
+	// ErrDeleteUnsupported is returned when the registry does not allow deleting manifests or tags.
+	ErrDeleteUnsupported = errors.New("the registry does not support deletions")
+	// ErrReferenced is returned when deleting a manifest still referenced by an image index.
+	ErrReferenced = errors.New("manifest still referenced by an image index")
 )
 
 // Repository is an HTTP client to interact with a remote repository.
@@ -64,7 +81,7 @@ func WithPlainHTTP(plainHTTP bool) func(r *Repository) {
 func (r *Repository) Tags(ctx context.Context) ([]string, error) {
 	var result []string
 	var tagRetriever = func(tags []string) error {
-		result = tags
+		result = append(result, tags...)
 		return nil
 	}
 
@@ -75,3 +92,149 @@ func (r *Repository) Tags(ctx context.Context) ([]string, error) {
 
 	return result, nil
 }
+
+// Retag points the given tags to the manifest referenced by reference, either a tag or a digest, and
+// returns its descriptor.
+func (r *Repository) Retag(ctx context.Context, reference string, tags ...string) (ocispec.Descriptor, error) {
+	desc, err := r.Resolve(ctx, reference)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	for _, tag := range tags {
+		if err := r.Tag(ctx, desc, tag); err != nil {
+			return ocispec.Descriptor{}, fmt.Errorf("unable to tag %s as %q: %w", desc.Digest, tag, err)
+		}
+	}
+
+	return desc, nil
+}
+
+// Untag removes a tag from the repository, the manifest it points to is left in place.
+// ErrDeleteUnsupported is returned if the registry does not support deleting tags.
+func (r *Repository) Untag(ctx context.Context, tag string) error {
+	ref := r.Reference
+	ref.Reference = tag
+	if err := ref.ValidateReferenceAsTag(); err != nil {
+		return err
+	}
+
+	ctx = auth.AppendRepositoryScope(ctx, ref, auth.ActionDelete)
+	scheme := "https"
+	if r.PlainHTTP {
+		scheme = "http"
+	}
+	url := fmt.Sprintf("%s://%s/v2/%s/manifests/%s", scheme, ref.Host(), ref.Repository, tag)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusAccepted, http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("%s: %w", tag, errdef.ErrNotFound)
+	}
+
+	errResp := &errcode.ErrorResponse{Method: req.Method, URL: req.URL, StatusCode: resp.StatusCode}
+	var body struct {
+		Errors errcode.Errors `json:"errors"`
+	}
+	if data, err := io.ReadAll(io.LimitReader(resp.Body, 8*1024)); err == nil && json.Unmarshal(data, &body) == nil {
+		errResp.Errors = body.Errors
+	}
+
+	return deleteError(errResp)
+}
+
+// DeleteManifest deletes the manifest with the given digest, along with the tags pointing to it.
+// The manifests referenced by the tagged image indexes of the repository are not deleted, and
+// ErrReferenced is returned instead. ErrDeleteUnsupported is returned if the registry does not
+// support deleting manifests.
+func (r *Repository) DeleteManifest(ctx context.Context, dgst digest.Digest) error {
+	desc, err := r.Resolve(ctx, dgst.String())
+	if err != nil {
+		return err
+	}
+
+	indexes, err := r.IndexesReferencing(ctx, dgst)
+	if err != nil {
+		return err
+	}
+	if len(indexes) > 0 {
+		return fmt.Errorf("%w %s:%s", ErrReferenced, r.Reference.Repository, indexes[0])
+	}
+
+	return deleteError(r.Delete(ctx, desc))
+}
+
+// IndexesReferencing returns the tags of the image indexes of the repository whose manifests include
+// the one with the given digest.
+func (r *Repository) IndexesReferencing(ctx context.Context, dgst digest.Digest) ([]string, error) {
+	tags, err := r.Tags(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list tags of %s: %w", r.Reference.Repository, err)
+	}
+
+	var result []string
+	for _, tag := range tags {
+		desc, err := r.Resolve(ctx, tag)
+		if err != nil {
+			return nil, err
+		}
+		if desc.MediaType != ocispec.MediaTypeImageIndex || desc.Digest == dgst {
+			continue
+		}
+
+		index, err := r.fetchIndex(ctx, desc)
+		if err != nil {
+			return nil, err
+		}
+		if slices.ContainsFunc(index.Manifests, func(m ocispec.Descriptor) bool { return m.Digest == dgst }) {
+			result = append(result, tag)
+		}
+	}
+
+	return result, nil
+}
+
+func (r *Repository) fetchIndex(ctx context.Context, desc ocispec.Descriptor) (*ocispec.Index, error) {
+	rc, err := r.Fetch(ctx, desc)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var index ocispec.Index
+	if err := json.NewDecoder(rc).Decode(&index); err != nil {
+		return nil, fmt.Errorf("unable to decode image index %s: %w", desc.Digest, err)
+	}
+
+	return &index, nil
+}
+
+// deleteError wraps with ErrDeleteUnsupported the errors returned by registries not supporting deletions.
+func deleteError(err error) error {
+	var errResp *errcode.ErrorResponse
+	if !errors.As(err, &errResp) {
+		return err
+	}
+
+	if errResp.StatusCode == http.StatusMethodNotAllowed {
+		return fmt.Errorf("%w: %w", ErrDeleteUnsupported, err)
+	}
+	for _, e := range errResp.Errors {
+		if e.Code == errcode.ErrorCodeUnsupported {
+			return fmt.Errorf("%w: %w", ErrDeleteUnsupported, err)
+		}
+	}
+
+	return err
+}
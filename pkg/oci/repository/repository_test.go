@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repository_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/distribution/distribution/v3/configuration"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote/auth"
+
+	"github.com/diginfra/diginfractl/pkg/oci"
+	"github.com/diginfra/diginfractl/pkg/oci/authn"
+	ocipusher "github.com/diginfra/diginfractl/pkg/oci/pusher"
+	"github.com/diginfra/diginfractl/pkg/oci/repository"
+	testutils "github.com/diginfra/diginfractl/pkg/test"
+)
+
+const rulesfiletgz = "../../test/data/rules.tar.gz"
+
+func startRegistry(t *testing.T, deleteEnabled bool, opts ...testutils.TestRegistryOption) string {
+	return testutils.StartTestRegistry(t, &configuration.Configuration{Storage: configuration.Storage{
+		"inmemory": configuration.Parameters{},
+		"delete":   configuration.Parameters{"enabled": deleteEnabled},
+	}}, opts...)
+}
+
+func TestTagManagement(t *testing.T) {
+	ctx := context.Background()
+	reg := startRegistry(t, true)
+	client := authn.NewClient(authn.WithCredentials(&auth.EmptyCredential))
+	pusher := ocipusher.NewPusher(client, true, nil)
+
+	plugin, err := pusher.Push(ctx, oci.Plugin, reg+"/plugin:0.7.0",
+		ocipusher.WithFilepathsAndPlatforms([]string{rulesfiletgz, rulesfiletgz}, []string{"linux/arm64", "linux/amd64"}),
+		ocipusher.WithArtifactConfig(oci.ArtifactConfig{Version: "0.7.0"}))
+	require.NoError(t, err)
+	_, err = pusher.Push(ctx, oci.Plugin, reg+"/plugin:0.7.1",
+		ocipusher.WithFilepathsAndPlatforms([]string{rulesfiletgz}, []string{"linux/amd64"}),
+		ocipusher.WithArtifactConfig(oci.ArtifactConfig{Version: "0.7.1"}),
+		ocipusher.WithTags("0.7"))
+	require.NoError(t, err)
+
+	repo, err := repository.NewRepository(reg+"/plugin", repository.WithClient(client), repository.WithPlainHTTP(true))
+	require.NoError(t, err)
+
+	// Move the floating tag back to the previous release.
+	desc, err := repo.Retag(ctx, "0.7.0", "0.7")
+	require.NoError(t, err)
+	assert.Equal(t, plugin.RootDigest, desc.Digest.String())
+	moved, err := repo.Resolve(ctx, "0.7")
+	require.NoError(t, err)
+	assert.Equal(t, desc.Digest, moved.Digest)
+
+	_, err = repo.Retag(ctx, "missing", "0.7")
+	assert.ErrorIs(t, err, errdef.ErrNotFound)
+
+	// The manifests of the platforms are referenced by the image indexes.
+	_, data, err := repo.FetchReference(ctx, "0.7.0")
+	require.NoError(t, err)
+	index, err := testutils.ImageIndexFromReader(data)
+	require.NoError(t, err)
+	require.NoError(t, data.Close())
+	platform := index.Manifests[0]
+	indexes, err := repo.IndexesReferencing(ctx, platform.Digest)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"0.7.0", "0.7"}, indexes)
+	assert.ErrorIs(t, repo.DeleteManifest(ctx, platform.Digest), repository.ErrReferenced)
+
+	// Untagging leaves the manifest in place.
+	require.NoError(t, repo.Untag(ctx, "0.7"))
+	_, err = repo.Resolve(ctx, "0.7")
+	assert.ErrorIs(t, err, errdef.ErrNotFound)
+	_, err = repo.Resolve(ctx, desc.Digest.String())
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.Untag(ctx, "0.7"), errdef.ErrNotFound)
+
+	// Deleting the image index removes its tags, then its manifests are no longer referenced.
+	require.NoError(t, repo.DeleteManifest(ctx, desc.Digest))
+	_, err = repo.Resolve(ctx, "0.7.0")
+	assert.ErrorIs(t, err, errdef.ErrNotFound)
+	indexes, err = repo.IndexesReferencing(ctx, platform.Digest)
+	require.NoError(t, err)
+	assert.Empty(t, indexes)
+	require.NoError(t, repo.DeleteManifest(ctx, platform.Digest))
+}
+
+func TestIndexesReferencingPaginated(t *testing.T) {
+	ctx := context.Background()
+	reg := startRegistry(t, true, testutils.WithTagsPageSize(2))
+	client := authn.NewClient(authn.WithCredentials(&auth.EmptyCredential))
+	pusher := ocipusher.NewPusher(client, true, nil)
+
+	// The tag of the image index is listed in the first page, before the ones of the other artifacts.
+	plugin, err := pusher.Push(ctx, oci.Plugin, reg+"/plugin:0.1.0",
+		ocipusher.WithFilepathsAndPlatforms([]string{rulesfiletgz}, []string{"linux/amd64"}),
+		ocipusher.WithArtifactConfig(oci.ArtifactConfig{Version: "0.1.0"}))
+	require.NoError(t, err)
+	_, err = pusher.Push(ctx, oci.Rulesfile, reg+"/plugin:1.0.0",
+		ocipusher.WithFilepaths([]string{rulesfiletgz}),
+		ocipusher.WithArtifactConfig(oci.ArtifactConfig{Name: "rules", Version: "1.0.0"}),
+		ocipusher.WithTags("2.0.0", "3.0.0", "4.0.0"))
+	require.NoError(t, err)
+
+	repo, err := repository.NewRepository(reg+"/plugin", repository.WithClient(client), repository.WithPlainHTTP(true))
+	require.NoError(t, err)
+
+	tags, err := repo.Tags(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"0.1.0", "1.0.0", "2.0.0", "3.0.0", "4.0.0"}, tags)
+
+	_, data, err := repo.FetchReference(ctx, plugin.RootDigest)
+	require.NoError(t, err)
+	index, err := testutils.ImageIndexFromReader(data)
+	require.NoError(t, err)
+	require.NoError(t, data.Close())
+	platform := index.Manifests[0]
+
+	indexes, err := repo.IndexesReferencing(ctx, platform.Digest)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0.1.0"}, indexes)
+	assert.ErrorIs(t, repo.DeleteManifest(ctx, platform.Digest), repository.ErrReferenced)
+	_, err = repo.Resolve(ctx, platform.Digest.String())
+	assert.NoError(t, err)
+}
+
+func TestDeleteUnsupported(t *testing.T) {
+	ctx := context.Background()
+	reg := startRegistry(t, false)
+	client := authn.NewClient(authn.WithCredentials(&auth.EmptyCredential))
+	pusher := ocipusher.NewPusher(client, true, nil)
+
+	rules, err := pusher.Push(ctx, oci.Rulesfile, reg+"/rules:1.0.0",
+		ocipusher.WithFilepaths([]string{rulesfiletgz}),
+		ocipusher.WithArtifactConfig(oci.ArtifactConfig{Name: "rules", Version: "1.0.0"}))
+	require.NoError(t, err)
+
+	repo, err := repository.NewRepository(reg+"/rules", repository.WithClient(client), repository.WithPlainHTTP(true))
+	require.NoError(t, err)
+
+	desc, err := repo.Resolve(ctx, rules.RootDigest)
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.DeleteManifest(ctx, desc.Digest), repository.ErrDeleteUnsupported)
+	_, err = repo.Resolve(ctx, "1.0.0")
+	require.NoError(t, err)
+
+	// Some registries do not support deleting tags either.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		_, _ = w.Write([]byte(`{"errors":[{"code":"UNSUPPORTED","message":"The operation is unsupported."}]}`))
+	}))
+	defer server.Close()
+	repo, err = repository.NewRepository(server.Listener.Addr().String()+"/rules", repository.WithClient(client),
+		repository.WithPlainHTTP(true))
+	require.NoError(t, err)
+	assert.ErrorIs(t, repo.Untag(ctx, "1.0.0"), repository.ErrDeleteUnsupported)
+}
@@ -137,6 +137,11 @@ type RegistryResult struct {
 	Config     ArtifactConfig
 	Type       ArtifactType
 	Filename   string
+	// Source is the reference the artifact has been pulled from, which differs from the requested one
+	// when the artifact has been pulled from a mirror.
+	Source string
+	// PlainHTTP is set to true if Source has been reached in plain http.
+	PlainHTTP bool
 }
 
 // ArtifactConfig is the struct stored in the config layer of rulesfile and plugin artifacts. Each type fills only the fields of interest.
@@ -25,20 +25,54 @@ import (
 
 	"github.com/diginfra/diginfractl/internal/config"
 	"github.com/diginfra/diginfractl/pkg/oci/authn"
+	ocicopier "github.com/diginfra/diginfractl/pkg/oci/copier"
+	"github.com/diginfra/diginfractl/pkg/oci/mirror"
 	ocipuller "github.com/diginfra/diginfractl/pkg/oci/puller"
 	ocipusher "github.com/diginfra/diginfractl/pkg/oci/pusher"
 	"github.com/diginfra/diginfractl/pkg/oci/registry"
+	"github.com/diginfra/diginfractl/pkg/oci/repository"
 	"github.com/diginfra/diginfractl/pkg/output"
 )
 
-// Puller returns a new ocipuller.Puller ready to be used for pulling from oci registries.
+// Puller returns a new ocipuller.Puller ready to be used for pulling from oci registries,
+// through the configured mirrors.
 func Puller(plainHTTP bool, printer *output.Printer) (*ocipuller.Puller, error) {
 	client, err := Client(true)
 	if err != nil {
 		return nil, err
 	}
 
-	return ocipuller.NewPuller(client, plainHTTP, output.NewTracker(printer, "Pulling")), nil
+	mirrors, err := Mirrors()
+	if err != nil {
+		return nil, err
+	}
+
+	puller := ocipuller.NewPuller(client, plainHTTP, output.NewTracker(printer, "Pulling"))
+	puller.Mirrors = mirrors
+	return puller, nil
+}
+
+// Mirrors returns the registry mirrors found in the configuration.
+func Mirrors() (mirror.Mirrors, error) {
+	conf, err := config.RegistryMirrors()
+	if err != nil {
+		return nil, err
+	}
+
+	mirrors := make(mirror.Mirrors, 0, len(conf))
+	for _, m := range conf {
+		endpoints := make([]mirror.Endpoint, 0, len(m.Endpoints))
+		for _, e := range m.Endpoints {
+			endpoints = append(endpoints, mirror.Endpoint{Location: e.Location, PlainHTTP: e.PlainHTTP})
+		}
+		mirrors = append(mirrors, mirror.Mirror{Prefix: m.Prefix, Endpoints: endpoints, Fallback: m.Fallback})
+	}
+
+	if err := mirrors.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid registry mirrors: %w", err)
+	}
+
+	return mirrors, nil
 }
 
 // Pusher returns an ocipusher.Pusher ready to be used for pushing to oci registries.
@@ -50,6 +84,25 @@ func Pusher(plainHTTP bool, printer *output.Printer) (*ocipusher.Pusher, error)
 	return ocipusher.NewPusher(client, plainHTTP, output.NewTracker(printer, "Pushing")), nil
 }
 
+// Copier returns an ocicopier.Copier ready to be used for copying between oci registries.
+func Copier(plainHTTP bool, printer *output.Printer) (*ocicopier.Copier, error) {
+	client, err := Client(true)
+	if err != nil {
+		return nil, err
+	}
+	return ocicopier.NewCopier(client, plainHTTP, output.NewTracker(printer, "Copying")), nil
+}
+
+// Repository returns a repository.Repository ready to be used for managing the tags and manifests of the
+// repository of ref.
+func Repository(ref string, plainHTTP bool) (*repository.Repository, error) {
+	client, err := Client(true)
+	if err != nil {
+		return nil, err
+	}
+	return repository.NewRepository(ref, repository.WithClient(client), repository.WithPlainHTTP(plainHTTP))
+}
+
 // Client returns a new auth.Client.
 // It authenticates the client if credentials are found in the system.
 func Client(enableClientTokenCache bool) (remote.Client, error) {
@@ -0,0 +1,37 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/diginfra/diginfractl/internal/config"
+)
+
+// FlagControlSocket is the name of the flag to specify the path of the follower control socket.
+const FlagControlSocket = "socket"
+
+// Control defines options that are common while interacting with the follower control API.
+type Control struct {
+	// Socket path of the Unix socket where the follower serves the control API.
+	Socket string
+}
+
+// AddFlags registers the control flags as persistent flags.
+func (c *Control) AddFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&c.Socket, FlagControlSocket, config.FollowControlSocket,
+		"path of the Unix socket where the follower serves the control API")
+}
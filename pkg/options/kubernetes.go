@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/diginfra/diginfractl/internal/kube"
+)
+
+const (
+	// FlagK8sDestination is the name of the flag to specify the ConfigMap or Secret where to install the artifacts.
+	FlagK8sDestination = "k8s-destination"
+	// FlagK8sNamespace is the name of the flag to specify the namespace of the Kubernetes destination.
+	FlagK8sNamespace = "k8s-namespace"
+	// FlagKubeconfig is the name of the flag to specify the kubeconfig used to reach the Kubernetes destination.
+	FlagKubeconfig = "kubeconfig"
+)
+
+// Kubernetes defines the options to install artifacts in a ConfigMap or a Secret instead of the local directories.
+type Kubernetes struct {
+	// Destination in the "configmap/<name>" or "secret/<name>" format. Empty if disabled.
+	Destination string
+	// Namespace of the destination.
+	Namespace string
+	// KubeConfig path of the kubeconfig file. The in-cluster configuration is used if empty.
+	KubeConfig string
+}
+
+// AddFlags registers the Kubernetes destination flags.
+func (k *Kubernetes) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&k.Destination, FlagK8sDestination, "",
+		`ConfigMap or Secret where to install rulesfiles and assets instead of the local directories, `+
+			`in the "configmap/<name>" or "secret/<name>" format`)
+	cmd.Flags().StringVar(&k.Namespace, FlagK8sNamespace, "", `namespace of the Kubernetes destination (defaults to "default")`)
+	cmd.Flags().StringVar(&k.KubeConfig, FlagKubeconfig, "",
+		"kubeconfig used to reach the Kubernetes destination. The in-cluster configuration is used if not set")
+}
+
+// NewDestination returns the configured Kubernetes destination, or nil if none is configured.
+func (k *Kubernetes) NewDestination() (*kube.Destination, error) {
+	if k.Destination == "" {
+		return nil, nil
+	}
+
+	// Validate the destination before building the client, to report syntax errors first.
+	if _, _, err := kube.ParseDestination(k.Destination); err != nil {
+		return nil, err
+	}
+	client, err := kube.NewClientset(k.KubeConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return kube.NewDestination(client, k.Namespace, k.Destination)
+}
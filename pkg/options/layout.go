@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package options
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/diginfra/diginfractl/internal/layout"
+)
+
+const (
+	// FlagVersioned is the name of the flag to enable the versioned install layout.
+	FlagVersioned = "versioned"
+	// FlagKeepVersions is the name of the flag to specify how many versions are kept with the versioned layout.
+	FlagKeepVersions = "keep-versions"
+)
+
+// Layout defines the options of the versioned install layout.
+type Layout struct {
+	// Versioned is true if the artifacts are installed with the versioned layout.
+	Versioned bool
+	// KeepVersions is the number of versions kept for each artifact.
+	KeepVersions int
+}
+
+// AddFlags registers the install layout flags.
+func (l *Layout) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&l.Versioned, FlagVersioned, false,
+		"install each version in its own directory under .versions and switch the active one atomically through symlinks")
+	cmd.Flags().IntVar(&l.KeepVersions, FlagKeepVersions, layout.DefaultKeep,
+		"number of versions kept for each artifact with the versioned layout, 0 to keep them all")
+}
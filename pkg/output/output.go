@@ -37,6 +37,14 @@ const (
 	IndexList
 	// ArtifactInfo identifies the header for artifact info.
 	ArtifactInfo
+	// FollowerStatus identifies the header for follow status.
+	FollowerStatus
+	// ArtifactHistory identifies the header for artifact history.
+	ArtifactHistory
+	// IndexLint identifies the header for index lint.
+	IndexLint
+	// ArtifactAudit identifies the header for artifact audit.
+	ArtifactAudit
 )
 
 var spinnerCharset = []string{"⠈⠁", "⠈⠑", "⠈⠱", "⠈⡱", "⢀⡱", "⢄⡱", "⢄⡱", "⢆⡱", "⢎⡱", "⢎⡰", "⢎⡠", "⢎⡀", "⢎⠁", "⠎⠁", "⠊⠁"}
@@ -159,6 +167,14 @@ func (p *Printer) PrintTable(header TableHeader, data [][]string) error {
 		table = [][]string{{"NAME", "URL", "ADDED", "UPDATED"}}
 	case ArtifactInfo:
 		table = [][]string{{"REF", "TAGS"}}
+	case FollowerStatus:
+		table = [][]string{{"REF", "SCHEDULE", "DIGEST", "STAGED", "PAUSED", "LAST SYNC", "NEXT RUN", "LAST ERROR"}}
+	case ArtifactHistory:
+		table = [][]string{{"TIME", "OPERATION", "REF", "VERSION", "OLD DIGEST", "NEW DIGEST", "OUTCOME", "SIGNER"}}
+	case IndexLint:
+		table = [][]string{{"ENTRY", "ISSUE"}}
+	case ArtifactAudit:
+		table = [][]string{{"REF", "VERSION", "KIND", "NOTICE"}}
 	default:
 		return fmt.Errorf("unsupported output table")
 	}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (C) 2024 The Diginfra Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/distribution/distribution/v3/configuration"
+	"github.com/distribution/distribution/v3/registry/handlers"
+	// Registers the inmemory storage driver used by default.
+	_ "github.com/distribution/distribution/v3/registry/storage/driver/inmemory"
+	"github.com/stretchr/testify/require"
+)
+
+type testRegistryOptions struct {
+	tagsPageSize int
+}
+
+// TestRegistryOption configures the registry started by StartTestRegistry.
+type TestRegistryOption func(*testRegistryOptions)
+
+// WithTagsPageSize makes the registry paginate the tag lists in pages of at most n tags, as public
+// registries do, when the client does not ask for a page size.
+func WithTagsPageSize(n int) TestRegistryOption {
+	return func(o *testRegistryOptions) {
+		o.tagsPageSize = n
+	}
+}
+
+// StartTestRegistry starts a new OCI registry, with in-memory storage unless cfg configures another one,
+// on a free port of localhost, and returns its address. A nil cfg uses the default configuration.
+// The registry is stopped when the test ends.
+func StartTestRegistry(t testing.TB, cfg *configuration.Configuration, opts ...TestRegistryOption) string {
+	t.Helper()
+
+	o := &testRegistryOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if cfg == nil {
+		cfg = &configuration.Configuration{}
+	}
+	if cfg.Storage == nil {
+		cfg.Storage = configuration.Storage{"inmemory": configuration.Parameters{}}
+	}
+
+	l, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	cfg.HTTP.Addr = fmt.Sprintf("localhost:%d", l.Addr().(*net.TCPAddr).Port)
+
+	var handler http.Handler = handlers.NewApp(context.Background(), cfg)
+	if o.tagsPageSize > 0 {
+		handler = paginateTags(handler, o.tagsPageSize)
+	}
+
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		_ = server.Serve(l)
+	}()
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+
+	return cfg.HTTP.Addr
+}
+
+// paginateTags sets the page size of the tag list requests that have none.
+func paginateTags(next http.Handler, n int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/tags/list") && r.URL.Query().Get("n") == "" {
+			q := r.URL.Query()
+			q.Set("n", strconv.Itoa(n))
+			u := *r.URL
+			u.RawQuery = q.Encode()
+			r = r.Clone(r.Context())
+			r.URL = &u
+			r.RequestURI = (&url.URL{Path: u.Path, RawQuery: u.RawQuery}).RequestURI()
+		}
+		next.ServeHTTP(w, r)
+	})
+}